@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"sort"
+	"strings"
+
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/tpu/v2"
+)
+
+// getTPUs lists Cloud TPU nodes and TPU VMs in every zone.
+func getTPUs(ctx context.Context) {
+	tpuService, err := tpu.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create Cloud TPU service", "error", err)
+		return
+	}
+
+	nodes, err := tpuService.Projects.Locations.Nodes.List(fmt.Sprintf("projects/%s/locations/-", projectID)).Context(ctx).Do()
+	if err != nil {
+		// Skip projects that don't have the Cloud TPU API enabled
+		slog.Debug("Skipping Cloud TPU", "error", err)
+		return
+	}
+
+	for _, node := range nodes.Nodes {
+		topology := ""
+		if node.AcceleratorConfig != nil {
+			topology = node.AcceleratorConfig.Topology
+		}
+		info := fmt.Sprintf("Name: %s\nZone: %s\nAccelerator Type: %s\nTopology: %s\nRuntime Version: %s\nState: %s\nHealth: %s\nCreated: %s",
+			path.Base(node.Name), membershipLocation(node.Name), node.AcceleratorType, topology,
+			node.RuntimeVersion, node.State, node.Health, node.CreateTime)
+		writeResourceRaw("TPU Node", info, node)
+	}
+	scanProgress.found(len(nodes.Nodes))
+}
+
+// getGPUSummary totals the GPUs attached to instances per zone and
+// accelerator type, across every zone.
+func getGPUSummary(ctx context.Context) {
+	computeService, err := compute.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create compute service", "error", err)
+		return
+	}
+
+	type gpuTotal struct {
+		zone, acceleratorType string
+		gpus                  int64
+		instances             []string
+		running               int
+	}
+	totals := map[string]*gpuTotal{}
+	err = computeService.Instances.AggregatedList(projectID).Pages(ctx, func(list *compute.InstanceAggregatedList) error {
+		for _, scoped := range list.Items {
+			for _, instance := range scoped.Instances {
+				for _, accel := range instance.GuestAccelerators {
+					zone, accelType := path.Base(instance.Zone), path.Base(accel.AcceleratorType)
+					key := zone + "/" + accelType
+					t := totals[key]
+					if t == nil {
+						t = &gpuTotal{zone: zone, acceleratorType: accelType}
+						totals[key] = t
+					}
+					t.gpus += accel.AcceleratorCount
+					t.instances = append(t.instances, instance.Name)
+					if instance.Status == "RUNNING" {
+						t.running++
+					}
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Error("Failed to list instances", "error", err)
+		return
+	}
+
+	keys := make([]string, 0, len(totals))
+	for key := range totals {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		t := totals[key]
+		info := fmt.Sprintf("Name: %s\nZone: %s\nAccelerator Type: %s\nGPUs: %d\nInstances: %s\nRunning Instances: %d",
+			key, t.zone, t.acceleratorType, t.gpus, strings.Join(t.instances, ", "), t.running)
+		writeResource("GPU Summary", info)
+	}
+	scanProgress.found(len(keys))
+}
+
+// instanceAccelerators returns the GPUs attached to an instance, e.g.
+// "2 x nvidia-tesla-t4".
+func instanceAccelerators(instance *compute.Instance) string {
+	var accels []string
+	for _, accel := range instance.GuestAccelerators {
+		accels = append(accels, fmt.Sprintf("%d x %s", accel.AcceleratorCount, path.Base(accel.AcceleratorType)))
+	}
+	return strings.Join(accels, ", ")
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+
+	"google.golang.org/api/dataform/v1beta1"
+)
+
+// lookerInstance is a Looker (Google Cloud core) instance, as returned by
+// looker.googleapis.com/v1. Only the fields the report uses are decoded.
+type lookerInstance struct {
+	Name             string `json:"name"`
+	State            string `json:"state"`
+	PlatformEdition  string `json:"platformEdition"`
+	LookerVersion    string `json:"lookerVersion"`
+	LookerURI        string `json:"lookerUri"`
+	PublicIPEnabled  bool   `json:"publicIpEnabled"`
+	PrivateIPEnabled bool   `json:"privateIpEnabled"`
+	ConsumerNetwork  string `json:"consumerNetwork"`
+	CreateTime       string `json:"createTime"`
+}
+
+// getDataform lists the region's Dataform repositories, with the Git
+// remote they sync with and their development workspaces.
+func getDataform(ctx context.Context, region string) {
+	dataformService, err := dataform.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create Dataform service", "error", err)
+		return
+	}
+
+	parent := fmt.Sprintf("projects/%s/locations/%s", projectID, region)
+	repos, err := dataformService.Projects.Locations.Repositories.List(parent).Context(ctx).Do()
+	if err != nil {
+		// Skip regions without Dataform and projects without the API enabled
+		slog.Debug("Skipping Dataform", "region", region, "error", err)
+		return
+	}
+
+	count := 0
+	for _, repo := range repos.Repositories {
+		remote, branch := "", ""
+		if repo.GitRemoteSettings != nil {
+			remote, branch = repo.GitRemoteSettings.Url, repo.GitRemoteSettings.DefaultBranch
+		}
+		workspaces, err := dataformService.Projects.Locations.Repositories.Workspaces.List(repo.Name).Context(ctx).Do()
+		if err != nil {
+			slog.Error("Failed to list Dataform workspaces", "repository", repo.Name, "error", err)
+			workspaces = &dataform.ListWorkspacesResponse{}
+		}
+		info := fmt.Sprintf("Name: %s\nRegion: %s\nGit Remote: %s\nDefault Branch: %s\nWorkspaces: %d",
+			path.Base(repo.Name), region, remote, branch, len(workspaces.Workspaces))
+		writeResourceRaw("Dataform Repository", info, repo)
+		count++
+
+		for _, workspace := range workspaces.Workspaces {
+			info := fmt.Sprintf("Name: %s\nRepository: %s\nRegion: %s", path.Base(workspace.Name), path.Base(repo.Name), region)
+			writeResourceRaw("Dataform Workspace", info, workspace)
+			count++
+		}
+	}
+	scanProgress.found(count)
+}
+
+// getLookerInstances lists the region's Looker (Google Cloud core)
+// instances.
+func getLookerInstances(ctx context.Context, region string) {
+	endpoint := fmt.Sprintf("https://looker.googleapis.com/v1/projects/%s/locations/%s/instances", projectID, region)
+	count := 0
+	err := listREST(ctx, endpoint, func(page *struct {
+		Instances []lookerInstance `json:"instances"`
+	}) error {
+		for _, instance := range page.Instances {
+			info := fmt.Sprintf("Name: %s\nRegion: %s\nEdition: %s\nVersion: %s\nURL: %s\nPublic IP: %t\nPrivate IP: %t\nNetwork: %s\nState: %s\nCreated: %s",
+				path.Base(instance.Name), region, instance.PlatformEdition, instance.LookerVersion, instance.LookerURI,
+				instance.PublicIPEnabled, instance.PrivateIPEnabled, path.Base(instance.ConsumerNetwork),
+				instance.State, instance.CreateTime)
+			writeResourceRaw("Looker Instance", info, instance)
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		// Skip projects that don't have the Looker API enabled
+		slog.Debug("Skipping Looker instances", "region", region, "error", err)
+	}
+	scanProgress.found(count)
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"google.golang.org/api/apigee/v1"
+)
+
+// getApigee reports the project's Apigee organization, its environments,
+// the runtime instances they are attached to and the API proxies with the
+// environments each revision is deployed to. An Apigee organization has
+// the same name as the project it was provisioned in.
+func getApigee(ctx context.Context) {
+	apigeeService, err := apigee.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create Apigee service", "error", err)
+		return
+	}
+
+	orgName := "organizations/" + projectID
+	org, err := apigeeService.Organizations.Get(orgName).Context(ctx).Do()
+	if err != nil {
+		// Skip projects without an Apigee organization
+		slog.Debug("Skipping Apigee", "error", err)
+		return
+	}
+	info := fmt.Sprintf("Name: %s\nRuntime Type: %s\nBilling Type: %s\nAnalytics Region: %s\nAuthorized Network: %s\nState: %s\nEnvironments: %s",
+		org.Name, org.RuntimeType, org.BillingType, org.AnalyticsRegion, org.AuthorizedNetwork, org.State,
+		strings.Join(org.Environments, ", "))
+	writeResourceRaw("Apigee Organization", info, org)
+	count := 1
+
+	for _, name := range org.Environments {
+		env, err := apigeeService.Organizations.Environments.Get(orgName + "/environments/" + name).Context(ctx).Do()
+		if err != nil {
+			slog.Error("Failed to get Apigee environment", "environment", name, "error", err)
+			continue
+		}
+		info := fmt.Sprintf("Name: %s\nOrganization: %s\nDisplay Name: %s\nType: %s\nDeployment Type: %s\nState: %s",
+			env.Name, org.Name, env.DisplayName, env.Type, env.DeploymentType, env.State)
+		writeResource("Apigee Environment", info)
+		count++
+	}
+
+	instances, err := apigeeService.Organizations.Instances.List(orgName).Context(ctx).Do()
+	if err != nil {
+		slog.Error("Failed to list Apigee instances", "error", err)
+	} else {
+		for _, instance := range instances.Instances {
+			var envs []string
+			attachments, err := apigeeService.Organizations.Instances.Attachments.List(orgName + "/instances/" + instance.Name).Context(ctx).Do()
+			if err != nil {
+				slog.Error("Failed to list Apigee instance attachments", "instance", instance.Name, "error", err)
+			} else {
+				for _, attachment := range attachments.Attachments {
+					envs = append(envs, attachment.Environment)
+				}
+			}
+			info := fmt.Sprintf("Name: %s\nOrganization: %s\nLocation: %s\nHost: %s\nPeering CIDR Range: %s\nRuntime Version: %s\nState: %s\nEnvironments: %s",
+				instance.Name, org.Name, instance.Location, instance.Host, instance.PeeringCidrRange,
+				instance.RuntimeVersion, instance.State, strings.Join(envs, ", "))
+			writeResourceRaw("Apigee Instance", info, instance)
+			count++
+		}
+	}
+
+	proxies, err := apigeeService.Organizations.Apis.List(orgName).Context(ctx).Do()
+	if err != nil {
+		slog.Error("Failed to list Apigee API proxies", "error", err)
+		scanProgress.found(count)
+		return
+	}
+	deployed := map[string][]string{}
+	deployments, err := apigeeService.Organizations.Deployments.List(orgName).Context(ctx).Do()
+	if err != nil {
+		slog.Error("Failed to list Apigee deployments", "error", err)
+	} else {
+		for _, deployment := range deployments.Deployments {
+			deployed[deployment.ApiProxy] = append(deployed[deployment.ApiProxy],
+				fmt.Sprintf("%s (revision %s)", deployment.Environment, deployment.Revision))
+		}
+	}
+	for _, proxy := range proxies.Proxies {
+		sort.Strings(deployed[proxy.Name])
+		info := fmt.Sprintf("Name: %s\nOrganization: %s\nRevisions: %d\nDeployed To: %s",
+			proxy.Name, org.Name, len(proxy.Revision), strings.Join(deployed[proxy.Name], ", "))
+		writeResourceRaw("Apigee API Proxy", info, proxy)
+		count++
+	}
+	scanProgress.found(count)
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+)
+
+// Backup and DR resources, as returned by backupdr.googleapis.com/v1. Only
+// the fields the report uses are decoded.
+type backupVault struct {
+	Name                                   string `json:"name"`
+	Description                            string `json:"description"`
+	State                                  string `json:"state"`
+	BackupMinimumEnforcedRetentionDuration string `json:"backupMinimumEnforcedRetentionDuration"`
+	BackupCount                            string `json:"backupCount"`
+	TotalStoredBytes                       string `json:"totalStoredBytes"`
+	CreateTime                             string `json:"createTime"`
+}
+
+type backupPlan struct {
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	State        string `json:"state"`
+	ResourceType string `json:"resourceType"`
+	BackupVault  string `json:"backupVault"`
+	BackupRules  []struct {
+		RuleID              string `json:"ruleId"`
+		BackupRetentionDays int    `json:"backupRetentionDays"`
+		StandardSchedule    struct {
+			RecurrenceType string `json:"recurrenceType"`
+		} `json:"standardSchedule"`
+	} `json:"backupRules"`
+}
+
+type backupPlanAssociation struct {
+	Name         string `json:"name"`
+	ResourceType string `json:"resourceType"`
+	Resource     string `json:"resource"`
+	BackupPlan   string `json:"backupPlan"`
+	State        string `json:"state"`
+}
+
+type backupManagementServer struct {
+	Name          string `json:"name"`
+	Type          string `json:"type"`
+	State         string `json:"state"`
+	ManagementURI struct {
+		WebUI string `json:"webUi"`
+	} `json:"managementUri"`
+}
+
+// getBackupDR lists the region's Backup and DR backup vaults, backup plans
+// and the resources they protect, plus management servers of the older
+// appliance-based service.
+func getBackupDR(ctx context.Context, region string) {
+	base := fmt.Sprintf("https://backupdr.googleapis.com/v1/projects/%s/locations/%s/", projectID, region)
+	count := 0
+
+	err := listREST(ctx, base+"backupVaults", func(page *struct {
+		BackupVaults []backupVault `json:"backupVaults"`
+	}) error {
+		for _, vault := range page.BackupVaults {
+			info := fmt.Sprintf("Name: %s\nRegion: %s\nDescription: %s\nState: %s\nMinimum Retention: %s\nBackups: %s\nStored Bytes: %s\nCreated: %s",
+				path.Base(vault.Name), region, oneLine(vault.Description), vault.State, vault.BackupMinimumEnforcedRetentionDuration,
+				vault.BackupCount, vault.TotalStoredBytes, vault.CreateTime)
+			writeResourceRaw("Backup Vault", info, vault)
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		// Skip projects that don't have the Backup and DR API enabled
+		slog.Debug("Skipping Backup and DR", "region", region, "error", err)
+		return
+	}
+
+	err = listREST(ctx, base+"backupPlans", func(page *struct {
+		BackupPlans []backupPlan `json:"backupPlans"`
+	}) error {
+		for _, plan := range page.BackupPlans {
+			var rules []string
+			for _, rule := range plan.BackupRules {
+				rules = append(rules, fmt.Sprintf("%s (%s, %d days)", rule.RuleID,
+					strings.ToLower(rule.StandardSchedule.RecurrenceType), rule.BackupRetentionDays))
+			}
+			info := fmt.Sprintf("Name: %s\nRegion: %s\nDescription: %s\nState: %s\nResource Type: %s\nBackup Vault: %s\nRules: %s",
+				path.Base(plan.Name), region, oneLine(plan.Description), plan.State, plan.ResourceType,
+				path.Base(plan.BackupVault), strings.Join(rules, ", "))
+			writeResourceRaw("Backup Plan", info, plan)
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Error("Failed to list backup plans", "region", region, "error", err)
+	}
+
+	err = listREST(ctx, base+"backupPlanAssociations", func(page *struct {
+		BackupPlanAssociations []backupPlanAssociation `json:"backupPlanAssociations"`
+	}) error {
+		for _, association := range page.BackupPlanAssociations {
+			info := fmt.Sprintf("Name: %s\nRegion: %s\nResource Type: %s\nResource: %s\nBackup Plan: %s\nState: %s",
+				path.Base(association.Resource), region, association.ResourceType, association.Resource,
+				path.Base(association.BackupPlan), association.State)
+			writeResourceRaw("Protected Resource", info, association)
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Error("Failed to list protected resources", "region", region, "error", err)
+	}
+
+	err = listREST(ctx, base+"managementServers", func(page *struct {
+		ManagementServers []backupManagementServer `json:"managementServers"`
+	}) error {
+		for _, server := range page.ManagementServers {
+			info := fmt.Sprintf("Name: %s\nRegion: %s\nType: %s\nState: %s\nConsole: %s",
+				path.Base(server.Name), region, server.Type, server.State, server.ManagementURI.WebUI)
+			writeResourceRaw("Backup Management Server", info, server)
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Error("Failed to list Backup and DR management servers", "region", region, "error", err)
+	}
+	scanProgress.found(count)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/api/batch/v1"
+)
+
+// getBatchJobs lists the region's Cloud Batch jobs with their state, the
+// machine type they run on and how many of their tasks are in each state.
+func getBatchJobs(ctx context.Context, region string) {
+	batchService, err := batch.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create Batch service", "error", err)
+		return
+	}
+
+	parent := fmt.Sprintf("projects/%s/locations/%s", projectID, region)
+	count := 0
+	err = batchService.Projects.Locations.Jobs.List(parent).Pages(ctx, func(page *batch.ListJobsResponse) error {
+		for _, job := range page.Jobs {
+			var tasks, parallelism int64
+			for _, group := range job.TaskGroups {
+				tasks += group.TaskCount
+				parallelism += group.Parallelism
+			}
+			machineType := ""
+			if job.AllocationPolicy != nil {
+				for _, instance := range job.AllocationPolicy.Instances {
+					if instance.Policy != nil && instance.Policy.MachineType != "" {
+						machineType = instance.Policy.MachineType
+					} else if instance.InstanceTemplate != "" {
+						machineType = "template " + path.Base(instance.InstanceTemplate)
+					}
+				}
+			}
+			state := ""
+			var taskStates []string
+			if job.Status != nil {
+				state = job.Status.State
+				counts := map[string]int64{}
+				for _, group := range job.Status.TaskGroups {
+					for taskState, n := range group.Counts {
+						v, _ := strconv.ParseInt(n, 10, 64)
+						counts[taskState] += v
+					}
+				}
+				for taskState, n := range counts {
+					taskStates = append(taskStates, fmt.Sprintf("%s %d", taskState, n))
+				}
+				sort.Strings(taskStates)
+			}
+			info := fmt.Sprintf("Name: %s\nRegion: %s\nState: %s\nMachine Type: %s\nTasks: %d\nParallelism: %d\nTask States: %s\nCreated: %s",
+				path.Base(job.Name), region, state, machineType, tasks, parallelism,
+				strings.Join(taskStates, ", "), job.CreateTime)
+			writeResourceRaw("Batch Job", info, job)
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		// Skip projects that don't have the Batch API enabled
+		slog.Debug("Skipping Batch jobs", "region", region, "error", err)
+	}
+	scanProgress.found(count)
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	bigquery "google.golang.org/api/bigquery/v2"
+	"google.golang.org/api/googleapi"
+)
+
+// getBigQueryDatasets lists the project's BigQuery datasets, where they
+// store their data and the KMS key new tables default to.
+func getBigQueryDatasets(ctx context.Context) {
+	bqService, err := bigquery.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create BigQuery service", "error", err)
+		return
+	}
+
+	count := 0
+	err = bqService.Datasets.List(projectID).All(true).Pages(ctx, func(list *bigquery.DatasetList) error {
+		for _, dataset := range list.Datasets {
+			// The default KMS key is only returned when getting a dataset.
+			kmsKey := ""
+			full, err := bqService.Datasets.Get(projectID, dataset.DatasetReference.DatasetId).Context(ctx).Do()
+			if err != nil {
+				slog.Error("Failed to get BigQuery dataset", "dataset", dataset.DatasetReference.DatasetId, "error", err)
+			} else if full.DefaultEncryptionConfiguration != nil {
+				kmsKey = full.DefaultEncryptionConfiguration.KmsKeyName
+			}
+			info := fmt.Sprintf("Name: %s\nLocation: %s\nKMS Key: %s\nLabels: %s",
+				dataset.DatasetReference.DatasetId, dataset.Location, kmsKey, attributeMapping(dataset.Labels))
+			writeResourceRaw("BigQuery Dataset", info, dataset)
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Error("Failed to list BigQuery datasets", "error", err)
+	}
+	scanProgress.found(count)
+}
+
+// exportBigQuery loads the inventory into the given table, creating it with
+// a day-partitioned schema on first use so daily scans accumulate for trend
+// queries.
+func exportBigQuery(ctx context.Context, tableSpec string) error {
+	bqProject, dataset, table, err := parseTableSpec(tableSpec)
+	if err != nil {
+		return err
+	}
+
+	bqService, err := bigquery.NewService(ctx, clientOptions...)
+	if err != nil {
+		return fmt.Errorf("create BigQuery service: %w", err)
+	}
+
+	if err := ensureInventoryTable(ctx, bqService, bqProject, dataset, table); err != nil {
+		return err
+	}
+
+	// A load job rather than streaming inserts: streamed rows can be
+	// dropped for a while after a table is created, and loads are free.
+	rows := withoutScanMetadata(inventory)
+	var data bytes.Buffer
+	enc := json.NewEncoder(&data)
+	for _, row := range rows {
+		if err := enc.Encode(bigQueryRow(row)); err != nil {
+			return fmt.Errorf("encode rows: %w", err)
+		}
+	}
+	if len(rows) > 0 {
+		job, err := bqService.Jobs.Insert(bqProject, &bigquery.Job{
+			Configuration: &bigquery.JobConfiguration{
+				Load: &bigquery.JobConfigurationLoad{
+					DestinationTable: &bigquery.TableReference{
+						ProjectId: bqProject,
+						DatasetId: dataset,
+						TableId:   table,
+					},
+					SourceFormat:     "NEWLINE_DELIMITED_JSON",
+					WriteDisposition: "WRITE_APPEND",
+				},
+			},
+		}).Media(&data, googleapi.ContentType("application/octet-stream")).Context(ctx).Do()
+		if err != nil {
+			return fmt.Errorf("start load job: %w", err)
+		}
+		if err := waitForBigQueryJob(ctx, bqService, job); err != nil {
+			return fmt.Errorf("load rows: %w", err)
+		}
+	}
+
+	fmt.Fprintf(console, "Exported %d rows to BigQuery table %s.%s.%s\n", len(rows), bqProject, dataset, table)
+	return nil
+}
+
+// waitForBigQueryJob polls job until it is done and returns the error it
+// failed with, if any.
+func waitForBigQueryJob(ctx context.Context, bqService *bigquery.Service, job *bigquery.Job) error {
+	ref := job.JobReference
+	for job.Status == nil || job.Status.State != "DONE" {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(2 * time.Second):
+		}
+		var err error
+		job, err = bqService.Jobs.Get(ref.ProjectId, ref.JobId).Location(ref.Location).Context(ctx).Do()
+		if err != nil {
+			return fmt.Errorf("get job %s: %w", ref.JobId, err)
+		}
+	}
+	if result := job.Status.ErrorResult; result != nil {
+		return errors.New(result.Message)
+	}
+	return nil
+}
+
+func ensureInventoryTable(ctx context.Context, bqService *bigquery.Service, bqProject, dataset, table string) error {
+	existing, err := bqService.Tables.Get(bqProject, dataset, table).Context(ctx).Do()
+	if err == nil {
+		return addMissingColumns(ctx, bqService, existing)
+	}
+	var apiErr *googleapi.Error
+	if !errors.As(err, &apiErr) || apiErr.Code != http.StatusNotFound {
+		return fmt.Errorf("get table: %w", err)
+	}
+
+	_, err = bqService.Tables.Insert(bqProject, dataset, &bigquery.Table{
+		TableReference: &bigquery.TableReference{
+			ProjectId: bqProject,
+			DatasetId: dataset,
+			TableId:   table,
+		},
+		Description: "GCP footprint inventory, one row per discovered resource per scan",
+		Schema:      inventorySchema(),
+		TimePartitioning: &bigquery.TimePartitioning{
+			Type:  "DAY",
+			Field: "scan_time",
+		},
+	}).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("create table: %w", err)
+	}
+	fmt.Fprintf(console, "Created BigQuery table %s.%s.%s\n", bqProject, dataset, table)
+	return nil
+}
+
+// addMissingColumns adds the columns of inventorySchema that a table created
+// by an older version lacks. BigQuery only allows adding nullable columns,
+// which all the later ones are.
+func addMissingColumns(ctx context.Context, bqService *bigquery.Service, table *bigquery.Table) error {
+	have := map[string]bool{}
+	var fields []*bigquery.TableFieldSchema
+	if table.Schema != nil {
+		fields = table.Schema.Fields
+	}
+	for _, f := range fields {
+		have[f.Name] = true
+	}
+	added := false
+	for _, f := range inventorySchema().Fields {
+		if !have[f.Name] {
+			fields = append(fields, f)
+			added = true
+		}
+	}
+	if !added {
+		return nil
+	}
+	ref := table.TableReference
+	_, err := bqService.Tables.Patch(ref.ProjectId, ref.DatasetId, ref.TableId, &bigquery.Table{
+		Schema: &bigquery.TableSchema{Fields: fields},
+	}).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("add columns: %w", err)
+	}
+	return nil
+}
+
+func inventorySchema() *bigquery.TableSchema {
+	return &bigquery.TableSchema{
+		Fields: []*bigquery.TableFieldSchema{
+			{Name: "scan_time", Type: "TIMESTAMP", Mode: "REQUIRED"},
+			{Name: "project_id", Type: "STRING", Mode: "REQUIRED"},
+			{Name: "section", Type: "STRING"},
+			{Name: "resource_type", Type: "STRING", Mode: "REQUIRED"},
+			{Name: "name", Type: "STRING"},
+			{Name: "attributes", Type: "RECORD", Mode: "REPEATED", Fields: []*bigquery.TableFieldSchema{
+				{Name: "key", Type: "STRING"},
+				{Name: "value", Type: "STRING"},
+			}},
+			{Name: "id", Type: "STRING"},
+			{Name: "asset_type", Type: "STRING"},
+			{Name: "location", Type: "STRING"},
+			{Name: "zone", Type: "STRING"},
+			{Name: "labels", Type: "RECORD", Mode: "REPEATED", Fields: []*bigquery.TableFieldSchema{
+				{Name: "key", Type: "STRING"},
+				{Name: "value", Type: "STRING"},
+			}},
+			{Name: "create_time", Type: "STRING"},
+		},
+	}
+}
+
+func bigQueryRow(row inventoryRow) map[string]bigquery.JsonValue {
+	attributes := make([]map[string]string, 0, len(row.Fields))
+	for _, f := range row.Fields {
+		attributes = append(attributes, map[string]string{"key": f.Key, "value": f.Value})
+	}
+	labels := make([]map[string]string, 0, len(row.Labels))
+	for _, k := range sortedKeys(row.Labels) {
+		labels = append(labels, map[string]string{"key": k, "value": row.Labels[k]})
+	}
+	return map[string]bigquery.JsonValue{
+		// BigQuery timestamps have microsecond precision.
+		"scan_time":     row.ScanTime.UTC().Format("2006-01-02T15:04:05.999999Z07:00"),
+		"project_id":    row.ProjectID,
+		"section":       row.Section,
+		"resource_type": row.ResourceType,
+		"name":          row.Name,
+		"attributes":    attributes,
+		"id":            row.key(),
+		"asset_type":    row.AssetType,
+		"location":      row.Location,
+		"zone":          row.Zone,
+		"labels":        labels,
+		"create_time":   row.CreateTime,
+	}
+}
+
+// parseTableSpec accepts dataset.table (billed to the scanned project) or
+// project.dataset.table.
+func parseTableSpec(spec string) (bqProject, dataset, table string, err error) {
+	parts := strings.Split(spec, ".")
+	switch {
+	case len(parts) == 2 && parts[0] != "" && parts[1] != "":
+		return projectID, parts[0], parts[1], nil
+	case len(parts) == 3 && parts[0] != "" && parts[1] != "" && parts[2] != "":
+		return parts[0], parts[1], parts[2], nil
+	}
+	return "", "", "", fmt.Errorf("invalid BigQuery table %q, expected dataset.table or project.dataset.table", spec)
+}
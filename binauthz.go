@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"sort"
+	"strings"
+
+	"google.golang.org/api/binaryauthorization/v1"
+)
+
+// getBinaryAuthorization reports the project's Binary Authorization policy,
+// one entry per admission rule, and the attestors rules can require.
+func getBinaryAuthorization(ctx context.Context) {
+	baService, err := binaryauthorization.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create Binary Authorization service", "error", err)
+		return
+	}
+
+	policy, err := baService.Projects.GetPolicy(fmt.Sprintf("projects/%s/policy", projectID)).Context(ctx).Do()
+	if err != nil {
+		// Skip projects that don't have the Binary Authorization API enabled
+		slog.Debug("Skipping Binary Authorization", "error", err)
+		return
+	}
+
+	var exempt []string
+	for _, pattern := range policy.AdmissionWhitelistPatterns {
+		exempt = append(exempt, pattern.NamePattern)
+	}
+	info := fmt.Sprintf("Name: policy\nGlobal Policy Evaluation: %s\nExempt Images: %s\nUpdated: %s",
+		policy.GlobalPolicyEvaluationMode, strings.Join(exempt, ", "), policy.UpdateTime)
+	writeResourceRaw("Binary Authorization Policy", info, policy)
+	count := 1
+
+	writeRule := func(scope string, rule *binaryauthorization.AdmissionRule) {
+		if rule == nil {
+			return
+		}
+		var attestors []string
+		for _, attestor := range rule.RequireAttestationsBy {
+			attestors = append(attestors, path.Base(attestor))
+		}
+		info := fmt.Sprintf("Name: %s\nEvaluation Mode: %s\nEnforcement Mode: %s\nRequired Attestors: %s",
+			scope, rule.EvaluationMode, rule.EnforcementMode, strings.Join(attestors, ", "))
+		writeResourceRaw("Admission Rule", info, rule)
+		count++
+	}
+	writeRule("default", policy.DefaultAdmissionRule)
+	for _, rules := range []struct {
+		kind  string
+		rules map[string]binaryauthorization.AdmissionRule
+	}{
+		{"cluster", policy.ClusterAdmissionRules},
+		{"namespace", policy.KubernetesNamespaceAdmissionRules},
+		{"service account", policy.KubernetesServiceAccountAdmissionRules},
+		{"Istio identity", policy.IstioServiceIdentityAdmissionRules},
+	} {
+		scopes := make([]string, 0, len(rules.rules))
+		for scope := range rules.rules {
+			scopes = append(scopes, scope)
+		}
+		sort.Strings(scopes)
+		for _, scope := range scopes {
+			rule := rules.rules[scope]
+			writeRule(rules.kind+" "+scope, &rule)
+		}
+	}
+
+	attestors, err := baService.Projects.Attestors.List("projects/" + projectID).Context(ctx).Do()
+	if err != nil {
+		slog.Error("Failed to list attestors", "error", err)
+		scanProgress.found(count)
+		return
+	}
+	for _, attestor := range attestors.Attestors {
+		note, keys := "", 0
+		if attestor.UserOwnedGrafeasNote != nil {
+			note, keys = attestor.UserOwnedGrafeasNote.NoteReference, len(attestor.UserOwnedGrafeasNote.PublicKeys)
+		}
+		info := fmt.Sprintf("Name: %s\nDescription: %s\nNote: %s\nPublic Keys: %d\nUpdated: %s",
+			path.Base(attestor.Name), oneLine(attestor.Description), note, keys, attestor.UpdateTime)
+		writeResourceRaw("Attestor", info, attestor)
+		count++
+	}
+	scanProgress.found(count)
+}
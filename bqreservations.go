@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+	"time"
+
+	bigquery "google.golang.org/api/bigquery/v2"
+	"google.golang.org/api/bigqueryreservation/v1"
+)
+
+// bigQueryJobWindow is how far back the job summary looks.
+const bigQueryJobWindow = 7 * 24 * time.Hour
+
+// bigQueryJobLimit caps the jobs the summary reads, so projects running
+// millions of jobs a week don't turn it into the slowest collector.
+const bigQueryJobLimit = 20000
+
+// errJobLimit stops paging once bigQueryJobLimit jobs have been counted.
+var errJobLimit = fmt.Errorf("more than %d jobs", bigQueryJobLimit)
+
+// getBigQueryReservations lists the project's BigQuery slot reservations
+// with their assignments, and its capacity commitments. Reservations live
+// in a location, so the US and EU multi-regions are checked as well as
+// every scanned region.
+func getBigQueryReservations(ctx context.Context) {
+	resService, err := bigqueryreservation.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create BigQuery Reservation service", "error", err)
+		return
+	}
+
+	count := 0
+	for _, location := range append([]string{"US", "EU"}, regions...) {
+		parent := fmt.Sprintf("projects/%s/locations/%s", projectID, location)
+		reservations, err := resService.Projects.Locations.Reservations.List(parent).Context(ctx).Do()
+		if err != nil {
+			// Skip projects that don't have the BigQuery Reservation API enabled
+			slog.Debug("Skipping BigQuery reservations", "location", location, "error", err)
+			continue
+		}
+		for _, r := range reservations.Reservations {
+			autoscale := ""
+			if r.Autoscale != nil {
+				autoscale = fmt.Sprintf("%d max, %d current", r.Autoscale.MaxSlots, r.Autoscale.CurrentSlots)
+			}
+			var assignees []string
+			assignments, err := resService.Projects.Locations.Reservations.Assignments.List(r.Name).Context(ctx).Do()
+			if err != nil {
+				slog.Error("Failed to list BigQuery reservation assignments", "reservation", r.Name, "error", err)
+			} else {
+				for _, a := range assignments.Assignments {
+					assignees = append(assignees, fmt.Sprintf("%s (%s)", a.Assignee, a.JobType))
+				}
+			}
+			info := fmt.Sprintf("Name: %s\nLocation: %s\nEdition: %s\nBaseline Slots: %d\nAutoscale: %s\nIgnore Idle Slots: %t\nAssignments: %s\nCreated: %s",
+				path.Base(r.Name), location, r.Edition, r.SlotCapacity, autoscale, r.IgnoreIdleSlots,
+				strings.Join(assignees, ", "), r.CreationTime)
+			writeResourceRaw("BigQuery Reservation", info, r)
+			count++
+		}
+
+		commitments, err := resService.Projects.Locations.CapacityCommitments.List(parent).Context(ctx).Do()
+		if err != nil {
+			slog.Error("Failed to list BigQuery capacity commitments", "location", location, "error", err)
+			continue
+		}
+		for _, c := range commitments.CapacityCommitments {
+			info := fmt.Sprintf("Name: %s\nLocation: %s\nEdition: %s\nSlots: %d\nPlan: %s\nRenewal Plan: %s\nState: %s\nEnds: %s",
+				path.Base(c.Name), location, c.Edition, c.SlotCount, c.Plan, c.RenewalPlan, c.State, c.CommitmentEndTime)
+			writeResourceRaw("BigQuery Capacity Commitment", info, c)
+			count++
+		}
+	}
+	scanProgress.found(count)
+}
+
+// getBigQueryJobSummary counts the jobs run in the project over the last
+// week by type, with failures and bytes processed, as a measure of how
+// heavily BigQuery is used.
+func getBigQueryJobSummary(ctx context.Context) {
+	bqService, err := bigquery.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create BigQuery service", "error", err)
+		return
+	}
+
+	since := time.Now().Add(-bigQueryJobWindow)
+	byType := map[string]int{}
+	total, failed := 0, 0
+	var bytesProcessed int64
+	err = bqService.Jobs.List(projectID).AllUsers(true).MinCreationTime(uint64(since.UnixMilli())).
+		Projection("full").MaxResults(1000).
+		Fields("nextPageToken", "jobs(configuration/jobType,statistics/totalBytesProcessed,errorResult)").
+		Pages(ctx, func(list *bigquery.JobList) error {
+			for _, job := range list.Jobs {
+				jobType := "UNKNOWN"
+				if job.Configuration != nil && job.Configuration.JobType != "" {
+					jobType = job.Configuration.JobType
+				}
+				byType[jobType]++
+				if job.ErrorResult != nil {
+					failed++
+				}
+				if job.Statistics != nil {
+					bytesProcessed += job.Statistics.TotalBytesProcessed
+				}
+				total++
+			}
+			if total >= bigQueryJobLimit {
+				return errJobLimit
+			}
+			return nil
+		})
+	truncated := err == errJobLimit
+	if err != nil && !truncated {
+		// Skip projects that don't have the BigQuery API enabled
+		slog.Debug("Skipping BigQuery job summary", "error", err)
+		return
+	}
+
+	var types []string
+	for _, t := range sortedKeys(byType) {
+		types = append(types, fmt.Sprintf("%s=%d", t, byType[t]))
+	}
+	info := fmt.Sprintf("Name: %s\nSince: %s\nJobs: %d\nBy Type: %s\nFailed: %d\nBytes Processed: %d\nTruncated: %t",
+		projectID, since.UTC().Format(time.RFC3339), total, strings.Join(types, ", "), failed, bytesProcessed, truncated)
+	writeResource("BigQuery Job Summary", info)
+	scanProgress.found(1)
+}
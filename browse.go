@@ -0,0 +1,433 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/term"
+)
+
+// loadInventoryFile reads a saved inventory: a state file (.last.json or
+// .state.json), a JSON array of resources, or an --format=ndjson report.
+func loadInventoryFile(path string) ([]inventoryRow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimSpace(data)
+
+	var rows []inventoryRow
+	var first map[string]json.RawMessage
+	switch {
+	case bytes.HasPrefix(data, []byte("[")):
+		err = json.Unmarshal(data, &rows)
+	case json.NewDecoder(bytes.NewReader(data)).Decode(&first) == nil && first["inventory"] != nil:
+		var state scanState
+		if state, err = decodeState(data); err == nil {
+			err = checkSchemaVersion(state.SchemaVersion)
+		}
+		rows = state.Inventory
+	default:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		for dec.More() {
+			var row inventoryRow
+			if err = dec.Decode(&row); err != nil {
+				break
+			}
+			rows = append(rows, row)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return rows, nil
+}
+
+// runBrowse opens a saved inventory in an interactive terminal browser.
+func runBrowse(path string) error {
+	rows, err := loadInventoryFile(path)
+	if err != nil {
+		return err
+	}
+	if len(rows) == 0 {
+		return fmt.Errorf("%s has no resources", path)
+	}
+
+	in, out := int(os.Stdin.Fd()), int(os.Stdout.Fd())
+	if !term.IsTerminal(in) || !term.IsTerminal(out) {
+		return fmt.Errorf("browse needs an interactive terminal")
+	}
+	oldState, err := term.MakeRaw(in)
+	if err != nil {
+		return err
+	}
+	defer term.Restore(in, oldState)
+
+	// Alternate screen with the cursor hidden, restored on the way out.
+	fmt.Print("\x1b[?1049h\x1b[?25l")
+	defer fmt.Print("\x1b[?25h\x1b[?1049l")
+
+	b := newBrowser(path, rows)
+	buf := make([]byte, 16)
+	for {
+		width, height, err := term.GetSize(out)
+		if err != nil {
+			width, height = 80, 24
+		}
+		b.draw(os.Stdout, width, height)
+
+		n, err := os.Stdin.Read(buf)
+		if err != nil {
+			return err
+		}
+		if !b.key(string(buf[:n]), height) {
+			return nil
+		}
+	}
+}
+
+// browser is the state of the browse UI: a tree of group (region or
+// service), resource type and resource on the left, and the selected item's
+// details on the right.
+type browser struct {
+	file     string
+	rows     []inventoryRow
+	services map[string]string
+	groupBy  string // region or service
+	query    string
+	search   bool // typing a search query
+	expanded map[string]bool
+	items    []browseItem
+	cursor   int
+	offset   int
+}
+
+// browseItem is one visible line of the tree. Folders have no row.
+type browseItem struct {
+	key   string
+	label string
+	depth int
+	count int
+	rows  []*inventoryRow
+	row   *inventoryRow
+}
+
+func newBrowser(file string, rows []inventoryRow) *browser {
+	b := &browser{
+		file:     file,
+		rows:     rows,
+		services: collectorServices(),
+		groupBy:  "region",
+		expanded: map[string]bool{},
+	}
+	b.rebuild()
+	return b
+}
+
+func (b *browser) group(row inventoryRow) string {
+	if b.groupBy == "service" {
+		_, name, _ := strings.Cut(row.Collector, "/")
+		if service, ok := b.services[name]; ok {
+			return service
+		}
+		return "other"
+	}
+	return rowRegion(row)
+}
+
+func (b *browser) matches(row inventoryRow) bool {
+	if b.query == "" {
+		return true
+	}
+	q := strings.ToLower(b.query)
+	if strings.Contains(strings.ToLower(row.ResourceType+"\x00"+row.Name), q) {
+		return true
+	}
+	for _, f := range row.Fields {
+		if strings.Contains(strings.ToLower(f.Value), q) {
+			return true
+		}
+	}
+	return false
+}
+
+// rebuild flattens the tree into the visible items. Every folder is open
+// while a search is active so all matches show.
+func (b *browser) rebuild() {
+	tree := map[string]map[string][]*inventoryRow{}
+	for i := range b.rows {
+		row := &b.rows[i]
+		if !b.matches(*row) {
+			continue
+		}
+		g := b.group(*row)
+		if tree[g] == nil {
+			tree[g] = map[string][]*inventoryRow{}
+		}
+		tree[g][row.ResourceType] = append(tree[g][row.ResourceType], row)
+	}
+
+	b.items = b.items[:0]
+	for _, g := range sortedKeys(tree) {
+		types := tree[g]
+		folder := browseItem{key: g, label: g, depth: 0}
+		for _, rows := range types {
+			folder.count += len(rows)
+			folder.rows = append(folder.rows, rows...)
+		}
+		b.items = append(b.items, folder)
+		if !b.open(g) {
+			continue
+		}
+		for _, t := range sortedKeys(types) {
+			rows := types[t]
+			key := g + "\x00" + t
+			b.items = append(b.items, browseItem{key: key, label: t, depth: 1, count: len(rows), rows: rows})
+			if !b.open(key) {
+				continue
+			}
+			sort.SliceStable(rows, func(i, j int) bool { return rows[i].Name < rows[j].Name })
+			for _, row := range rows {
+				b.items = append(b.items, browseItem{key: key + "\x00" + row.Name, label: row.Name, depth: 2, row: row})
+			}
+		}
+	}
+	if b.cursor >= len(b.items) {
+		b.cursor = max(len(b.items)-1, 0)
+	}
+}
+
+func (b *browser) open(key string) bool {
+	return b.expanded[key] || b.query != ""
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// key handles one key press and reports whether to keep running.
+func (b *browser) key(k string, height int) bool {
+	if b.search {
+		switch k {
+		case "\r", "\n":
+			b.search = false
+		case "\x1b", "\x03":
+			b.search, b.query = false, ""
+		case "\x7f", "\b":
+			if b.query != "" {
+				_, size := utf8.DecodeLastRuneInString(b.query)
+				b.query = b.query[:len(b.query)-size]
+			}
+		default:
+			if !strings.HasPrefix(k, "\x1b") && k >= " " {
+				b.query += k
+			}
+		}
+		b.cursor, b.offset = 0, 0
+		b.rebuild()
+		return true
+	}
+
+	page := max(height-4, 1)
+	switch k {
+	case "q", "\x03":
+		return false
+	case "\x1b[A", "k":
+		b.cursor--
+	case "\x1b[B", "j":
+		b.cursor++
+	case "\x1b[5~":
+		b.cursor -= page
+	case "\x1b[6~":
+		b.cursor += page
+	case "\x1b[H", "g":
+		b.cursor = 0
+	case "\x1b[F", "G":
+		b.cursor = len(b.items) - 1
+	case "\x1b[C", "l":
+		if item, ok := b.selected(); ok && item.row == nil {
+			b.expanded[item.key] = true
+			b.rebuild()
+		}
+	case "\r", " ":
+		if item, ok := b.selected(); ok && item.row == nil {
+			b.expanded[item.key] = !b.expanded[item.key]
+			b.rebuild()
+		}
+	case "\x1b[D", "h":
+		// Collapse the current folder, or jump to the parent's line.
+		if item, ok := b.selected(); ok {
+			if item.row == nil && b.expanded[item.key] {
+				b.expanded[item.key] = false
+				b.rebuild()
+			} else {
+				for i := b.cursor - 1; i >= 0; i-- {
+					if b.items[i].depth < item.depth {
+						b.cursor = i
+						break
+					}
+				}
+			}
+		}
+	case "\t", "s":
+		if b.groupBy == "region" {
+			b.groupBy = "service"
+		} else {
+			b.groupBy = "region"
+		}
+		b.expanded = map[string]bool{}
+		b.cursor, b.offset = 0, 0
+		b.rebuild()
+	case "/":
+		b.search = true
+	case "\x1b":
+		b.query = ""
+		b.rebuild()
+	}
+	b.cursor = min(max(b.cursor, 0), max(len(b.items)-1, 0))
+	return true
+}
+
+func (b *browser) selected() (browseItem, bool) {
+	if b.cursor < len(b.items) {
+		return b.items[b.cursor], true
+	}
+	return browseItem{}, false
+}
+
+// draw renders the whole screen. Lines are padded to the full width so
+// nothing from the previous frame is left behind.
+func (b *browser) draw(w io.Writer, width, height int) {
+	body := max(height-2, 1)
+	if b.cursor < b.offset {
+		b.offset = b.cursor
+	}
+	if b.cursor >= b.offset+body {
+		b.offset = b.cursor - body + 1
+	}
+	leftWidth := min(max(width*2/5, 20), width-1)
+	rightWidth := max(width-leftWidth-3, 0)
+
+	var screen strings.Builder
+	screen.WriteString("\x1b[H")
+
+	header := fmt.Sprintf(" gcp_footprint browse: %s  %d resources  by %s", b.file, len(b.rows), b.groupBy)
+	if b.query != "" {
+		header += fmt.Sprintf("  filter %q", b.query)
+	}
+	screen.WriteString("\x1b[7m" + fit(header, width) + "\x1b[0m\r\n")
+
+	detail := b.detail(rightWidth)
+	for i := 0; i < body; i++ {
+		left := ""
+		if n := b.offset + i; n < len(b.items) {
+			item := b.items[n]
+			left = b.treeLine(item)
+			if n == b.cursor {
+				left = "\x1b[7m" + fit(left, leftWidth) + "\x1b[0m"
+			} else {
+				left = fit(left, leftWidth)
+			}
+		} else {
+			left = fit(left, leftWidth)
+		}
+		right := ""
+		if i < len(detail) {
+			right = detail[i]
+		}
+		screen.WriteString(left + " │ " + fit(right, rightWidth) + "\r\n")
+	}
+
+	footer := " ↑/↓ move  →/enter open  ← close  tab region/service  / search  esc clear  q quit"
+	if b.search {
+		footer = " Search: " + b.query + "█"
+	}
+	screen.WriteString("\x1b[7m" + fit(footer, width) + "\x1b[0m")
+	io.WriteString(w, screen.String())
+}
+
+func (b *browser) treeLine(item browseItem) string {
+	indent := strings.Repeat("  ", item.depth)
+	if item.row != nil {
+		return indent + "  " + item.label
+	}
+	marker := "▸ "
+	if b.open(item.key) {
+		marker = "▾ "
+	}
+	return fmt.Sprintf("%s%s%s (%d)", indent, marker, item.label, item.count)
+}
+
+// detail returns the right pane for the selected item: a resource's fields,
+// or a count by type for a folder.
+func (b *browser) detail(width int) []string {
+	item, ok := b.selected()
+	if !ok {
+		return []string{"No resources match."}
+	}
+	if item.row == nil {
+		lines := []string{item.label, strings.Repeat("─", min(utf8.RuneCountInString(item.label), width)), ""}
+		counts := map[string]int{}
+		for _, row := range item.rows {
+			counts[row.ResourceType]++
+		}
+		for _, t := range sortedKeys(counts) {
+			lines = append(lines, fmt.Sprintf("%5d  %s", counts[t], t))
+		}
+		return lines
+	}
+
+	row := item.row
+	lines := []string{
+		row.ResourceType + ": " + row.Name,
+		strings.Repeat("─", min(utf8.RuneCountInString(row.ResourceType+": "+row.Name), width)),
+		"",
+	}
+	keyWidth := 0
+	for _, f := range row.Fields {
+		keyWidth = max(keyWidth, utf8.RuneCountInString(f.Key))
+	}
+	for _, f := range row.Fields {
+		lines = append(lines, wrap(fmt.Sprintf("%-*s  %s", keyWidth, f.Key, f.Value), width, keyWidth+2)...)
+	}
+	lines = append(lines, "", "Section: "+row.Section, "Collector: "+row.Collector)
+	return lines
+}
+
+// fit truncates or pads s to exactly width columns.
+func fit(s string, width int) string {
+	n := utf8.RuneCountInString(s)
+	if n > width {
+		r := []rune(s)
+		if width > 0 {
+			return string(r[:width-1]) + "…"
+		}
+		return ""
+	}
+	return s + strings.Repeat(" ", width-n)
+}
+
+// wrap breaks s into lines of width columns, indenting continuation lines.
+func wrap(s string, width, indent int) []string {
+	if width <= indent+1 {
+		return []string{s}
+	}
+	r := []rune(s)
+	var lines []string
+	for len(r) > width {
+		lines = append(lines, string(r[:width]))
+		r = append([]rune(strings.Repeat(" ", indent)), r[width:]...)
+	}
+	return append(lines, string(r))
+}
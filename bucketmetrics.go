@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	monitoring "google.golang.org/api/monitoring/v3"
+)
+
+var bucketMetrics bool
+
+// bucketUsage is a bucket's size and object count from Cloud Monitoring.
+type bucketUsage struct {
+	bytes   float64
+	objects int64
+}
+
+// getBucketUsage returns the latest stored bytes and object count of every
+// bucket in the project, summed over storage classes. Cloud Storage reports
+// these metrics once a day, so they can lag behind the bucket by up to a
+// day and buckets created since aren't included.
+func getBucketUsage(ctx context.Context) (map[string]bucketUsage, error) {
+	monitoringService, err := monitoring.NewService(ctx, clientOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("create Monitoring service: %w", err)
+	}
+
+	usage := map[string]bucketUsage{}
+	end := time.Now().UTC()
+	start := end.Add(-48 * time.Hour)
+	for _, metric := range []string{"storage.googleapis.com/storage/total_bytes", "storage.googleapis.com/storage/object_count"} {
+		err := monitoringService.Projects.TimeSeries.List("projects/"+projectID).
+			Filter(fmt.Sprintf("metric.type = %q", metric)).
+			IntervalStartTime(start.Format(time.RFC3339)).
+			IntervalEndTime(end.Format(time.RFC3339)).
+			Pages(ctx, func(resp *monitoring.ListTimeSeriesResponse) error {
+				for _, series := range resp.TimeSeries {
+					// Points are newest first; one series per bucket and
+					// storage class.
+					if series.Resource == nil || len(series.Points) == 0 || series.Points[0].Value == nil {
+						continue
+					}
+					bucket := series.Resource.Labels["bucket_name"]
+					value := series.Points[0].Value
+					u := usage[bucket]
+					if value.DoubleValue != nil {
+						u.bytes += *value.DoubleValue
+					}
+					if value.Int64Value != nil {
+						u.objects += *value.Int64Value
+					}
+					usage[bucket] = u
+				}
+				return nil
+			})
+		if err != nil {
+			return nil, fmt.Errorf("list %s: %w", metric, err)
+		}
+	}
+	return usage, nil
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// How long cached lookups stay fresh. These only change when Google adds
+// machine types or zones or reprices something, so a stale entry costs at
+// most a slightly off estimate or a new zone scanned a few days late.
+const (
+	machineTypeCacheTTL = 7 * 24 * time.Hour
+	skuCacheTTL         = 24 * time.Hour
+	zoneCacheTTL        = 7 * 24 * time.Hour
+)
+
+var cacheDir string
+
+// cacheEntry is the on-disk form of a cached value.
+type cacheEntry[T any] struct {
+	Fetched time.Time `json:"fetched"`
+	Value   T         `json:"value"`
+}
+
+// cached returns the value stored under key in --cache-dir if it is younger
+// than ttl, and otherwise calls fetch and stores its result. Without
+// --cache-dir it just calls fetch. A cache that can't be read or written
+// is logged and otherwise ignored.
+func cached[T any](key string, ttl time.Duration, fetch func() (T, error)) (T, error) {
+	if cacheDir == "" {
+		return fetch()
+	}
+	file := filepath.Join(cacheDir, cacheFileName(key))
+
+	if data, err := os.ReadFile(file); err == nil {
+		var entry cacheEntry[T]
+		if err := json.Unmarshal(data, &entry); err != nil {
+			slog.Debug("Ignoring unreadable cache entry", "key", key, "error", err)
+		} else if time.Since(entry.Fetched) < ttl {
+			return entry.Value, nil
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		slog.Debug("Failed to read cache entry", "key", key, "error", err)
+	}
+
+	value, err := fetch()
+	if err != nil {
+		return value, err
+	}
+	if err := writeCacheEntry(file, cacheEntry[T]{Fetched: time.Now(), Value: value}); err != nil {
+		slog.Warn("Failed to write cache entry", "key", key, "error", err)
+	}
+	return value, nil
+}
+
+func writeCacheEntry[T any](file string, entry cacheEntry[T]) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return err
+	}
+	// With --projects each project is scanned by its own child process,
+	// and they all share the cache directory. Writing a temporary file and
+	// renaming it means another process never reads a half-written entry.
+	tmp, err := os.CreateTemp(filepath.Dir(file), ".cache-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+// cacheFileName turns a key like "machine-types/us-central1-a/e2-small"
+// into a flat file name.
+func cacheFileName(key string) string {
+	return strings.NewReplacer("/", "_", "\\", "_", ":", "_").Replace(key) + ".json"
+}
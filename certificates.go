@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+	"time"
+
+	"google.golang.org/api/certificatemanager/v1"
+	"google.golang.org/api/compute/v1"
+)
+
+// certExpiryWarning is how close to expiry a certificate is flagged.
+const certExpiryWarning = 30 * 24 * time.Hour
+
+// getSSLCertificates lists classic load balancer SSL certificates, global
+// and regional.
+func getSSLCertificates(ctx context.Context) {
+	computeService, err := compute.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create compute service", "error", err)
+		return
+	}
+
+	certs, err := computeService.SslCertificates.AggregatedList(projectID).Context(ctx).Do()
+	if err != nil {
+		slog.Error("Failed to list SSL certificates", "error", err)
+		return
+	}
+
+	count := 0
+	for _, scoped := range certs.Items {
+		for _, cert := range scoped.SslCertificates {
+			domains, status := cert.SubjectAlternativeNames, ""
+			if cert.Managed != nil {
+				domains, status = cert.Managed.Domains, cert.Managed.Status
+			}
+			info := fmt.Sprintf("Name: %s\nType: %s\nDomains: %s\nStatus: %s\nRegion: %s\n%s",
+				cert.Name, cert.Type, strings.Join(domains, ", "), status, cert.Region,
+				certExpiryInfo(cert.Name, cert.ExpireTime))
+			writeResourceRaw("SSL Certificate", info, cert)
+			count++
+		}
+	}
+	scanProgress.found(count)
+}
+
+// getCertificateManager lists Certificate Manager certificates and the
+// certificate maps that attach them to load balancers.
+func getCertificateManager(ctx context.Context) {
+	cmService, err := certificatemanager.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create Certificate Manager service", "error", err)
+		return
+	}
+
+	parent := fmt.Sprintf("projects/%s/locations/global", projectID)
+	certs, err := cmService.Projects.Locations.Certificates.List(parent).Context(ctx).Do()
+	if err != nil {
+		// Skip projects that don't have the Certificate Manager API enabled
+		slog.Debug("Skipping Certificate Manager", "error", err)
+		return
+	}
+
+	count := 0
+	for _, cert := range certs.Certificates {
+		certType, status := "SELF_MANAGED", ""
+		if cert.ManagedCertificate != nil {
+			certType, status = "MANAGED", cert.ManagedCertificate.State
+		}
+		name := path.Base(cert.Name)
+		info := fmt.Sprintf("Name: %s\nType: %s\nDomains: %s\nStatus: %s\nScope: %s\n%s",
+			name, certType, strings.Join(cert.SanDnsnames, ", "), status, cert.Scope,
+			certExpiryInfo(name, cert.ExpireTime))
+		writeResourceRaw("Certificate Manager Certificate", info, cert)
+		count++
+	}
+
+	maps, err := cmService.Projects.Locations.CertificateMaps.List(parent).Context(ctx).Do()
+	if err != nil {
+		slog.Error("Failed to list certificate maps", "error", err)
+		scanProgress.found(count)
+		return
+	}
+	for _, certMap := range maps.CertificateMaps {
+		var targets []string
+		for _, target := range certMap.GclbTargets {
+			if target.TargetHttpsProxy != "" {
+				targets = append(targets, path.Base(target.TargetHttpsProxy))
+			}
+			if target.TargetSslProxy != "" {
+				targets = append(targets, path.Base(target.TargetSslProxy))
+			}
+		}
+
+		var hostnames []string
+		entries, err := cmService.Projects.Locations.CertificateMaps.CertificateMapEntries.List(certMap.Name).Context(ctx).Do()
+		if err != nil {
+			slog.Error("Failed to list certificate map entries", "map", certMap.Name, "error", err)
+		} else {
+			for _, entry := range entries.CertificateMapEntries {
+				hostname := entry.Hostname
+				if hostname == "" {
+					hostname = entry.Matcher
+				}
+				hostnames = append(hostnames, hostname)
+			}
+		}
+
+		info := fmt.Sprintf("Name: %s\nDescription: %s\nEntries: %s\nTargets: %s",
+			path.Base(certMap.Name), oneLine(certMap.Description), strings.Join(hostnames, ", "), strings.Join(targets, ", "))
+		writeResourceRaw("Certificate Map", info, certMap)
+		count++
+	}
+	scanProgress.found(count)
+}
+
+// certExpiryInfo returns the Expires and Expiring Soon fields for a
+// certificate, warning about certificates that expire within
+// certExpiryWarning. Certificates still being provisioned have no expiry.
+func certExpiryInfo(name, expireTime string) string {
+	expires, err := time.Parse(time.RFC3339, expireTime)
+	if err != nil {
+		return fmt.Sprintf("Expires: %s\nExpiring Soon: false", expireTime)
+	}
+
+	soon := expires.Sub(scanTime) < certExpiryWarning
+	if soon {
+		slog.Warn("Certificate expires soon", "certificate", name, "expires", expires.Format(time.RFC3339))
+	}
+	return fmt.Sprintf("Expires: %s\nExpiring Soon: %v", expires.Format(time.RFC3339), soon)
+}
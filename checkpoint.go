@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// scanState is persisted after every collector so an interrupted scan can
+// pick up where it stopped with --resume. It is also the format of the
+// snapshot of the last complete scan.
+type scanState struct {
+	SchemaVersion string         `json:"schema_version"`
+	ProjectID     string         `json:"project_id"`
+	ScanTime      time.Time      `json:"scan_time"`
+	Completed     []string       `json:"completed"`
+	Inventory     []inventoryRow `json:"inventory"`
+}
+
+var (
+	checkpointFile string
+	completed      = map[string]bool{}
+
+	// checkpointed is how many rows of inventory the state file holds, or
+	// -1 until the current scan has written it.
+	checkpointed = -1
+)
+
+func checkpointKey(c collector, region string) string {
+	if region == "" {
+		region = "global"
+	}
+	return region + "/" + c.name
+}
+
+// loadCheckpoint restores the scan time, completed collectors and inventory
+// of a previous run of the same project.
+func loadCheckpoint() error {
+	state, err := readState(checkpointFile)
+	if errors.Is(err, os.ErrNotExist) {
+		slog.Info("No scan to resume, starting a new one", "state_file", checkpointFile)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	scanTime = state.ScanTime
+	inventory = state.Inventory
+	for _, key := range state.Completed {
+		completed[key] = true
+	}
+	slog.Info("Resuming scan", "started", scanTime.Format(time.RFC3339),
+		"completed_collectors", len(state.Completed), "resources", len(inventory))
+
+	// Compact the appended records into one, which the rest of this scan
+	// appends to.
+	if err := writeState(checkpointFile, finishedScan()); err != nil {
+		slog.Error("Failed to write scan state", "error", err)
+		return nil
+	}
+	checkpointed = len(inventory)
+	return nil
+}
+
+// saveCheckpoint marks a collector as done and records it in the state
+// file. The scan's first checkpoint writes the whole state; each one after
+// appends a line with just the collector and the resources it found, so
+// the inventory isn't rewritten after every collector.
+func saveCheckpoint(key string) {
+	completed[key] = true
+
+	if checkpointed < 0 {
+		if err := writeState(checkpointFile, finishedScan()); err != nil {
+			slog.Error("Failed to write scan state", "error", err)
+			return
+		}
+		checkpointed = len(inventory)
+		return
+	}
+
+	err := appendState(checkpointFile, scanState{
+		ProjectID: projectID,
+		ScanTime:  scanTime,
+		Completed: []string{key},
+		Inventory: inventory[checkpointed:],
+	})
+	if err != nil {
+		slog.Error("Failed to write scan state", "error", err)
+		// Rewrite the whole state at the next checkpoint.
+		checkpointed = -1
+		return
+	}
+	checkpointed = len(inventory)
+}
+
+// appendState adds a record to the end of a state file.
+func appendState(path string, record scanState) error {
+	record.SchemaVersion = inventorySchemaVersion
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func readState(path string) (scanState, error) {
+	var state scanState
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return state, err
+	}
+	if state, err = decodeState(data); err != nil {
+		return state, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if err := checkSchemaVersion(state.SchemaVersion); err != nil {
+		return state, fmt.Errorf("%s: %w", path, err)
+	}
+	if state.ProjectID != projectID {
+		return state, fmt.Errorf("%s is for project %q", path, state.ProjectID)
+	}
+	return state, nil
+}
+
+// decodeState parses a state file: one scanState, followed in a checkpoint
+// by a record per collector finished since, whose completed collectors and
+// resources are added to it. A last record cut short by a crash is dropped,
+// so that collector runs again.
+func decodeState(data []byte) (scanState, error) {
+	var state scanState
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if err := dec.Decode(&state); err != nil {
+		return state, err
+	}
+	for dec.More() {
+		var record scanState
+		if err := dec.Decode(&record); err != nil {
+			slog.Warn("Ignoring an incomplete scan state record", "error", err)
+			break
+		}
+		state.Completed = append(state.Completed, record.Completed...)
+		state.Inventory = append(state.Inventory, record.Inventory...)
+	}
+	return state, nil
+}
+
+// writeState replaces path atomically, so a crash mid-write never leaves a
+// corrupt state file behind.
+func writeState(path string, state scanState) error {
+	state.SchemaVersion = inventorySchemaVersion
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// removeCheckpoint deletes the state file once a scan has finished.
+func removeCheckpoint() {
+	if err := os.Remove(checkpointFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+		slog.Warn("Failed to remove scan state", "state_file", checkpointFile, "error", err)
+	}
+}
+
+// replayInventory writes resources restored from a checkpoint to the text
+// or streamed report, so a resumed report contains everything found before
+// the interruption.
+func replayInventory() {
+	for _, row := range inventory {
+		streamResource(row)
+		if row.Section != currentSection {
+			writeSection(row.Section)
+		}
+		if _, err := fmt.Fprintf(report, "\n[%s]\n%s\n", row.ResourceType, row.info()); err != nil {
+			slog.Error("Failed to write resource", "error", err)
+		}
+	}
+}
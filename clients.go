@@ -0,0 +1,184 @@
+package main
+
+import (
+	"context"
+
+	container "cloud.google.com/go/container/apiv1"
+	"cloud.google.com/go/container/apiv1/containerpb"
+	"google.golang.org/api/compute/v1"
+	sqladmin "google.golang.org/api/sqladmin/v1"
+)
+
+// The core Compute Engine, Cloud SQL and GKE collectors call their APIs
+// through these interfaces, opened with the new*API variables, so tests can
+// swap in fakes and check a collector's report entries without a project.
+// Each method lists or gets one kind of resource of projectID.
+
+// computeAPI is the part of the Compute Engine API the core collectors use.
+type computeAPI interface {
+	Region(ctx context.Context, region string) (*compute.Region, error)
+	Project(ctx context.Context) (*compute.Project, error)
+	Instances(ctx context.Context, zone string) ([]*compute.Instance, error)
+	Disks(ctx context.Context, zone string) ([]*compute.Disk, error)
+	Snapshots(ctx context.Context) ([]*compute.Snapshot, error)
+	Networks(ctx context.Context) ([]*compute.Network, error)
+	Subnetworks(ctx context.Context, region string) ([]*compute.Subnetwork, error)
+	Firewalls(ctx context.Context) ([]*compute.Firewall, error)
+	ForwardingRules(ctx context.Context, region string) ([]*compute.ForwardingRule, error)
+	GlobalForwardingRules(ctx context.Context) ([]*compute.ForwardingRule, error)
+}
+
+// sqlAPI is the part of the Cloud SQL Admin API the Cloud SQL collector
+// uses.
+type sqlAPI interface {
+	Instances(ctx context.Context) ([]*sqladmin.DatabaseInstance, error)
+	Databases(ctx context.Context, instance string) ([]*sqladmin.Database, error)
+	Users(ctx context.Context, instance string) ([]*sqladmin.User, error)
+}
+
+// gkeAPI is the part of the GKE API the cluster collector uses. It holds a
+// gRPC connection, so it must be closed.
+type gkeAPI interface {
+	Clusters(ctx context.Context, location string) ([]*containerpb.Cluster, error)
+	Close() error
+}
+
+var (
+	newComputeAPI = func(ctx context.Context) (computeAPI, error) {
+		s, err := compute.NewService(ctx, clientOptions...)
+		if err != nil {
+			return nil, err
+		}
+		return liveCompute{s}, nil
+	}
+	newSQLAPI = func(ctx context.Context) (sqlAPI, error) {
+		s, err := sqladmin.NewService(ctx, clientOptions...)
+		if err != nil {
+			return nil, err
+		}
+		return liveSQL{s}, nil
+	}
+	newGKEAPI = func(ctx context.Context) (gkeAPI, error) {
+		c, err := container.NewClusterManagerClient(ctx, clientOptions...)
+		if err != nil {
+			return nil, err
+		}
+		return liveGKE{c}, nil
+	}
+)
+
+type liveCompute struct{ s *compute.Service }
+
+func (c liveCompute) Region(ctx context.Context, region string) (*compute.Region, error) {
+	return c.s.Regions.Get(projectID, region).Context(ctx).Do()
+}
+
+func (c liveCompute) Project(ctx context.Context) (*compute.Project, error) {
+	return c.s.Projects.Get(projectID).Context(ctx).Do()
+}
+
+func (c liveCompute) Instances(ctx context.Context, zone string) ([]*compute.Instance, error) {
+	list, err := c.s.Instances.List(projectID, zone).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+func (c liveCompute) Disks(ctx context.Context, zone string) ([]*compute.Disk, error) {
+	list, err := c.s.Disks.List(projectID, zone).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+func (c liveCompute) Snapshots(ctx context.Context) ([]*compute.Snapshot, error) {
+	list, err := c.s.Snapshots.List(projectID).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+func (c liveCompute) Networks(ctx context.Context) ([]*compute.Network, error) {
+	list, err := c.s.Networks.List(projectID).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+func (c liveCompute) Subnetworks(ctx context.Context, region string) ([]*compute.Subnetwork, error) {
+	list, err := c.s.Subnetworks.List(projectID, region).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+func (c liveCompute) Firewalls(ctx context.Context) ([]*compute.Firewall, error) {
+	list, err := c.s.Firewalls.List(projectID).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+func (c liveCompute) ForwardingRules(ctx context.Context, region string) ([]*compute.ForwardingRule, error) {
+	list, err := c.s.ForwardingRules.List(projectID, region).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+func (c liveCompute) GlobalForwardingRules(ctx context.Context) ([]*compute.ForwardingRule, error) {
+	list, err := c.s.GlobalForwardingRules.List(projectID).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+type liveSQL struct{ s *sqladmin.Service }
+
+func (c liveSQL) Instances(ctx context.Context) ([]*sqladmin.DatabaseInstance, error) {
+	list, err := c.s.Instances.List(projectID).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+func (c liveSQL) Databases(ctx context.Context, instance string) ([]*sqladmin.Database, error) {
+	list, err := c.s.Databases.List(projectID, instance).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+func (c liveSQL) Users(ctx context.Context, instance string) ([]*sqladmin.User, error) {
+	list, err := c.s.Users.List(projectID, instance).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+type liveGKE struct {
+	c *container.ClusterManagerClient
+}
+
+func (g liveGKE) Clusters(ctx context.Context, location string) ([]*containerpb.Cluster, error) {
+	resp, err := g.c.ListClusters(ctx, &containerpb.ListClustersRequest{
+		Parent: "projects/" + projectID + "/locations/" + location,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Clusters, nil
+}
+
+func (g liveGKE) Close() error { return g.c.Close() }
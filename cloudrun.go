@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"slices"
+
+	run "google.golang.org/api/run/v2"
+)
+
+// getCloudRunServices lists the region's Cloud Run services with their URL,
+// ingress setting, runtime service account and whether anyone on the
+// internet may invoke them.
+func getCloudRunServices(ctx context.Context, region string) {
+	runService, err := run.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create Cloud Run service", "error", err)
+		return
+	}
+
+	parent := fmt.Sprintf("projects/%s/locations/%s", projectID, region)
+	count := 0
+	err = runService.Projects.Locations.Services.List(parent).Pages(ctx, func(page *run.GoogleCloudRunV2ListServicesResponse) error {
+		for _, service := range page.Services {
+			serviceAccount := ""
+			if service.Template != nil {
+				serviceAccount = service.Template.ServiceAccount
+			}
+			public := false
+			policy, err := runService.Projects.Locations.Services.GetIamPolicy(service.Name).Context(ctx).Do()
+			if err != nil {
+				slog.Error("Failed to get Cloud Run IAM policy", "service", service.Name, "error", err)
+			} else {
+				for _, binding := range policy.Bindings {
+					if binding.Role == "roles/run.invoker" &&
+						(slices.Contains(binding.Members, "allUsers") || slices.Contains(binding.Members, "allAuthenticatedUsers")) {
+						public = true
+					}
+				}
+			}
+			info := fmt.Sprintf("Name: %s\nRegion: %s\nURL: %s\nIngress: %s\nPublic Invoker: %t\nService Account: %s\nUpdated: %s",
+				path.Base(service.Name), region, service.Uri, service.Ingress, public, serviceAccount, service.UpdateTime)
+			writeResourceRaw("Cloud Run Service", info, service)
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		// Skip projects that don't have the Cloud Run API enabled
+		slog.Debug("Skipping Cloud Run services", "region", region, "error", err)
+	}
+	scanProgress.found(count)
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	sqladmin "google.golang.org/api/sqladmin/v1"
+)
+
+// getCloudSQLDatabases lists the databases and database users of a Cloud
+// SQL instance. Users are reported by name, host and type only; the Admin
+// API never returns passwords. It returns the number of resources
+// reported.
+func getCloudSQLDatabases(ctx context.Context, sqlService sqlAPI, instance *sqladmin.DatabaseInstance) int {
+	// Stopped instances can't be queried for either.
+	if instance.State != "RUNNABLE" {
+		slog.Debug("Skipping Cloud SQL databases", "instance", instance.Name, "state", instance.State)
+		return 0
+	}
+
+	count := 0
+	databases, err := sqlService.Databases(ctx, instance.Name)
+	if err != nil {
+		slog.Error("Failed to list Cloud SQL databases", "instance", instance.Name, "error", err)
+	} else {
+		for _, db := range databases {
+			info := fmt.Sprintf("Name: %s\nInstance: %s\nRegion: %s\nCharset: %s\nCollation: %s",
+				db.Name, instance.Name, instance.Region, db.Charset, db.Collation)
+			writeResourceRaw("Cloud SQL Database", info, db)
+			count++
+		}
+	}
+
+	users, err := sqlService.Users(ctx, instance.Name)
+	if err != nil {
+		slog.Error("Failed to list Cloud SQL users", "instance", instance.Name, "error", err)
+		return count
+	}
+	for _, user := range users {
+		userType := user.Type
+		if userType == "" {
+			userType = "BUILT_IN"
+		}
+		// MySQL users are accounts of a name and a host, so root@% and
+		// root@localhost are different users. Postgres users have no host.
+		name := user.Name
+		if user.Host != "" {
+			name += "@" + user.Host
+		}
+		info := fmt.Sprintf("Name: %s\nInstance: %s\nRegion: %s\nHost: %s\nType: %s",
+			name, instance.Name, instance.Region, user.Host, userType)
+		writeResourceRaw("Cloud SQL User", info, user)
+		count++
+	}
+	return count
+}
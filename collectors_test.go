@@ -0,0 +1,434 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/container/apiv1/containerpb"
+	"google.golang.org/api/compute/v1"
+	sqladmin "google.golang.org/api/sqladmin/v1"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// TestCollectorsGolden runs the core collectors against fakes of a small
+// project in two regions and compares the report entries and NDJSON rows
+// they write with the golden files in testdata. After an intended change
+// to a collector's output, regenerate them with go test -update and review
+// the diff.
+func TestCollectorsGolden(t *testing.T) {
+	tests := []struct {
+		name       string
+		collectors []string
+		compute    computeAPI
+		sql        sqlAPI
+		gke        gkeAPI
+	}{
+		{
+			name: "compute",
+			collectors: []string{
+				"firewall rules", "snapshots", "global forwarding rules",
+				"compute instances", "VPC networks", "subnets", "persistent disks", "forwarding rules",
+			},
+			compute: testCompute(),
+		},
+		{name: "cloudsql", collectors: []string{"Cloud SQL instances"}, sql: testSQL()},
+		{name: "gke", collectors: []string{"GKE clusters"}, gke: testGKE()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useFakes(t, tt.compute, tt.sql, tt.gke)
+			text, ndjson := scanWithFakes(t, tt.collectors...)
+			checkGolden(t, tt.name+".txt", text)
+			checkGolden(t, tt.name+".ndjson", ndjson)
+			if g, ok := tt.gke.(*fakeGKE); ok && !g.closed {
+				t.Error("GKE client wasn't closed")
+			}
+		})
+	}
+}
+
+// scanWithFakes runs the named collectors in the order scan does, globals
+// first and then each region in turn, for my-project in us-central1 and
+// europe-west4. It returns the text report and the NDJSON inventory they
+// wrote.
+func scanWithFakes(t *testing.T, names ...string) (text, ndjson string) {
+	t.Helper()
+	savedProject, savedRegions, savedTime := projectID, regions, scanTime
+	savedReport, savedInventory, savedProgress := report, inventory, scanProgress
+	savedSection, savedCollector := currentSection, currentCollector
+	t.Cleanup(func() {
+		projectID, regions, scanTime = savedProject, savedRegions, savedTime
+		report, inventory, scanProgress = savedReport, savedInventory, savedProgress
+		currentSection, currentCollector = savedSection, savedCollector
+		regionZoneLists.Clear()
+	})
+
+	var buf bytes.Buffer
+	projectID, regions = "my-project", []string{"us-central1", "europe-west4"}
+	scanTime = time.Date(2026, 3, 2, 15, 4, 5, 0, time.UTC)
+	report, inventory, scanProgress = &buf, nil, newProgress(len(names)*len(regions), true)
+	currentSection, currentCollector = "", ""
+	regionZoneLists.Clear()
+
+	ctx := context.Background()
+	run := func(section string, c collector, region string) {
+		if section != currentSection {
+			writeSection(section)
+		}
+		currentCollector = checkpointKey(c, region)
+		scanProgress.begin(region, c.name)
+		c.run(ctx, region)
+		scanProgress.end()
+	}
+	var regional []collector
+	for _, name := range names {
+		if c, ok := lookupCollector(globalCollectors, name); ok {
+			run(c.section, c, "")
+			continue
+		}
+		c, ok := lookupCollector(regionalCollectors, name)
+		if !ok {
+			t.Fatalf("no collector named %q", name)
+		}
+		regional = append(regional, c)
+	}
+	for _, region := range regions {
+		for _, c := range regional {
+			run("REGION: "+region, c, region)
+		}
+	}
+
+	var rows bytes.Buffer
+	for _, row := range inventory {
+		if err := writeNDJSON(&rows, row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return buf.String(), rows.String()
+}
+
+func lookupCollector(collectors []collector, name string) (collector, bool) {
+	for _, c := range collectors {
+		if c.name == name {
+			return c, true
+		}
+	}
+	return collector{}, false
+}
+
+// checkGolden compares got with testdata/<name>.golden, or rewrites the
+// file with -update.
+func checkGolden(t *testing.T, name, got string) {
+	t.Helper()
+	file := filepath.Join("testdata", name+".golden")
+	if *update {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("%v; run go test -update to create it", err)
+	}
+	if got != string(want) {
+		t.Errorf("output differs from %s; run go test -update and review the diff\ngot:\n%s", file, got)
+	}
+}
+
+const computeURL = "https://www.googleapis.com/compute/v1/projects/my-project/"
+
+// testCompute is a project with zones in us-central1 only, so the zonal
+// collectors skip europe-west4 the way they skip a region the project
+// can't use.
+func testCompute() *fakeCompute {
+	enabled := "TRUE"
+	return &fakeCompute{
+		zones: map[string][]string{"us-central1": {"us-central1-b", "us-central1-a"}},
+		project: &compute.Project{
+			Name: "my-project",
+			CommonInstanceMetadata: &compute.Metadata{
+				Items: []*compute.MetadataItems{{Key: "enable-oslogin", Value: &enabled}},
+			},
+		},
+		instances: map[string][]*compute.Instance{
+			"us-central1-a": {{
+				Name:              "web-1",
+				MachineType:       computeURL + "zones/us-central1-a/machineTypes/e2-medium",
+				Status:            "RUNNING",
+				Zone:              computeURL + "zones/us-central1-a",
+				CreationTimestamp: "2025-11-03T09:12:44.120-08:00",
+				NetworkInterfaces: []*compute.NetworkInterface{{
+					Network:       computeURL + "global/networks/prod",
+					Subnetwork:    computeURL + "regions/us-central1/subnetworks/prod-us",
+					AccessConfigs: []*compute.AccessConfig{{NatIP: "34.123.45.67"}},
+				}},
+				ShieldedInstanceConfig: &compute.ShieldedInstanceConfig{
+					EnableSecureBoot: true, EnableVtpm: true, EnableIntegrityMonitoring: true,
+				},
+				ServiceAccounts: []*compute.ServiceAccount{{
+					Email:  "123456789012-compute@developer.gserviceaccount.com",
+					Scopes: []string{cloudPlatformScope},
+				}},
+				ResourcePolicies: []string{computeURL + "regions/us-central1/resourcePolicies/nightly-stop"},
+			}},
+			"us-central1-b": {{
+				Name:              "gpu-1",
+				MachineType:       computeURL + "zones/us-central1-b/machineTypes/g2-standard-8",
+				Status:            "TERMINATED",
+				Zone:              computeURL + "zones/us-central1-b",
+				CreationTimestamp: "2025-06-20T14:00:02.511-07:00",
+				LastStopTimestamp: "2026-01-15T18:30:00.000-08:00",
+				NetworkInterfaces: []*compute.NetworkInterface{{
+					Network:    computeURL + "global/networks/prod",
+					Subnetwork: computeURL + "regions/us-central1/subnetworks/prod-us",
+				}},
+				Metadata: &compute.Metadata{
+					Items: []*compute.MetadataItems{{Key: "serial-port-enable", Value: &enabled}},
+				},
+				ServiceAccounts: []*compute.ServiceAccount{{
+					Email:  "trainer@my-project.iam.gserviceaccount.com",
+					Scopes: []string{"https://www.googleapis.com/auth/devstorage.read_only"},
+				}},
+				GuestAccelerators: []*compute.AcceleratorConfig{{
+					AcceleratorCount: 2,
+					AcceleratorType:  computeURL + "zones/us-central1-b/acceleratorTypes/nvidia-l4",
+				}},
+				DeletionProtection: true,
+			}},
+		},
+		disks: map[string][]*compute.Disk{
+			"us-central1-a": {
+				{
+					Name:             "web-1",
+					SizeGb:           20,
+					Type:             computeURL + "zones/us-central1-a/diskTypes/pd-balanced",
+					Status:           "READY",
+					Zone:             computeURL + "zones/us-central1-a",
+					Users:            []string{computeURL + "zones/us-central1-a/instances/web-1"},
+					ResourcePolicies: []string{computeURL + "regions/us-central1/resourcePolicies/daily-snapshots"},
+				},
+				{
+					Name:   "orphan-data",
+					SizeGb: 500,
+					Type:   computeURL + "zones/us-central1-a/diskTypes/pd-ssd",
+					Status: "READY",
+					Zone:   computeURL + "zones/us-central1-a",
+					DiskEncryptionKey: &compute.CustomerEncryptionKey{
+						KmsKeyName: "projects/my-project/locations/us-central1/keyRings/disks/cryptoKeys/data/cryptoKeyVersions/3",
+					},
+				},
+			},
+		},
+		snapshots: []*compute.Snapshot{{
+			Name:              "web-1-20260301",
+			DiskSizeGb:        20,
+			Status:            "READY",
+			CreationTimestamp: "2026-03-01T02:00:11.204-08:00",
+			SourceDisk:        computeURL + "zones/us-central1-a/disks/web-1",
+			StorageBytes:      4831838208,
+		}},
+		networks: []*compute.Network{
+			{
+				Name:                  "default",
+				Description:           "Default network for the project",
+				AutoCreateSubnetworks: true,
+				CreationTimestamp:     "2024-02-11T08:00:00.000-08:00",
+			},
+			{
+				Name:              "prod",
+				Description:       "Production\nworkloads",
+				CreationTimestamp: "2024-05-30T10:21:09.000-07:00",
+				Peerings: []*compute.NetworkPeering{{
+					Name:    "to-shared",
+					Network: "https://www.googleapis.com/compute/v1/projects/shared-host/global/networks/shared",
+					State:   "ACTIVE",
+				}},
+			},
+		},
+		subnetworks: map[string][]*compute.Subnetwork{
+			"us-central1": {{
+				Name:              "prod-us",
+				Network:           computeURL + "global/networks/prod",
+				IpCidrRange:       "10.10.0.0/20",
+				Region:            computeURL + "regions/us-central1",
+				CreationTimestamp: "2024-05-30T10:25:41.000-07:00",
+				LogConfig: &compute.SubnetworkLogConfig{
+					Enable: true, FlowSampling: 0.5, AggregationInterval: "INTERVAL_5_SEC",
+				},
+			}},
+			"europe-west4": {{
+				Name:              "prod-eu",
+				Network:           computeURL + "global/networks/prod",
+				IpCidrRange:       "10.20.0.0/20",
+				Region:            computeURL + "regions/europe-west4",
+				CreationTimestamp: "2024-05-30T10:26:03.000-07:00",
+			}},
+		},
+		firewalls: []*compute.Firewall{
+			{
+				Name:         "default-allow-ssh",
+				Network:      computeURL + "global/networks/default",
+				Direction:    "INGRESS",
+				Priority:     65534,
+				SourceRanges: []string{"0.0.0.0/0"},
+			},
+			{
+				Name:         "allow-health-checks",
+				Network:      computeURL + "global/networks/prod",
+				Direction:    "INGRESS",
+				Priority:     1000,
+				SourceRanges: []string{"35.191.0.0/16", "130.211.0.0/22"},
+				TargetTags:   []string{"web"},
+			},
+		},
+		forwardingRules: map[string][]*compute.ForwardingRule{
+			"us-central1": {{
+				Name:                "internal-api",
+				IPAddress:           "10.10.0.50",
+				IPProtocol:          "TCP",
+				Ports:               []string{"80", "8080"},
+				LoadBalancingScheme: "INTERNAL",
+				BackendService:      computeURL + "regions/us-central1/backendServices/api",
+				Network:             computeURL + "global/networks/prod",
+				Subnetwork:          computeURL + "regions/us-central1/subnetworks/prod-us",
+				Region:              computeURL + "regions/us-central1",
+			}},
+		},
+		globalRules: []*compute.ForwardingRule{{
+			Name:                "web-https",
+			IPAddress:           "34.117.10.20",
+			IPProtocol:          "TCP",
+			PortRange:           "443-443",
+			LoadBalancingScheme: "EXTERNAL_MANAGED",
+			Target:              computeURL + "global/targetHttpsProxies/web",
+		}},
+	}
+}
+
+// testSQL has a running instance in each region and a suspended one,
+// whose databases and users aren't listed.
+func testSQL() *fakeSQL {
+	return &fakeSQL{
+		instances: []*sqladmin.DatabaseInstance{
+			{
+				Name:            "orders-db",
+				DatabaseVersion: "POSTGRES_15",
+				Region:          "us-central1",
+				State:           "RUNNABLE",
+				Settings: &sqladmin.Settings{
+					Tier: "db-custom-2-7680", AvailabilityType: "REGIONAL",
+					DataDiskSizeGb: 100, DataDiskType: "PD_SSD",
+					IpConfiguration: &sqladmin.IpConfiguration{
+						AuthorizedNetworks: []*sqladmin.AclEntry{{Value: "203.0.113.0/24"}, {Value: "198.51.100.7/32"}},
+					},
+				},
+				IpAddresses: []*sqladmin.IpMapping{
+					{Type: "PRIVATE", IpAddress: "10.30.0.3"},
+					{Type: "PRIMARY", IpAddress: "35.192.0.10"},
+				},
+				DiskEncryptionConfiguration: &sqladmin.DiskEncryptionConfiguration{
+					KmsKeyName: "projects/my-project/locations/us-central1/keyRings/sql/cryptoKeys/orders",
+				},
+			},
+			{
+				Name:            "reporting-db",
+				DatabaseVersion: "POSTGRES_15",
+				Region:          "us-central1",
+				State:           "SUSPENDED",
+				Settings:        &sqladmin.Settings{Tier: "db-f1-micro", AvailabilityType: "ZONAL", DataDiskSizeGb: 10, DataDiskType: "PD_HDD"},
+			},
+			{
+				Name:            "legacy-mysql",
+				DatabaseVersion: "MYSQL_8_0",
+				Region:          "europe-west4",
+				State:           "RUNNABLE",
+				Settings:        &sqladmin.Settings{Tier: "db-n1-standard-1", AvailabilityType: "ZONAL", DataDiskSizeGb: 50, DataDiskType: "PD_SSD"},
+				IpAddresses:     []*sqladmin.IpMapping{{Type: "PRIVATE", IpAddress: "10.40.0.5"}},
+			},
+		},
+		databases: map[string][]*sqladmin.Database{
+			"orders-db": {
+				{Name: "postgres", Charset: "UTF8", Collation: "en_US.UTF8"},
+				{Name: "orders", Charset: "UTF8", Collation: "en_US.UTF8"},
+			},
+			"reporting-db": {{Name: "reports", Charset: "UTF8", Collation: "en_US.UTF8"}},
+			"legacy-mysql": {{Name: "shop", Charset: "utf8mb4", Collation: "utf8mb4_0900_ai_ci"}},
+		},
+		users: map[string][]*sqladmin.User{
+			"orders-db": {
+				{Name: "postgres"},
+				{Name: "ci@my-project.iam", Type: "CLOUD_IAM_SERVICE_ACCOUNT"},
+			},
+			"reporting-db": {{Name: "postgres"}},
+			"legacy-mysql": {
+				{Name: "root", Host: "%"},
+				{Name: "root", Host: "localhost"},
+			},
+		},
+	}
+}
+
+// testGKE has clusters in us-central1 only; europe-west4 answers the way a
+// location without GKE does.
+func testGKE() *fakeGKE {
+	return &fakeGKE{clusters: map[string][]*containerpb.Cluster{
+		"us-central1": {
+			{
+				Name:                 "prod",
+				Location:             "us-central1",
+				CurrentMasterVersion: "1.30.5-gke.1014001",
+				CurrentNodeCount:     5,
+				Status:               containerpb.Cluster_RUNNING,
+				Endpoint:             "34.70.1.2",
+				PrivateClusterConfig: &containerpb.PrivateClusterConfig{EnablePrivateNodes: true},
+				MasterAuthorizedNetworksConfig: &containerpb.MasterAuthorizedNetworksConfig{
+					Enabled:    true,
+					CidrBlocks: []*containerpb.MasterAuthorizedNetworksConfig_CidrBlock{{CidrBlock: "10.0.0.0/8"}},
+				},
+				WorkloadIdentityConfig: &containerpb.WorkloadIdentityConfig{WorkloadPool: "my-project.svc.id.goog"},
+				BinaryAuthorization: &containerpb.BinaryAuthorization{
+					EvaluationMode: containerpb.BinaryAuthorization_PROJECT_SINGLETON_POLICY_ENFORCE,
+				},
+				NetworkConfig:  &containerpb.NetworkConfig{DatapathProvider: containerpb.DatapathProvider_ADVANCED_DATAPATH},
+				ShieldedNodes:  &containerpb.ShieldedNodes{Enabled: true},
+				ReleaseChannel: &containerpb.ReleaseChannel{Channel: containerpb.ReleaseChannel_REGULAR},
+				NodePools: []*containerpb.NodePool{
+					{
+						Name: "default-pool",
+						Config: &containerpb.NodeConfig{
+							MachineType: "e2-standard-4", ServiceAccount: "default", OauthScopes: []string{cloudPlatformScope},
+						},
+						InitialNodeCount: 1,
+						Locations:        []string{"us-central1-a", "us-central1-b", "us-central1-c"},
+					},
+					{
+						Name: "gpu-pool",
+						Config: &containerpb.NodeConfig{
+							MachineType: "g2-standard-8", ServiceAccount: "gke-nodes@my-project.iam.gserviceaccount.com",
+						},
+						InitialNodeCount: 2,
+						Locations:        []string{"us-central1-a"},
+					},
+				},
+			},
+			{
+				// A cluster that sets none of the security options.
+				Name:                 "sandbox",
+				Location:             "us-central1",
+				CurrentMasterVersion: "1.29.8-gke.1211000",
+				Status:               containerpb.Cluster_PROVISIONING,
+				Endpoint:             "35.226.9.8",
+			},
+		},
+	}}
+}
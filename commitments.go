@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+
+	"google.golang.org/api/compute/v1"
+)
+
+// getCommitments lists the region's committed use discounts and the
+// reservations in each of its zones, so commitments can be reconciled against
+// what actually runs. Commitments are pooled per region, so each one
+// reports the region's running vCPUs and memory against the total the
+// region's active commitments cover.
+func getCommitments(ctx context.Context, region string) {
+	computeService, err := compute.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create compute service", "error", err)
+		return
+	}
+
+	commitments, err := computeService.RegionCommitments.List(projectID, region).Context(ctx).Do()
+	if err != nil {
+		// Skip regions that don't exist or aren't enabled for this project
+		slog.Debug("Skipping region", "region", region, "error", err)
+		return
+	}
+
+	count := 0
+	if len(commitments.Items) > 0 {
+		committedCPUs, committedMemoryMB := int64(0), int64(0)
+		for _, c := range commitments.Items {
+			if c.Status != "ACTIVE" {
+				continue
+			}
+			for _, r := range c.Resources {
+				switch r.Type {
+				case "VCPU":
+					committedCPUs += r.Amount
+				case "MEMORY":
+					committedMemoryMB += r.Amount
+				}
+			}
+		}
+		runningCPUs, runningMemoryMB, err := regionUsage(ctx, computeService, region)
+		if err != nil {
+			slog.Error("Failed to measure commitment utilization", "region", region, "error", err)
+		}
+
+		for _, c := range commitments.Items {
+			var resources []string
+			for _, r := range c.Resources {
+				resource := fmt.Sprintf("%s %d", r.Type, r.Amount)
+				if r.AcceleratorType != "" {
+					resource += " " + path.Base(r.AcceleratorType)
+				}
+				resources = append(resources, resource)
+			}
+			var reservations []string
+			for _, r := range c.Reservations {
+				reservations = append(reservations, r.Name)
+			}
+			info := fmt.Sprintf("Name: %s\nRegion: %s\nPlan: %s\nType: %s\nCategory: %s\nStatus: %s\nStart: %s\nEnd: %s\nResources: %s\nReservations: %s\nRegion vCPU Utilization: %s\nRegion Memory Utilization: %s",
+				c.Name, region, c.Plan, c.Type, c.Category, c.Status, c.StartTimestamp, c.EndTimestamp,
+				strings.Join(resources, ", "), strings.Join(reservations, ", "),
+				utilization(runningCPUs, committedCPUs), utilization(runningMemoryMB, committedMemoryMB))
+			writeResourceRaw("Commitment", info, c)
+			count++
+		}
+	}
+
+	zones, err := regionZones(ctx, region)
+	if err != nil {
+		slog.Error("Failed to list zones", "region", region, "error", err)
+	}
+	for _, zone := range zones {
+		reservations, err := computeService.Reservations.List(projectID, zone).Context(ctx).Do()
+		if err != nil {
+			slog.Debug("Skipping zone", "zone", zone, "error", err)
+			continue
+		}
+		for _, r := range reservations.Items {
+			machineType, reserved, inUse := "", int64(0), int64(0)
+			if s := r.SpecificReservation; s != nil {
+				reserved, inUse = s.Count, s.InUseCount
+				if s.InstanceProperties != nil {
+					machineType = s.InstanceProperties.MachineType
+				}
+			}
+			share := ""
+			if r.ShareSettings != nil {
+				share = r.ShareSettings.ShareType
+			}
+			info := fmt.Sprintf("Name: %s\nZone: %s\nMachine Type: %s\nReserved: %d\nIn Use: %d\nUtilization: %s\nSpecific Reservation Required: %t\nCommitment: %s\nShare Type: %s\nStatus: %s",
+				r.Name, path.Base(r.Zone), machineType, reserved, inUse, utilization(inUse, reserved),
+				r.SpecificReservationRequired, path.Base(r.Commitment), share, r.Status)
+			writeResourceRaw("Reservation", info, r)
+			count++
+		}
+	}
+	scanProgress.found(count)
+}
+
+// regionUsage totals the vCPUs and memory of the region's running
+// instances.
+func regionUsage(ctx context.Context, computeService *compute.Service, region string) (cpus, memoryMB int64, err error) {
+	types := map[string]*compute.MachineType{}
+	err = computeService.Instances.AggregatedList(projectID).
+		Filter(`status = "RUNNING"`).
+		Pages(ctx, func(list *compute.InstanceAggregatedList) error {
+			for scope, scoped := range list.Items {
+				if !strings.HasPrefix(scope, "zones/"+region+"-") {
+					continue
+				}
+				for _, instance := range scoped.Instances {
+					zone, name := path.Base(instance.Zone), path.Base(instance.MachineType)
+					mt, ok := types[zone+"/"+name]
+					if !ok {
+						mt, err = cachedMachineType(ctx, computeService, zone, name)
+						if err != nil {
+							return err
+						}
+						types[zone+"/"+name] = mt
+					}
+					cpus += mt.GuestCpus
+					memoryMB += mt.MemoryMb
+				}
+			}
+			return nil
+		})
+	return cpus, memoryMB, err
+}
+
+func utilization(used, total int64) string {
+	if total == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%.0f%%", float64(used)/float64(total)*100)
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+	"time"
+
+	"google.golang.org/api/compute/v1"
+)
+
+// staleImageAge is how old a custom image or machine image can get before
+// it's flagged as stale.
+const staleImageAge = 365 * 24 * time.Hour
+
+// getInstanceGroups lists the zonal instance groups in each of the region's
+// zones and the region's regional instance groups, managed or not.
+func getInstanceGroups(ctx context.Context, region string) {
+	computeService, err := compute.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create compute service", "error", err)
+		return
+	}
+
+	count := 0
+	zones, err := regionZones(ctx, region)
+	if err != nil {
+		// Skip regions that don't exist or aren't enabled for this project
+		slog.Debug("Skipping region", "region", region, "error", err)
+		return
+	}
+	for _, zone := range zones {
+		groups, err := computeService.InstanceGroups.List(projectID, zone).Context(ctx).Do()
+		if err != nil {
+			// Skip zones that aren't enabled for this project
+			slog.Debug("Skipping zone", "zone", zone, "error", err)
+			continue
+		}
+		for _, group := range groups.Items {
+			writeResourceRaw("Instance Group", instanceGroupInfo(group, path.Base(group.Zone)), group)
+		}
+		count += len(groups.Items)
+	}
+
+	regionalGroups, err := computeService.RegionInstanceGroups.List(projectID, region).Context(ctx).Do()
+	if err != nil {
+		slog.Debug("Skipping regional instance groups", "region", region, "error", err)
+	} else {
+		for _, group := range regionalGroups.Items {
+			writeResourceRaw("Instance Group", instanceGroupInfo(group, region), group)
+		}
+		count += len(regionalGroups.Items)
+	}
+	scanProgress.found(count)
+}
+
+func instanceGroupInfo(group *compute.InstanceGroup, location string) string {
+	return fmt.Sprintf("Name: %s\nSize: %d\nLocation: %s\nNetwork: %s\nSubnet: %s\nCreated: %s",
+		group.Name, group.Size, location, group.Network, group.Subnetwork, group.CreationTimestamp)
+}
+
+// getSoleTenantNodes lists the region's sole-tenant node templates and the
+// node groups built from them in each of its zones.
+func getSoleTenantNodes(ctx context.Context, region string) {
+	computeService, err := compute.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create compute service", "error", err)
+		return
+	}
+
+	templates, err := computeService.NodeTemplates.List(projectID, region).Context(ctx).Do()
+	if err != nil {
+		// Skip regions that don't exist or aren't enabled for this project
+		slog.Debug("Skipping region", "region", region, "error", err)
+		return
+	}
+
+	count := 0
+	for _, template := range templates.Items {
+		binding := ""
+		if template.ServerBinding != nil {
+			binding = template.ServerBinding.Type
+		}
+		info := fmt.Sprintf("Name: %s\nRegion: %s\nNode Type: %s\nCPU Overcommit: %s\nServer Binding: %s\nStatus: %s",
+			template.Name, region, template.NodeType, template.CpuOvercommitType, binding, template.Status)
+		writeResourceRaw("Sole-Tenant Node Template", info, template)
+		count++
+	}
+
+	zones, err := regionZones(ctx, region)
+	if err != nil {
+		slog.Error("Failed to list zones", "region", region, "error", err)
+	}
+	for _, zone := range zones {
+		groups, err := computeService.NodeGroups.List(projectID, zone).Context(ctx).Do()
+		if err != nil {
+			slog.Debug("Skipping zone", "zone", zone, "error", err)
+			continue
+		}
+		for _, group := range groups.Items {
+			autoscaling, window, share := "OFF", "", ""
+			if a := group.AutoscalingPolicy; a != nil && a.Mode != "" {
+				autoscaling = fmt.Sprintf("%s (%d-%d nodes)", a.Mode, a.MinNodes, a.MaxNodes)
+			}
+			if group.MaintenanceWindow != nil {
+				window = group.MaintenanceWindow.StartTime
+			}
+			if group.ShareSettings != nil {
+				share = group.ShareSettings.ShareType
+			}
+			info := fmt.Sprintf("Name: %s\nZone: %s\nNode Template: %s\nNodes: %d\nStatus: %s\nMaintenance Policy: %s\nMaintenance Window: %s\nAutoscaling: %s\nShare Type: %s",
+				group.Name, path.Base(group.Zone), path.Base(group.NodeTemplate), group.Size, group.Status,
+				group.MaintenancePolicy, window, autoscaling, strings.ToLower(share))
+			writeResourceRaw("Sole-Tenant Node Group", info, group)
+			count++
+		}
+	}
+	scanProgress.found(count)
+}
+
+// getImages lists the project's custom images. Images older than a year,
+// or replaced in their family by a newer image without being deprecated,
+// are flagged as stale.
+func getImages(ctx context.Context) {
+	computeService, err := compute.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create compute service", "error", err)
+		return
+	}
+
+	images, err := computeService.Images.List(projectID).Context(ctx).Do()
+	if err != nil {
+		slog.Error("Failed to list images", "error", err)
+		return
+	}
+
+	newest := map[string]string{}
+	for _, image := range images.Items {
+		if image.Family != "" && image.CreationTimestamp > newest[image.Family] {
+			newest[image.Family] = image.CreationTimestamp
+		}
+	}
+
+	for _, image := range images.Items {
+		deprecation := "ACTIVE"
+		if image.Deprecated != nil && image.Deprecated.State != "" {
+			deprecation = image.Deprecated.State
+		}
+		superseded := image.Family != "" && image.CreationTimestamp < newest[image.Family] && deprecation == "ACTIVE"
+		info := fmt.Sprintf("Name: %s\nFamily: %s\nSource Disk: %s\nDisk Size: %d GB\nArchive Size Bytes: %d\nStorage Locations: %s\nDeprecation: %s\nStatus: %s\nCreated: %s\nStale: %t",
+			image.Name, image.Family, path.Base(image.SourceDisk), image.DiskSizeGb, image.ArchiveSizeBytes,
+			strings.Join(image.StorageLocations, ", "), deprecation, image.Status, image.CreationTimestamp,
+			superseded || olderThan(image.CreationTimestamp, staleImageAge))
+		writeResourceRaw("Image", info, image)
+	}
+	scanProgress.found(len(images.Items))
+}
+
+// getMachineImages lists the project's machine images, flagging those older
+// than a year as stale.
+func getMachineImages(ctx context.Context) {
+	computeService, err := compute.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create compute service", "error", err)
+		return
+	}
+
+	images, err := computeService.MachineImages.List(projectID).Context(ctx).Do()
+	if err != nil {
+		slog.Error("Failed to list machine images", "error", err)
+		return
+	}
+
+	for _, image := range images.Items {
+		info := fmt.Sprintf("Name: %s\nSource Instance: %s\nTotal Storage Bytes: %d\nStorage Locations: %s\nStatus: %s\nCreated: %s\nStale: %t",
+			image.Name, path.Base(image.SourceInstance), image.TotalStorageBytes,
+			strings.Join(image.StorageLocations, ", "), image.Status, image.CreationTimestamp,
+			olderThan(image.CreationTimestamp, staleImageAge))
+		writeResourceRaw("Machine Image", info, image)
+	}
+	scanProgress.found(len(images.Items))
+}
+
+// olderThan reports whether an RFC 3339 timestamp is further back than age.
+func olderThan(timestamp string, age time.Duration) bool {
+	t, err := time.Parse(time.RFC3339, timestamp)
+	return err == nil && time.Since(t) > age
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+
+	"google.golang.org/api/essentialcontacts/v1"
+)
+
+// contactCategories are the notification categories audits expect someone
+// to be subscribed to.
+var contactCategories = []string{"SECURITY", "BILLING", "TECHNICAL", "LEGAL", "SUSPENSION", "PRODUCT_UPDATES"}
+
+// getEssentialContacts lists the project's Essential Contacts and, per
+// notification category, who actually receives it once contacts inherited
+// from the folder and organization are included. Categories nobody receives
+// are flagged.
+func getEssentialContacts(ctx context.Context) {
+	ecService, err := essentialcontacts.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create Essential Contacts service", "error", err)
+		return
+	}
+
+	parent := "projects/" + projectID
+	contacts, err := ecService.Projects.Contacts.List(parent).Context(ctx).Do()
+	if err != nil {
+		// Skip projects that don't have the Essential Contacts API enabled
+		slog.Debug("Skipping Essential Contacts", "error", err)
+		return
+	}
+
+	count := 0
+	for _, contact := range contacts.Contacts {
+		info := fmt.Sprintf("Name: %s\nEmail: %s\nCategories: %s\nLanguage: %s\nValidation: %s",
+			path.Base(contact.Name), contact.Email, strings.Join(contact.NotificationCategorySubscriptions, ", "),
+			contact.LanguageTag, contact.ValidationState)
+		writeResourceRaw("Essential Contact", info, contact)
+		count++
+	}
+
+	for _, category := range contactCategories {
+		effective, err := ecService.Projects.Contacts.Compute(parent).NotificationCategories(category).Context(ctx).Do()
+		if err != nil {
+			slog.Error("Failed to compute Essential Contacts", "category", category, "error", err)
+			continue
+		}
+		var emails []string
+		for _, contact := range effective.Contacts {
+			emails = append(emails, contact.Email)
+		}
+		info := fmt.Sprintf("Name: %s\nContacts: %s\nMissing: %t", category, strings.Join(emails, ", "), len(emails) == 0)
+		writeResource("Notification Category", info)
+		count++
+		if len(emails) == 0 {
+			slog.Warn("No Essential Contact for notification category", "category", category)
+		}
+	}
+	scanProgress.found(count)
+}
@@ -0,0 +1,387 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"google.golang.org/api/cloudbilling/v1"
+	"google.golang.org/api/compute/v1"
+)
+
+// hoursPerMonth is the month length Google's pricing calculator uses.
+const hoursPerMonth = 730
+
+// Cloud Billing Catalog service IDs for the services that are costed.
+const (
+	computeEngineService    = "services/6F81-5844-456A"
+	cloudSQLService         = "services/9662-B51E-5089"
+	kubernetesEngineService = "services/CCD8-9BF1-090E"
+)
+
+// skuPrice is the on-demand list price of one Catalog SKU.
+type skuPrice struct {
+	description string
+	price       float64
+	unit        string // h, GiBy.h, GiBy.mo, ...
+}
+
+// costEstimator prices inventory rows from the Cloud Billing Catalog. The
+// estimates use on-demand list prices in USD with no committed use,
+// sustained use or negotiated discounts, so they are an upper bound.
+type costEstimator struct {
+	prices       map[string][]skuPrice // by region, "global" for global SKUs
+	compute      *compute.Service
+	machineTypes map[string]*compute.MachineType
+}
+
+type resourceCost struct {
+	row     *inventoryRow
+	service string
+	region  string
+	monthly float64
+}
+
+// runCostEstimate attaches an Estimated Monthly Cost field to every
+// instance, disk, Cloud SQL instance and GKE cluster it can price and writes
+// a COST ESTIMATE section with per-region and per-service totals.
+func runCostEstimate(ctx context.Context, e *costEstimator) {
+	var costs []resourceCost
+	for i := range inventory {
+		row := &inventory[i]
+		service, region, monthly, ok := e.estimate(ctx, *row)
+		if !ok {
+			continue
+		}
+		row.setField("Estimated Monthly Cost", fmt.Sprintf("%.2f USD", monthly))
+		costs = append(costs, resourceCost{row: row, service: service, region: region, monthly: monthly})
+	}
+	writeCostSection(costs)
+}
+
+func newCostEstimator(ctx context.Context) (*costEstimator, error) {
+	billingService, err := cloudbilling.NewService(ctx, clientOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("create Cloud Billing service: %w", err)
+	}
+	computeService, err := compute.NewService(ctx, clientOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("create compute service: %w", err)
+	}
+
+	e := &costEstimator{
+		prices:       map[string][]skuPrice{},
+		compute:      computeService,
+		machineTypes: map[string]*compute.MachineType{},
+	}
+	for _, service := range []string{computeEngineService, cloudSQLService, kubernetesEngineService} {
+		skus, err := cached("skus/"+path.Base(service), skuCacheTTL, func() ([]*cloudbilling.Sku, error) {
+			var skus []*cloudbilling.Sku
+			err := billingService.Services.Skus.List(service).CurrencyCode("USD").PageSize(5000).
+				Pages(ctx, func(resp *cloudbilling.ListSkusResponse) error {
+					skus = append(skus, resp.Skus...)
+					return nil
+				})
+			return skus, err
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list SKUs of %s: %w", service, err)
+		}
+		for _, sku := range skus {
+			e.addSKU(sku)
+		}
+	}
+	return e, nil
+}
+
+func (e *costEstimator) addSKU(sku *cloudbilling.Sku) {
+	if sku.Category == nil || sku.Category.UsageType != "OnDemand" || len(sku.PricingInfo) == 0 {
+		return
+	}
+	expr := sku.PricingInfo[0].PricingExpression
+	if expr == nil || len(expr.TieredRates) == 0 {
+		return
+	}
+	// Earlier tiers are free allowances; the last tier is the rate that
+	// applies to sustained usage.
+	rate := expr.TieredRates[len(expr.TieredRates)-1].UnitPrice
+	if rate == nil {
+		return
+	}
+	price := skuPrice{
+		description: sku.Description,
+		price:       float64(rate.Units) + float64(rate.Nanos)/1e9,
+		unit:        expr.UsageUnit,
+	}
+	for _, region := range sku.ServiceRegions {
+		e.prices[region] = append(e.prices[region], price)
+	}
+}
+
+// find returns the first SKU in the region, or failing that a global SKU,
+// whose description matches.
+func (e *costEstimator) find(region string, match func(description string) bool) (skuPrice, bool) {
+	for _, r := range []string{region, "global"} {
+		for _, p := range e.prices[r] {
+			if match(p.description) {
+				return p, true
+			}
+		}
+	}
+	return skuPrice{}, false
+}
+
+// monthly converts a quantity of an hourly or monthly SKU into a monthly
+// cost.
+func (p skuPrice) monthly(quantity float64) float64 {
+	if strings.HasSuffix(p.unit, "mo") {
+		return p.price * quantity
+	}
+	return p.price * quantity * hoursPerMonth
+}
+
+// estimate prices a row, returning the service and region it is billed to.
+func (e *costEstimator) estimate(ctx context.Context, row inventoryRow) (service, region string, monthly float64, ok bool) {
+	switch row.ResourceType {
+	case "Compute Instance":
+		// GKE nodes are costed with their cluster's node pools.
+		if strings.HasPrefix(row.Name, "gke-") || row.field("Status") == "TERMINATED" {
+			return "", "", 0, false
+		}
+		zone := row.field("Zone")
+		monthly, ok = e.instance(ctx, zone, path.Base(row.field("Machine Type")))
+		return "Compute Engine", regionOf(zone), monthly, ok
+
+	case "Persistent Disk":
+		zone := row.field("Zone")
+		size, _ := strconv.ParseFloat(strings.TrimSuffix(row.field("Size"), " GB"), 64)
+		monthly, ok = e.disk(regionOf(zone), path.Base(row.field("Type")), size)
+		return "Compute Engine", regionOf(zone), monthly, ok
+
+	case "Cloud SQL Instance":
+		monthly, ok = e.sqlInstance(row)
+		return "Cloud SQL", row.field("Region"), monthly, ok
+
+	case "GKE Cluster":
+		monthly, ok = e.gkeCluster(ctx, row)
+		return "Kubernetes Engine", regionOf(row.field("Location")), monthly, ok
+	}
+	return "", "", 0, false
+}
+
+// instance prices a VM from its vCPU and memory SKUs. Shared-core machine
+// types are priced as full cores, so their estimate is high.
+func (e *costEstimator) instance(ctx context.Context, zone, machineType string) (float64, bool) {
+	mt, err := e.machineType(ctx, zone, machineType)
+	if err != nil {
+		slog.Debug("Skipping cost of machine type", "machine_type", machineType, "zone", zone, "error", err)
+		return 0, false
+	}
+
+	family := strings.ToUpper(strings.SplitN(machineType, "-", 2)[0]) + " "
+	onDemand := func(kind string) func(string) bool {
+		return func(d string) bool {
+			return strings.HasPrefix(d, family) && strings.Contains(d, kind) &&
+				!strings.Contains(d, "Preemptible") && !strings.Contains(d, "Spot") &&
+				!strings.Contains(d, "Custom") && !strings.Contains(d, "Sole Tenancy") &&
+				!strings.Contains(d, "Commitment")
+		}
+	}
+	region := regionOf(zone)
+	core, ok := e.find(region, onDemand("Instance Core"))
+	if !ok {
+		return 0, false
+	}
+	ram, ok := e.find(region, onDemand("Instance Ram"))
+	if !ok {
+		return 0, false
+	}
+	return core.monthly(float64(mt.GuestCpus)) + ram.monthly(float64(mt.MemoryMb)/1024), true
+}
+
+func (e *costEstimator) machineType(ctx context.Context, zone, name string) (*compute.MachineType, error) {
+	key := zone + "/" + name
+	if mt, ok := e.machineTypes[key]; ok {
+		return mt, nil
+	}
+	mt, err := cachedMachineType(ctx, e.compute, zone, name)
+	if err != nil {
+		return nil, err
+	}
+	e.machineTypes[key] = mt
+	return mt, nil
+}
+
+// diskSKUs maps persistent disk types to their capacity SKU descriptions.
+var diskSKUs = map[string]string{
+	"pd-standard": "Storage PD Capacity",
+	"pd-balanced": "Balanced PD Capacity",
+	"pd-ssd":      "SSD backed PD Capacity",
+	"pd-extreme":  "Extreme PD Capacity",
+}
+
+func (e *costEstimator) disk(region, diskType string, sizeGB float64) (float64, bool) {
+	prefix, ok := diskSKUs[diskType]
+	if !ok {
+		return 0, false
+	}
+	p, ok := e.find(region, func(d string) bool { return strings.HasPrefix(d, prefix) })
+	if !ok {
+		return 0, false
+	}
+	return p.monthly(sizeGB), true
+}
+
+var sqlCustomTier = regexp.MustCompile(`^db-custom-(\d+)-(\d+)$`)
+
+// sqlInstance prices a Cloud SQL instance from its vCPU, memory and storage
+// SKUs. Shared-core tiers and SQL Server licenses aren't priced.
+func (e *costEstimator) sqlInstance(row inventoryRow) (float64, bool) {
+	var cpus, memGB float64
+	tier := row.field("Tier")
+	if m := sqlCustomTier.FindStringSubmatch(tier); m != nil {
+		cpus, _ = strconv.ParseFloat(m[1], 64)
+		memMB, _ := strconv.ParseFloat(m[2], 64)
+		memGB = memMB / 1024
+	} else if n, ok := strings.CutPrefix(tier, "db-n1-standard-"); ok {
+		cpus, _ = strconv.ParseFloat(n, 64)
+		memGB = cpus * 3.75
+	} else if n, ok := strings.CutPrefix(tier, "db-n1-highmem-"); ok {
+		cpus, _ = strconv.ParseFloat(n, 64)
+		memGB = cpus * 6.5
+	}
+	if cpus == 0 {
+		return 0, false
+	}
+
+	engine := ""
+	switch version := row.field("Database Version"); {
+	case strings.HasPrefix(version, "MYSQL"):
+		engine = "MySQL"
+	case strings.HasPrefix(version, "POSTGRES"):
+		engine = "PostgreSQL"
+	case strings.HasPrefix(version, "SQLSERVER"):
+		engine = "SQL Server"
+	default:
+		return 0, false
+	}
+	availability := "Zonal"
+	if row.field("Availability") == "REGIONAL" {
+		availability = "Regional"
+	}
+	prefix := fmt.Sprintf("Cloud SQL for %s: %s - ", engine, availability)
+	sku := func(kind string) (skuPrice, bool) {
+		return e.find(row.field("Region"), func(d string) bool {
+			return strings.HasPrefix(d, prefix+kind)
+		})
+	}
+
+	vcpu, ok := sku("vCPU")
+	if !ok {
+		return 0, false
+	}
+	ram, ok := sku("RAM")
+	if !ok {
+		return 0, false
+	}
+	total := vcpu.monthly(cpus) + ram.monthly(memGB)
+
+	storageKind := "Standard storage"
+	if row.field("Disk Type") == "PD_HDD" {
+		storageKind = "Low cost storage"
+	}
+	if storage, ok := sku(storageKind); ok {
+		size, _ := strconv.ParseFloat(strings.TrimSuffix(row.field("Disk Size"), " GB"), 64)
+		total += storage.monthly(size)
+	}
+	return total, true
+}
+
+var nodePoolSummary = regexp.MustCompile(`^(.*) \((\S+) x (\d+)\)$`)
+
+// gkeCluster prices a cluster's management fee and the VMs of its node
+// pools at their initial size; autoscaling isn't taken into account.
+func (e *costEstimator) gkeCluster(ctx context.Context, row inventoryRow) (float64, bool) {
+	location := row.field("Location")
+	region := regionOf(location)
+	zone := location
+	if zone == region {
+		zone = region + "-a"
+	}
+
+	total := 0.0
+	priced := false
+	feeSKU := "Zonal Kubernetes Clusters"
+	if zone != location {
+		feeSKU = "Regional Kubernetes Clusters"
+	}
+	if fee, ok := e.find(region, func(d string) bool { return strings.HasPrefix(d, feeSKU) }); ok {
+		total += fee.monthly(1)
+		priced = true
+	}
+
+	for _, pool := range splitList(row.field("Node Pools")) {
+		m := nodePoolSummary.FindStringSubmatch(pool)
+		if m == nil {
+			continue
+		}
+		nodes, _ := strconv.Atoi(m[3])
+		if perNode, ok := e.instance(ctx, zone, m[2]); ok {
+			total += perNode * float64(nodes)
+			priced = true
+		}
+	}
+	return total, priced
+}
+
+// regionOf returns the region of a zone such as us-central1-a; regions are
+// returned unchanged.
+func regionOf(location string) string {
+	if strings.Count(location, "-") == 2 {
+		return location[:strings.LastIndex(location, "-")]
+	}
+	return location
+}
+
+func writeCostSection(costs []resourceCost) {
+	writeSection("COST ESTIMATE")
+	fmt.Fprintln(report, "\nEstimated monthly cost at on-demand list prices (USD), before discounts.")
+
+	byRegion := map[string]float64{}
+	byService := map[string]float64{}
+	total := 0.0
+	for _, c := range costs {
+		fmt.Fprintf(report, "\n%-24s %-40s %-16s %10.2f", c.row.ResourceType, c.row.Name, c.region, c.monthly)
+		byRegion[c.region] += c.monthly
+		byService[c.service] += c.monthly
+		total += c.monthly
+	}
+
+	writeRollup := func(title string, totals map[string]float64) {
+		fmt.Fprintf(report, "\n\n%s:", title)
+		keys := make([]string, 0, len(totals))
+		for k := range totals {
+			keys = append(keys, k)
+		}
+		sort.Slice(keys, func(i, j int) bool { return totals[keys[i]] > totals[keys[j]] })
+		for _, k := range keys {
+			fmt.Fprintf(report, "\n  %-30s %10.2f", k, totals[k])
+		}
+	}
+	writeRollup("By region", byRegion)
+	writeRollup("By service", byService)
+	fmt.Fprintf(report, "\n\nTotal: %.2f USD/month\n", total)
+}
+
+// cachedMachineType gets a machine type through the on-disk cache. Machine
+// types are the same in every project, so the key is just zone and name.
+func cachedMachineType(ctx context.Context, computeService *compute.Service, zone, name string) (*compute.MachineType, error) {
+	return cached("machine-types/"+zone+"/"+name, machineTypeCacheTTL, func() (*compute.MachineType, error) {
+		return computeService.MachineTypes.Get(projectID, zone, name).Context(ctx).Do()
+	})
+}
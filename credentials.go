@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"google.golang.org/api/impersonate"
+	"google.golang.org/api/option"
+)
+
+const cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+
+// configureCredentials sets up clientOptions, which every API client in the
+// tool is created with. With --impersonate-service-account the caller's
+// credentials are only used to mint short-lived tokens for the target
+// service account, so no key file has to be handed out.
+func configureCredentials(ctx context.Context) error {
+	if credsFile := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"); credsFile != "" {
+		if err := validateCredentialsFile(credsFile); err != nil {
+			return fmt.Errorf("%s: %w", credsFile, err)
+		}
+	}
+
+	if impersonateServiceAccount == "" {
+		return nil
+	}
+
+	ts, err := impersonate.CredentialsTokenSource(ctx, impersonate.CredentialsConfig{
+		TargetPrincipal: impersonateServiceAccount,
+		Scopes:          []string{cloudPlatformScope},
+	})
+	if err != nil {
+		return fmt.Errorf("impersonate %s: %w", impersonateServiceAccount, err)
+	}
+
+	clientOptions = append(clientOptions, option.WithTokenSource(ts))
+	slog.Info("Impersonating service account", "service_account", impersonateServiceAccount)
+	return nil
+}
+
+// credentialsFile holds the fields of a credentials JSON file that are
+// checked at startup. Only external_account (Workload Identity Federation)
+// configs are validated in depth; other types are handed to the client
+// libraries as is.
+type credentialsFile struct {
+	Type                           string `json:"type"`
+	Audience                       string `json:"audience"`
+	SubjectTokenType               string `json:"subject_token_type"`
+	TokenURL                       string `json:"token_url"`
+	ServiceAccountImpersonationURL string `json:"service_account_impersonation_url"`
+	CredentialSource               *struct {
+		EnvironmentID string `json:"environment_id"`
+		File          string `json:"file"`
+		URL           string `json:"url"`
+		Executable    *struct {
+			Command string `json:"command"`
+		} `json:"executable"`
+	} `json:"credential_source"`
+}
+
+// validateCredentialsFile catches malformed credential configs before the
+// scan starts, rather than as an authentication error from every collector.
+// It is mostly aimed at external account configs generated for GitHub
+// Actions, EKS and other OIDC/AWS federation setups.
+func validateCredentialsFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	var creds credentialsFile
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return fmt.Errorf("parse credentials: %w", err)
+	}
+
+	switch creds.Type {
+	case "service_account", "authorized_user", "impersonated_service_account", "external_account_authorized_user":
+		slog.Debug("Using credentials file", "type", creds.Type)
+		return nil
+	case "external_account":
+		// Validated below.
+	case "":
+		return errors.New("credentials file has no \"type\" field")
+	default:
+		return fmt.Errorf("unsupported credentials type %q", creds.Type)
+	}
+
+	var missing []string
+	if creds.Audience == "" {
+		missing = append(missing, "audience")
+	}
+	if creds.SubjectTokenType == "" {
+		missing = append(missing, "subject_token_type")
+	}
+	if creds.TokenURL == "" {
+		missing = append(missing, "token_url")
+	}
+	if creds.CredentialSource == nil {
+		missing = append(missing, "credential_source")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("external account credentials missing %s", strings.Join(missing, ", "))
+	}
+
+	source := creds.CredentialSource
+	var kind string
+	switch {
+	case strings.HasPrefix(source.EnvironmentID, "aws"):
+		kind = "aws"
+	case source.Executable != nil:
+		kind = "executable"
+		if source.Executable.Command == "" {
+			return errors.New("external account executable credential source has no command")
+		}
+		if os.Getenv("GOOGLE_EXTERNAL_ACCOUNT_ALLOW_EXECUTABLES") != "1" {
+			return errors.New("executable credential sources require GOOGLE_EXTERNAL_ACCOUNT_ALLOW_EXECUTABLES=1")
+		}
+	case source.File != "":
+		kind = "file"
+		if _, err := os.Stat(source.File); err != nil {
+			return fmt.Errorf("external account subject token file: %w", err)
+		}
+	case source.URL != "":
+		kind = "url"
+	default:
+		return errors.New("external account credential_source has no environment_id, file, url or executable")
+	}
+
+	slog.Info("Using Workload Identity Federation credentials",
+		"audience", creds.Audience,
+		"source", kind,
+		"impersonation", creds.ServiceAccountImpersonationURL != "")
+	return nil
+}
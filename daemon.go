@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+// runDaemon runs scan immediately and then every scanInterval until ctx is
+// cancelled. A scan in progress when ctx is cancelled still writes its
+// report, marked incomplete, and no further scans start. A failed scan is
+// logged and retried at the next interval rather than stopping the process,
+// and a tick that finds another scan running is skipped.
+func runDaemon(ctx context.Context, scan func(ctx context.Context) error) {
+	slog.Info("Starting daemon", "project", projectID, "interval", scanInterval)
+
+	ticker := time.NewTicker(scanInterval)
+	defer ticker.Stop()
+
+	for {
+		start := time.Now()
+		if err := scan(ctx); errors.Is(err, errScanRunning) {
+			slog.Info("Skipping scan, one is already running")
+		} else if err != nil {
+			slog.Error("Scan failed", "error", err)
+		} else {
+			slog.Info("Scan complete", "duration", time.Since(start).Round(time.Second), "resources", len(withoutScanMetadata(inventory)))
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		slog.Info("Next scan scheduled", "at", start.Add(scanInterval).Format(time.RFC3339))
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+
+	"google.golang.org/api/datacatalog/v1"
+	"google.golang.org/api/dataplex/v1"
+)
+
+// getDataplex lists the region's Dataplex lakes with their zones and the
+// buckets and datasets attached to them as assets.
+func getDataplex(ctx context.Context, region string) {
+	dataplexService, err := dataplex.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create Dataplex service", "error", err)
+		return
+	}
+
+	parent := fmt.Sprintf("projects/%s/locations/%s", projectID, region)
+	lakes, err := dataplexService.Projects.Locations.Lakes.List(parent).Context(ctx).Do()
+	if err != nil {
+		// Skip regions without Dataplex and projects without the API enabled
+		slog.Debug("Skipping Dataplex", "region", region, "error", err)
+		return
+	}
+
+	count := 0
+	for _, lake := range lakes.Lakes {
+		metastore := ""
+		if lake.Metastore != nil {
+			metastore = path.Base(lake.Metastore.Service)
+		}
+		info := fmt.Sprintf("Name: %s\nDisplay Name: %s\nState: %s\nRegion: %s\nMetastore: %s\nCreated: %s",
+			path.Base(lake.Name), lake.DisplayName, lake.State, region, metastore, lake.CreateTime)
+		writeResourceRaw("Dataplex Lake", info, lake)
+		count++
+
+		zones, err := dataplexService.Projects.Locations.Lakes.Zones.List(lake.Name).Context(ctx).Do()
+		if err != nil {
+			slog.Error("Failed to list Dataplex zones", "lake", lake.Name, "error", err)
+			continue
+		}
+		for _, zone := range zones.Zones {
+			locationType := ""
+			if zone.ResourceSpec != nil {
+				locationType = zone.ResourceSpec.LocationType
+			}
+			info := fmt.Sprintf("Name: %s\nLake: %s\nType: %s\nLocation Type: %s\nState: %s",
+				path.Base(zone.Name), path.Base(lake.Name), zone.Type, locationType, zone.State)
+			writeResourceRaw("Dataplex Zone", info, zone)
+			count++
+
+			assets, err := dataplexService.Projects.Locations.Lakes.Zones.Assets.List(zone.Name).Context(ctx).Do()
+			if err != nil {
+				slog.Error("Failed to list Dataplex assets", "zone", zone.Name, "error", err)
+				continue
+			}
+			for _, asset := range assets.Assets {
+				resourceType, resource := "", ""
+				if asset.ResourceSpec != nil {
+					resourceType, resource = asset.ResourceSpec.Type, asset.ResourceSpec.Name
+				}
+				info := fmt.Sprintf("Name: %s\nLake: %s\nZone: %s\nResource Type: %s\nResource: %s\nState: %s",
+					path.Base(asset.Name), path.Base(lake.Name), path.Base(zone.Name), resourceType, resource, asset.State)
+				writeResourceRaw("Dataplex Asset", info, asset)
+				count++
+			}
+		}
+	}
+	scanProgress.found(count)
+}
+
+// getPolicyTagTaxonomies lists the region's Data Catalog taxonomies and the
+// policy tags that control column-level access in BigQuery.
+func getPolicyTagTaxonomies(ctx context.Context, region string) {
+	catalogService, err := datacatalog.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create Data Catalog service", "error", err)
+		return
+	}
+
+	parent := fmt.Sprintf("projects/%s/locations/%s", projectID, region)
+	taxonomies, err := catalogService.Projects.Locations.Taxonomies.List(parent).Context(ctx).Do()
+	if err != nil {
+		// Skip projects that don't have the Data Catalog API enabled
+		slog.Debug("Skipping Data Catalog taxonomies", "region", region, "error", err)
+		return
+	}
+
+	count := 0
+	for _, taxonomy := range taxonomies.Taxonomies {
+		info := fmt.Sprintf("Name: %s\nDisplay Name: %s\nRegion: %s\nPolicy Tags: %d\nActivated Policy Types: %s",
+			path.Base(taxonomy.Name), taxonomy.DisplayName, region, taxonomy.PolicyTagCount,
+			strings.Join(taxonomy.ActivatedPolicyTypes, ", "))
+		writeResourceRaw("Data Catalog Taxonomy", info, taxonomy)
+		count++
+
+		tags, err := catalogService.Projects.Locations.Taxonomies.PolicyTags.List(taxonomy.Name).Context(ctx).Do()
+		if err != nil {
+			slog.Error("Failed to list policy tags", "taxonomy", taxonomy.Name, "error", err)
+			continue
+		}
+		names := make(map[string]string, len(tags.PolicyTags))
+		for _, tag := range tags.PolicyTags {
+			names[tag.Name] = tag.DisplayName
+		}
+		for _, tag := range tags.PolicyTags {
+			info := fmt.Sprintf("Name: %s\nTaxonomy: %s\nParent: %s\nDescription: %s",
+				tag.DisplayName, taxonomy.DisplayName, names[tag.ParentPolicyTag], oneLine(tag.Description))
+			writeResourceRaw("Policy Tag", info, tag)
+			count++
+		}
+	}
+	scanProgress.found(count)
+}
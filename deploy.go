@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+
+	"google.golang.org/api/clouddeploy/v1"
+	"google.golang.org/api/deploymentmanager/v2"
+)
+
+// getCloudDeploy lists the region's Cloud Deploy delivery pipelines with
+// their releases, and the targets they deploy to.
+func getCloudDeploy(ctx context.Context, region string) {
+	deployService, err := clouddeploy.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create Cloud Deploy service", "error", err)
+		return
+	}
+
+	parent := fmt.Sprintf("projects/%s/locations/%s", projectID, region)
+	pipelines, err := deployService.Projects.Locations.DeliveryPipelines.List(parent).Context(ctx).Do()
+	if err != nil {
+		// Skip regions without Cloud Deploy and projects without the API enabled
+		slog.Debug("Skipping Cloud Deploy", "region", region, "error", err)
+		return
+	}
+
+	count := 0
+	for _, pipeline := range pipelines.DeliveryPipelines {
+		var stages []string
+		if pipeline.SerialPipeline != nil {
+			for _, stage := range pipeline.SerialPipeline.Stages {
+				stages = append(stages, stage.TargetId)
+			}
+		}
+		info := fmt.Sprintf("Name: %s\nRegion: %s\nStages: %s\nSuspended: %t\nCreated: %s",
+			path.Base(pipeline.Name), region, strings.Join(stages, " -> "), pipeline.Suspended, pipeline.CreateTime)
+		writeResourceRaw("Cloud Deploy Pipeline", info, pipeline)
+		count++
+
+		releases, err := deployService.Projects.Locations.DeliveryPipelines.Releases.List(pipeline.Name).Context(ctx).Do()
+		if err != nil {
+			slog.Error("Failed to list Cloud Deploy releases", "pipeline", pipeline.Name, "error", err)
+			continue
+		}
+		for _, release := range releases.Releases {
+			info := fmt.Sprintf("Name: %s\nPipeline: %s\nRender State: %s\nAbandoned: %t\nCreated: %s",
+				path.Base(release.Name), path.Base(pipeline.Name), release.RenderState, release.Abandoned, release.CreateTime)
+			writeResourceRaw("Cloud Deploy Release", info, release)
+			count++
+		}
+	}
+
+	targets, err := deployService.Projects.Locations.Targets.List(parent).Context(ctx).Do()
+	if err != nil {
+		slog.Error("Failed to list Cloud Deploy targets", "region", region, "error", err)
+		scanProgress.found(count)
+		return
+	}
+	for _, target := range targets.Targets {
+		kind, destination := "", ""
+		switch {
+		case target.Gke != nil:
+			kind, destination = "GKE", target.Gke.Cluster
+		case target.Run != nil:
+			kind, destination = "Cloud Run", target.Run.Location
+		case target.AnthosCluster != nil:
+			kind, destination = "Anthos", target.AnthosCluster.Membership
+		case target.MultiTarget != nil:
+			kind, destination = "Multi-target", strings.Join(target.MultiTarget.TargetIds, ", ")
+		}
+		info := fmt.Sprintf("Name: %s\nRegion: %s\nType: %s\nDestination: %s\nRequires Approval: %t",
+			path.Base(target.Name), region, kind, destination, target.RequireApproval)
+		writeResourceRaw("Cloud Deploy Target", info, target)
+		count++
+	}
+	scanProgress.found(count)
+}
+
+// getDeploymentManager lists legacy Deployment Manager deployments.
+func getDeploymentManager(ctx context.Context) {
+	dmService, err := deploymentmanager.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create Deployment Manager service", "error", err)
+		return
+	}
+
+	deployments, err := dmService.Deployments.List(projectID).Context(ctx).Do()
+	if err != nil {
+		// Skip projects that don't have the Deployment Manager API enabled
+		slog.Debug("Skipping Deployment Manager", "error", err)
+		return
+	}
+
+	for _, deployment := range deployments.Deployments {
+		status, operation := "", ""
+		if deployment.Operation != nil {
+			status, operation = deployment.Operation.Status, deployment.Operation.OperationType
+		}
+		info := fmt.Sprintf("Name: %s\nDescription: %s\nLast Operation: %s\nStatus: %s\nCreated: %s\nUpdated: %s",
+			deployment.Name, oneLine(deployment.Description), operation, status, deployment.InsertTime, deployment.UpdateTime)
+		writeResourceRaw("Deployment Manager Deployment", info, deployment)
+	}
+	scanProgress.found(len(deployments.Deployments))
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+
+	dialogflow "google.golang.org/api/dialogflow/v2"
+	dialogflowcx "google.golang.org/api/dialogflow/v3"
+	"google.golang.org/api/option"
+)
+
+// getDialogflow lists the project's Dialogflow CX agents with their
+// webhooks and environments, and its Dialogflow ES agent with its
+// fulfillment webhook and environments. Agents can be global or regional,
+// and regional agents are only served by their region's endpoint, so each
+// location gets its own clients.
+func getDialogflow(ctx context.Context) {
+	count := 0
+	for _, location := range append([]string{"global"}, regions...) {
+		endpoint := "https://dialogflow.googleapis.com/"
+		if location != "global" {
+			endpoint = fmt.Sprintf("https://%s-dialogflow.googleapis.com/", location)
+		}
+		opts := append(append([]option.ClientOption{}, clientOptions...), option.WithEndpoint(endpoint))
+
+		cxService, err := dialogflowcx.NewService(ctx, opts...)
+		if err != nil {
+			slog.Error("Failed to create Dialogflow CX service", "error", err)
+			return
+		}
+		n, err := getDialogflowCX(ctx, cxService, location)
+		count += n
+		if err != nil {
+			// Skip projects that don't have the Dialogflow API enabled,
+			// without trying every region when even global fails
+			slog.Debug("Skipping Dialogflow", "location", location, "error", err)
+			if location == "global" {
+				break
+			}
+			continue
+		}
+
+		esService, err := dialogflow.NewService(ctx, opts...)
+		if err != nil {
+			slog.Error("Failed to create Dialogflow service", "error", err)
+			return
+		}
+		count += getDialogflowES(ctx, esService, location)
+	}
+	scanProgress.found(count)
+}
+
+// getDialogflowCX writes the location's CX agents, their webhooks and
+// environments, and returns how many resources it wrote. The error is from
+// listing the agents themselves.
+func getDialogflowCX(ctx context.Context, cxService *dialogflowcx.Service, location string) (int, error) {
+	var agents []*dialogflowcx.GoogleCloudDialogflowCxV3Agent
+	parent := fmt.Sprintf("projects/%s/locations/%s", projectID, location)
+	err := cxService.Projects.Locations.Agents.List(parent).Pages(ctx, func(page *dialogflowcx.GoogleCloudDialogflowCxV3ListAgentsResponse) error {
+		agents = append(agents, page.Agents...)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, agent := range agents {
+		info := fmt.Sprintf("Name: %s\nAgent ID: %s\nLocation: %s\nEdition: CX\nLanguage: %s\nTime Zone: %s\nLogging: %t\nSecurity Settings: %s",
+			agent.DisplayName, path.Base(agent.Name), location, agent.DefaultLanguageCode, agent.TimeZone,
+			agent.EnableStackdriverLogging, path.Base(agent.SecuritySettings))
+		writeResourceRaw("Dialogflow Agent", info, agent)
+		count++
+
+		err := cxService.Projects.Locations.Agents.Webhooks.List(agent.Name).Pages(ctx, func(page *dialogflowcx.GoogleCloudDialogflowCxV3ListWebhooksResponse) error {
+			for _, webhook := range page.Webhooks {
+				uri := ""
+				switch {
+				case webhook.GenericWebService != nil:
+					uri = webhook.GenericWebService.Uri
+				case webhook.ServiceDirectory != nil:
+					uri = webhook.ServiceDirectory.Service
+				}
+				info := fmt.Sprintf("Name: %s\nAgent: %s\nLocation: %s\nURI: %s\nDisabled: %t",
+					webhook.DisplayName, agent.DisplayName, location, uri, webhook.Disabled)
+				writeResourceRaw("Dialogflow Webhook", info, webhook)
+				count++
+			}
+			return nil
+		})
+		if err != nil {
+			slog.Error("Failed to list Dialogflow webhooks", "agent", agent.Name, "error", err)
+		}
+
+		err = cxService.Projects.Locations.Agents.Environments.List(agent.Name).Pages(ctx, func(page *dialogflowcx.GoogleCloudDialogflowCxV3ListEnvironmentsResponse) error {
+			for _, env := range page.Environments {
+				var versions []string
+				for _, v := range env.VersionConfigs {
+					// Versions are named .../flows/<flow>/versions/<version>.
+					parts := strings.Split(v.Version, "/")
+					if n := len(parts); n >= 3 {
+						versions = append(versions, parts[n-3]+" v"+parts[n-1])
+					}
+				}
+				info := fmt.Sprintf("Name: %s\nAgent: %s\nLocation: %s\nFlow Versions: %s\nUpdated: %s",
+					env.DisplayName, agent.DisplayName, location, strings.Join(versions, ", "), env.UpdateTime)
+				writeResourceRaw("Dialogflow Environment", info, env)
+				count++
+			}
+			return nil
+		})
+		if err != nil {
+			slog.Error("Failed to list Dialogflow environments", "agent", agent.Name, "error", err)
+		}
+	}
+	return count, nil
+}
+
+// getDialogflowES writes the location's ES agent, if there is one, with
+// its fulfillment webhook and environments, and returns how many resources
+// it wrote.
+func getDialogflowES(ctx context.Context, esService *dialogflow.Service, location string) int {
+	parent := fmt.Sprintf("projects/%s/locations/%s", projectID, location)
+	agent, err := esService.Projects.Locations.GetAgent(parent).Context(ctx).Do()
+	if err != nil {
+		// A project has at most one ES agent per location, and most have none
+		slog.Debug("Skipping Dialogflow ES", "location", location, "error", err)
+		return 0
+	}
+
+	webhook := ""
+	fulfillment, err := esService.Projects.Locations.Agent.GetFulfillment(parent + "/agent/fulfillment").Context(ctx).Do()
+	if err != nil {
+		slog.Error("Failed to get Dialogflow fulfillment", "location", location, "error", err)
+	} else if fulfillment.Enabled && fulfillment.GenericWebService != nil {
+		webhook = fulfillment.GenericWebService.Uri
+	}
+	info := fmt.Sprintf("Name: %s\nLocation: %s\nEdition: ES %s\nLanguage: %s\nTime Zone: %s\nWebhook: %s",
+		agent.DisplayName, location, agent.Tier, agent.DefaultLanguageCode, agent.TimeZone, webhook)
+	writeResourceRaw("Dialogflow Agent", info, agent)
+	count := 1
+
+	err = esService.Projects.Locations.Agent.Environments.List(parent+"/agent").Pages(ctx, func(page *dialogflow.GoogleCloudDialogflowV2ListEnvironmentsResponse) error {
+		for _, env := range page.Environments {
+			info := fmt.Sprintf("Name: %s\nAgent: %s\nLocation: %s\nAgent Version: %s\nState: %s\nUpdated: %s",
+				path.Base(env.Name), agent.DisplayName, location, path.Base(env.AgentVersion), env.State, env.UpdateTime)
+			writeResourceRaw("Dialogflow Environment", info, env)
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Error("Failed to list Dialogflow ES environments", "location", location, "error", err)
+	}
+	return count
+}
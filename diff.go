@@ -0,0 +1,91 @@
+package main
+
+// inventoryDiff lists what changed between two scans. Resources are matched
+// by their normalized ID.
+type inventoryDiff struct {
+	Added   []inventoryRow   `json:"added"`
+	Removed []inventoryRow   `json:"removed"`
+	Changed []resourceChange `json:"changed"`
+}
+
+type resourceChange struct {
+	ID           string        `json:"id"`
+	Zone         string        `json:"zone,omitempty"`
+	ResourceType string        `json:"resource_type"`
+	Section      string        `json:"section"`
+	Name         string        `json:"name"`
+	Fields       []fieldChange `json:"fields"`
+}
+
+type fieldChange struct {
+	Key    string `json:"key"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+}
+
+// key returns the row's ID, working it out for rows saved before scans
+// recorded one.
+func (r inventoryRow) key() string {
+	if r.ID == "" {
+		r.normalize()
+	}
+	return r.ID
+}
+
+func diffInventories(before, after []inventoryRow) inventoryDiff {
+	before, after = withoutScanMetadata(before), withoutScanMetadata(after)
+	diff := inventoryDiff{
+		Added:   []inventoryRow{},
+		Removed: []inventoryRow{},
+		Changed: []resourceChange{},
+	}
+
+	old := make(map[string]inventoryRow, len(before))
+	for _, row := range before {
+		old[row.key()] = row
+	}
+
+	seen := make(map[string]bool, len(after))
+	for _, row := range after {
+		seen[row.key()] = true
+		prev, ok := old[row.key()]
+		if !ok {
+			diff.Added = append(diff.Added, row)
+			continue
+		}
+		if fields := diffFields(prev, row); len(fields) > 0 {
+			diff.Changed = append(diff.Changed, resourceChange{
+				ID:           row.key(),
+				Zone:         row.Zone,
+				ResourceType: row.ResourceType,
+				Section:      row.Section,
+				Name:         row.Name,
+				Fields:       fields,
+			})
+		}
+	}
+
+	for _, row := range before {
+		if !seen[row.key()] {
+			diff.Removed = append(diff.Removed, row)
+		}
+	}
+	return diff
+}
+
+func diffFields(before, after inventoryRow) []fieldChange {
+	var changes []fieldChange
+	keys := map[string]bool{}
+	for _, f := range after.Fields {
+		keys[f.Key] = true
+		if old := before.field(f.Key); old != f.Value {
+			changes = append(changes, fieldChange{Key: f.Key, Before: old, After: f.Value})
+		}
+	}
+	for _, f := range before.Fields {
+		if !keys[f.Key] {
+			changes = append(changes, fieldChange{Key: f.Key, Before: f.Value})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+
+	"google.golang.org/api/dlp/v2"
+)
+
+// getDLP lists the project's Sensitive Data Protection (Cloud DLP)
+// inspection and de-identification templates, job triggers and stored
+// infoTypes. They can be created in the global location as well as in any
+// region, so global is checked along with every scanned region.
+func getDLP(ctx context.Context) {
+	dlpService, err := dlp.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create DLP service", "error", err)
+		return
+	}
+
+	count := 0
+	locations := dlpService.Projects.Locations
+	for _, location := range append([]string{"global"}, regions...) {
+		parent := fmt.Sprintf("projects/%s/locations/%s", projectID, location)
+		err := locations.InspectTemplates.List(parent).Pages(ctx, func(page *dlp.GooglePrivacyDlpV2ListInspectTemplatesResponse) error {
+			for _, t := range page.InspectTemplates {
+				var infoTypes []string
+				if t.InspectConfig != nil {
+					for _, it := range t.InspectConfig.InfoTypes {
+						infoTypes = append(infoTypes, it.Name)
+					}
+				}
+				info := fmt.Sprintf("Name: %s\nDisplay Name: %s\nLocation: %s\nInfo Types: %s\nUpdated: %s",
+					path.Base(t.Name), t.DisplayName, location, strings.Join(infoTypes, ", "), t.UpdateTime)
+				writeResourceRaw("DLP Inspect Template", info, t)
+				count++
+			}
+			return nil
+		})
+		if err != nil {
+			// Skip projects that don't have the DLP API enabled, without
+			// trying every region when even the global location fails
+			slog.Debug("Skipping DLP", "location", location, "error", err)
+			if location == "global" {
+				return
+			}
+			continue
+		}
+
+		err = locations.DeidentifyTemplates.List(parent).Pages(ctx, func(page *dlp.GooglePrivacyDlpV2ListDeidentifyTemplatesResponse) error {
+			for _, t := range page.DeidentifyTemplates {
+				info := fmt.Sprintf("Name: %s\nDisplay Name: %s\nLocation: %s\nTransformation: %s\nUpdated: %s",
+					path.Base(t.Name), t.DisplayName, location, deidentifyTransformation(t.DeidentifyConfig), t.UpdateTime)
+				writeResourceRaw("DLP De-identify Template", info, t)
+				count++
+			}
+			return nil
+		})
+		if err != nil {
+			slog.Error("Failed to list DLP de-identify templates", "location", location, "error", err)
+		}
+
+		err = locations.JobTriggers.List(parent).Pages(ctx, func(page *dlp.GooglePrivacyDlpV2ListJobTriggersResponse) error {
+			for _, t := range page.JobTriggers {
+				var schedules []string
+				for _, trigger := range t.Triggers {
+					if trigger.Schedule != nil {
+						schedules = append(schedules, "every "+trigger.Schedule.RecurrencePeriodDuration)
+					} else if trigger.Manual != nil {
+						schedules = append(schedules, "manual")
+					}
+				}
+				source, template := "", ""
+				if t.InspectJob != nil {
+					source, template = dlpStorage(t.InspectJob.StorageConfig), path.Base(t.InspectJob.InspectTemplateName)
+				}
+				info := fmt.Sprintf("Name: %s\nDisplay Name: %s\nLocation: %s\nStatus: %s\nSchedule: %s\nSource: %s\nInspect Template: %s\nLast Run: %s",
+					path.Base(t.Name), t.DisplayName, location, t.Status, strings.Join(schedules, ", "),
+					source, template, t.LastRunTime)
+				writeResourceRaw("DLP Job Trigger", info, t)
+				count++
+			}
+			return nil
+		})
+		if err != nil {
+			slog.Error("Failed to list DLP job triggers", "location", location, "error", err)
+		}
+
+		err = locations.StoredInfoTypes.List(parent).Pages(ctx, func(page *dlp.GooglePrivacyDlpV2ListStoredInfoTypesResponse) error {
+			for _, t := range page.StoredInfoTypes {
+				displayName, state := "", ""
+				if v := t.CurrentVersion; v != nil {
+					state = v.State
+					if v.Config != nil {
+						displayName = v.Config.DisplayName
+					}
+				}
+				info := fmt.Sprintf("Name: %s\nDisplay Name: %s\nLocation: %s\nState: %s",
+					path.Base(t.Name), displayName, location, state)
+				writeResourceRaw("DLP Stored InfoType", info, t)
+				count++
+			}
+			return nil
+		})
+		if err != nil {
+			slog.Error("Failed to list DLP stored infoTypes", "location", location, "error", err)
+		}
+	}
+	scanProgress.found(count)
+}
+
+// deidentifyTransformation names the kind of transformation a
+// de-identification template applies.
+func deidentifyTransformation(config *dlp.GooglePrivacyDlpV2DeidentifyConfig) string {
+	switch {
+	case config == nil:
+		return ""
+	case config.InfoTypeTransformations != nil:
+		return "infoType"
+	case config.RecordTransformations != nil:
+		return "record"
+	case config.ImageTransformations != nil:
+		return "image"
+	}
+	return ""
+}
+
+// dlpStorage describes what a DLP job inspects: a bucket path, a BigQuery
+// table or a Datastore kind.
+func dlpStorage(config *dlp.GooglePrivacyDlpV2StorageConfig) string {
+	switch {
+	case config == nil:
+		return ""
+	case config.CloudStorageOptions != nil && config.CloudStorageOptions.FileSet != nil:
+		return config.CloudStorageOptions.FileSet.Url
+	case config.BigQueryOptions != nil && config.BigQueryOptions.TableReference != nil:
+		t := config.BigQueryOptions.TableReference
+		return fmt.Sprintf("%s.%s.%s", t.ProjectId, t.DatasetId, t.TableId)
+	case config.DatastoreOptions != nil && config.DatastoreOptions.Kind != nil:
+		return "datastore:" + config.DatastoreOptions.Kind.Name
+	case config.HybridOptions != nil:
+		return "hybrid"
+	}
+	return ""
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+var dryRun bool
+
+// collectorCalls estimates how many API requests a collector makes per run:
+// one list call per permission it needs, in each of three zones for zonal
+// collectors since most regions have three. Paging, per-resource lookups
+// and skipped zones make the real number higher or lower, so it is only a
+// rough figure for planning quotas.
+func collectorCalls(c collector) int {
+	calls := max(len(c.permissions), 1)
+	if c.zonal {
+		calls *= 3
+	}
+	return calls
+}
+
+// writeDryRun prints what a scan with the current flags would do: every
+// collector with its section, API service and permissions, per region for
+// regional ones, the analysis passes and exports that run afterwards, and
+// an estimate of the API calls. Nothing is requested from any API.
+func writeDryRun(w io.Writer) {
+	services := collectorServices()
+	totalCalls := 0
+	perService := map[string]int{}
+
+	fmt.Fprintf(w, "Dry run for project %s: %d global and %d regional collectors in %d regions. No API calls are made.\n",
+		projectID, len(globalCollectors), len(regionalCollectors), len(regions))
+
+	if !skipPreflight {
+		wanted := map[string]bool{}
+		for _, c := range append(append([]collector{}, globalCollectors...), regionalCollectors...) {
+			for _, p := range c.permissions {
+				wanted[p] = true
+			}
+		}
+		calls := (len(wanted) + 99) / 100
+		fmt.Fprintf(w, "\nPre-flight permission check: %d permissions, %d testIamPermissions calls\n", len(wanted), calls)
+		totalCalls += calls
+		perService["cloudresourcemanager"] += calls
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "\nGLOBAL COLLECTORS\n")
+	fmt.Fprintf(tw, "Collector\tSection\tService\tCalls\tPermissions\n")
+	section := ""
+	for _, c := range globalCollectors {
+		if c.section != "" {
+			section = c.section
+		}
+		calls := collectorCalls(c)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\n", c.name, section, services[c.name], calls, strings.Join(c.permissions, ", "))
+		totalCalls += calls
+		perService[services[c.name]] += calls
+	}
+
+	fmt.Fprintf(tw, "\nREGIONAL COLLECTORS (x %d regions: %s)\n", len(regions), strings.Join(regions, ", "))
+	fmt.Fprintf(tw, "Collector\tZonal\tService\tCalls\tPermissions\n")
+	for _, c := range regionalCollectors {
+		calls := collectorCalls(c) * len(regions)
+		fmt.Fprintf(tw, "%s\t%t\t%s\t%d\t%s\n", c.name, c.zonal, services[c.name], calls, strings.Join(c.permissions, ", "))
+		totalCalls += calls
+		perService[services[c.name]] += calls
+	}
+	tw.Flush()
+
+	var after []string
+	if estimateCosts || findIdle {
+		after = append(after, "load Cloud Billing Catalog prices")
+	}
+	if findIdle {
+		after = append(after, "--find-idle: list disks in every zone")
+	}
+	if attackSurface {
+		after = append(after, "--attack-surface: from the inventory, no calls")
+	}
+	if cmekAudit {
+		after = append(after, "--cmek-audit: from the inventory, no calls")
+	}
+	if dataResidency {
+		after = append(after, "--data-residency: get the resource locations policy")
+	}
+	if isKMSKeyVersion(signKey) {
+		after = append(after, "sign the report manifest with Cloud KMS key "+signKey)
+	}
+	if bigQueryTable != "" {
+		after = append(after, "export to BigQuery table "+bigQueryTable)
+	}
+	if notifyWebhook != "" {
+		after = append(after, "post a summary to the notification webhook")
+	}
+	if uploadDest != "" {
+		after = append(after, "upload the report to "+uploadDest)
+	}
+	if len(after) > 0 {
+		fmt.Fprintf(w, "\nAfter the scan:\n")
+		for _, step := range after {
+			fmt.Fprintf(w, "  - %s\n", step)
+		}
+	}
+
+	fmt.Fprintf(w, "\nEstimated API calls: at least %d\n", totalCalls)
+	names := sortedKeys(perService)
+	sort.SliceStable(names, func(i, j int) bool { return perService[names[i]] > perService[names[j]] })
+	for _, service := range names {
+		fmt.Fprintf(w, "  %-28s %6d\n", service, perService[service])
+	}
+}
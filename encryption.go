@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"google.golang.org/api/pubsub/v1"
+)
+
+var cmekAudit bool
+
+// encryptedTypes are the resource types whose rows carry a KMS Key field,
+// empty when Google manages the key.
+var encryptedTypes = []string{"Persistent Disk", "Storage Bucket", "Cloud SQL Instance", "BigQuery Dataset", "Pub/Sub Topic", "Kafka Cluster"}
+
+// kmsKeyName strips the key version from a KMS key resource name, so disks
+// encrypted with different versions of the same key are grouped together.
+func kmsKeyName(name string) string {
+	key, _, _ := strings.Cut(name, "/cryptoKeyVersions/")
+	return key
+}
+
+// getPubSubTopics lists Pub/Sub topics with their KMS key, message
+// retention and the regions messages may be stored in.
+func getPubSubTopics(ctx context.Context) {
+	pubsubService, err := pubsub.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create Pub/Sub service", "error", err)
+		return
+	}
+
+	count := 0
+	err = pubsubService.Projects.Topics.List("projects/"+projectID).Pages(ctx, func(page *pubsub.ListTopicsResponse) error {
+		for _, topic := range page.Topics {
+			var regions []string
+			if topic.MessageStoragePolicy != nil {
+				regions = topic.MessageStoragePolicy.AllowedPersistenceRegions
+			}
+			info := fmt.Sprintf("Name: %s\nKMS Key: %s\nMessage Retention: %s\nStorage Regions: %s",
+				path.Base(topic.Name), topic.KmsKeyName, topic.MessageRetentionDuration, strings.Join(regions, ", "))
+			writeResourceRaw("Pub/Sub Topic", info, topic)
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Error("Failed to list Pub/Sub topics", "error", err)
+	}
+	scanProgress.found(count)
+}
+
+// runCMEKAudit groups disks, buckets, Cloud SQL instances, BigQuery
+// datasets, Pub/Sub topics and Kafka clusters by the customer-managed key
+// that encrypts them, and lists those left on Google-managed encryption. Each gets an
+// Encryption field of CMEK or Google-managed.
+func runCMEKAudit() {
+	byKey := map[string][]*inventoryRow{}
+	var googleManaged []*inventoryRow
+	for i := range inventory {
+		row := &inventory[i]
+		if !slices.Contains(encryptedTypes, row.ResourceType) {
+			continue
+		}
+		if key := row.field("KMS Key"); key != "" {
+			row.setField("Encryption", "CMEK")
+			byKey[kmsKeyName(key)] = append(byKey[kmsKeyName(key)], row)
+		} else {
+			row.setField("Encryption", "Google-managed")
+			googleManaged = append(googleManaged, row)
+		}
+	}
+
+	writeSection("ENCRYPTION (CMEK) AUDIT")
+	total := len(googleManaged)
+	for _, key := range sortedKeys(byKey) {
+		rows := byKey[key]
+		total += len(rows)
+		fmt.Fprintf(report, "\n%s (%d)\n", key, len(rows))
+		writeEncryptedRows(rows)
+	}
+	if len(googleManaged) > 0 {
+		fmt.Fprintf(report, "\nGoogle-managed (%d)\n", len(googleManaged))
+		writeEncryptedRows(googleManaged)
+	}
+	fmt.Fprintf(report, "\n%d of %d resources use customer-managed keys, across %d keys\n",
+		total-len(googleManaged), total, len(byKey))
+}
+
+func writeEncryptedRows(rows []*inventoryRow) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].ResourceType != rows[j].ResourceType {
+			return rows[i].ResourceType < rows[j].ResourceType
+		}
+		return rows[i].Name < rows[j].Name
+	})
+	for _, row := range rows {
+		fmt.Fprintf(report, "  %-24s %s\n", row.ResourceType, row.Name)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+var attackSurface bool
+
+// publicEndpoint returns how a resource can be reached from the internet,
+// or "" if it can't: instances with an external IP, external load
+// balancers, Cloud SQL instances with a public IP, Cloud Run services
+// anyone may invoke, GKE clusters with a public control plane and buckets
+// readable by allUsers or allAuthenticatedUsers.
+func publicEndpoint(row inventoryRow) string {
+	switch row.ResourceType {
+	case "Compute Instance":
+		return row.field("External IP")
+	case "Forwarding Rule", "Global Forwarding Rule":
+		if strings.HasPrefix(row.field("Scheme"), "EXTERNAL") {
+			endpoint := fmt.Sprintf("%s %s", row.field("IP Address"), row.field("Ports"))
+			if workload := row.field("Kubernetes Workload"); workload != "" {
+				endpoint += " (" + workload + ")"
+			}
+			return endpoint
+		}
+	case "Cloud SQL Instance":
+		if ip := row.field("Public IP"); ip != "" {
+			return fmt.Sprintf("%s (authorized networks: %s)", ip, row.field("Authorized Networks"))
+		}
+	case "Cloud Run Service":
+		if row.field("Public Invoker") == "true" && row.field("Ingress") == "INGRESS_TRAFFIC_ALL" {
+			return row.field("URL")
+		}
+	case "GKE Cluster":
+		if row.field("Private Endpoint") == "false" && row.field("Endpoint") != "" {
+			return fmt.Sprintf("%s (authorized networks: %s)", row.field("Endpoint"), row.field("Master Authorized Networks"))
+		}
+	case "Storage Bucket":
+		if access := row.field("Public Access"); access != "" {
+			return "gs://" + row.Name + " (" + access + ")"
+		}
+	}
+	return ""
+}
+
+// runAttackSurface lists every publicly reachable resource in an ATTACK
+// SURFACE section and gives each a Public Endpoint field.
+func runAttackSurface() {
+	var exposed []*inventoryRow
+	for i := range inventory {
+		row := &inventory[i]
+		if endpoint := publicEndpoint(*row); endpoint != "" {
+			row.setField("Public Endpoint", endpoint)
+			exposed = append(exposed, row)
+		}
+	}
+
+	writeSection("ATTACK SURFACE")
+	if len(exposed) == 0 {
+		fmt.Fprintln(report, "\nNo publicly reachable resources found.")
+		return
+	}
+	for _, row := range exposed {
+		fmt.Fprintf(report, "\n%-24s %-40s %s", row.ResourceType, row.Name, row.field("Public Endpoint"))
+	}
+	fmt.Fprintf(report, "\n\n%d publicly reachable resources\n", len(exposed))
+}
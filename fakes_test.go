@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"cloud.google.com/go/container/apiv1/containerpb"
+	"google.golang.org/api/compute/v1"
+	sqladmin "google.golang.org/api/sqladmin/v1"
+)
+
+// fakeCompute answers the computeAPI from canned resources. Zonal and
+// regional lists are keyed by zone or region; a region without zones is
+// one that doesn't exist.
+type fakeCompute struct {
+	zones           map[string][]string
+	project         *compute.Project
+	instances       map[string][]*compute.Instance
+	disks           map[string][]*compute.Disk
+	snapshots       []*compute.Snapshot
+	networks        []*compute.Network
+	subnetworks     map[string][]*compute.Subnetwork
+	firewalls       []*compute.Firewall
+	forwardingRules map[string][]*compute.ForwardingRule
+	globalRules     []*compute.ForwardingRule
+}
+
+func (f *fakeCompute) Region(ctx context.Context, region string) (*compute.Region, error) {
+	zones, ok := f.zones[region]
+	if !ok {
+		return nil, fmt.Errorf("region %s not found", region)
+	}
+	r := &compute.Region{Name: region}
+	for _, zone := range zones {
+		r.Zones = append(r.Zones, "https://www.googleapis.com/compute/v1/projects/"+projectID+"/zones/"+zone)
+	}
+	return r, nil
+}
+
+func (f *fakeCompute) Project(ctx context.Context) (*compute.Project, error) {
+	if f.project == nil {
+		return &compute.Project{Name: projectID}, nil
+	}
+	return f.project, nil
+}
+
+func (f *fakeCompute) Instances(ctx context.Context, zone string) ([]*compute.Instance, error) {
+	return f.instances[zone], nil
+}
+
+func (f *fakeCompute) Disks(ctx context.Context, zone string) ([]*compute.Disk, error) {
+	return f.disks[zone], nil
+}
+
+func (f *fakeCompute) Snapshots(ctx context.Context) ([]*compute.Snapshot, error) {
+	return f.snapshots, nil
+}
+
+func (f *fakeCompute) Networks(ctx context.Context) ([]*compute.Network, error) {
+	return f.networks, nil
+}
+
+func (f *fakeCompute) Subnetworks(ctx context.Context, region string) ([]*compute.Subnetwork, error) {
+	return f.subnetworks[region], nil
+}
+
+func (f *fakeCompute) Firewalls(ctx context.Context) ([]*compute.Firewall, error) {
+	return f.firewalls, nil
+}
+
+func (f *fakeCompute) ForwardingRules(ctx context.Context, region string) ([]*compute.ForwardingRule, error) {
+	return f.forwardingRules[region], nil
+}
+
+func (f *fakeCompute) GlobalForwardingRules(ctx context.Context) ([]*compute.ForwardingRule, error) {
+	return f.globalRules, nil
+}
+
+// fakeSQL answers the sqlAPI from canned instances, with databases and
+// users keyed by instance name.
+type fakeSQL struct {
+	instances []*sqladmin.DatabaseInstance
+	databases map[string][]*sqladmin.Database
+	users     map[string][]*sqladmin.User
+}
+
+func (f *fakeSQL) Instances(ctx context.Context) ([]*sqladmin.DatabaseInstance, error) {
+	return f.instances, nil
+}
+
+func (f *fakeSQL) Databases(ctx context.Context, instance string) ([]*sqladmin.Database, error) {
+	return f.databases[instance], nil
+}
+
+func (f *fakeSQL) Users(ctx context.Context, instance string) ([]*sqladmin.User, error) {
+	return f.users[instance], nil
+}
+
+// fakeGKE answers the gkeAPI from canned clusters keyed by location. A
+// location without clusters is one where GKE isn't available.
+type fakeGKE struct {
+	clusters map[string][]*containerpb.Cluster
+	closed   bool
+}
+
+func (f *fakeGKE) Clusters(ctx context.Context, location string) ([]*containerpb.Cluster, error) {
+	clusters, ok := f.clusters[location]
+	if !ok {
+		return nil, fmt.Errorf("location %s not found", location)
+	}
+	return clusters, nil
+}
+
+func (f *fakeGKE) Close() error {
+	f.closed = true
+	return nil
+}
+
+// useFakes makes the collectors open the given fakes instead of the live
+// APIs until the test ends. A nil fake leaves that API as it is.
+func useFakes(t *testing.T, c computeAPI, s sqlAPI, g gkeAPI) {
+	savedCompute, savedSQL, savedGKE := newComputeAPI, newSQLAPI, newGKEAPI
+	t.Cleanup(func() { newComputeAPI, newSQLAPI, newGKEAPI = savedCompute, savedSQL, savedGKE })
+	if c != nil {
+		newComputeAPI = func(context.Context) (computeAPI, error) { return c, nil }
+	}
+	if s != nil {
+		newSQLAPI = func(context.Context) (sqlAPI, error) { return s, nil }
+	}
+	if g != nil {
+		newGKEAPI = func(context.Context) (gkeAPI, error) { return g, nil }
+	}
+}
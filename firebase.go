@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+
+	"google.golang.org/api/firebase/v1beta1"
+	"google.golang.org/api/firebasedatabase/v1beta"
+	"google.golang.org/api/firebasehosting/v1beta1"
+	"google.golang.org/api/identitytoolkit/v2"
+)
+
+// getFirebase reports Firebase on projects where it is enabled: the
+// registered apps, Hosting sites, Realtime Database instances and the
+// Authentication configuration.
+func getFirebase(ctx context.Context) {
+	firebaseService, err := firebase.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create Firebase service", "error", err)
+		return
+	}
+	projectName := "projects/" + projectID
+	if _, err := firebaseService.Projects.Get(projectName).Context(ctx).Do(); err != nil {
+		// Skip projects that Firebase hasn't been added to
+		slog.Debug("Skipping Firebase", "error", err)
+		return
+	}
+
+	count := 0
+	err = firebaseService.Projects.SearchApps(projectName).Pages(ctx, func(page *firebase.SearchFirebaseAppsResponse) error {
+		for _, app := range page.Apps {
+			info := fmt.Sprintf("Name: %s\nApp ID: %s\nPlatform: %s\nNamespace: %s\nState: %s",
+				app.DisplayName, app.AppId, app.Platform, app.Namespace, app.State)
+			writeResourceRaw("Firebase App", info, app)
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Error("Failed to list Firebase apps", "error", err)
+	}
+
+	hostingService, err := firebasehosting.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create Firebase Hosting service", "error", err)
+	} else if sites, err := hostingService.Projects.Sites.List(projectName).Context(ctx).Do(); err != nil {
+		slog.Error("Failed to list Firebase Hosting sites", "error", err)
+	} else {
+		for _, site := range sites.Sites {
+			info := fmt.Sprintf("Name: %s\nURL: %s\nType: %s\nApp ID: %s",
+				path.Base(site.Name), site.DefaultUrl, site.Type, site.AppId)
+			writeResourceRaw("Firebase Hosting Site", info, site)
+			count++
+		}
+	}
+
+	databaseService, err := firebasedatabase.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create Realtime Database service", "error", err)
+	} else if instances, err := databaseService.Projects.Locations.Instances.List(projectName + "/locations/-").Context(ctx).Do(); err != nil {
+		slog.Error("Failed to list Realtime Database instances", "error", err)
+	} else {
+		for _, instance := range instances.Instances {
+			info := fmt.Sprintf("Name: %s\nLocation: %s\nURL: %s\nType: %s\nState: %s",
+				path.Base(instance.Name), membershipLocation(instance.Name), instance.DatabaseUrl, instance.Type, instance.State)
+			writeResourceRaw("Realtime Database", info, instance)
+			count++
+		}
+	}
+
+	identityService, err := identitytoolkit.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create Identity Toolkit service", "error", err)
+	} else if config, err := identityService.Projects.GetConfig(projectName + "/config").Context(ctx).Do(); err != nil {
+		// Authentication hasn't been set up on the project
+		slog.Debug("Skipping Firebase Authentication", "error", err)
+	} else {
+		mfa := "DISABLED"
+		if config.Mfa != nil && config.Mfa.State != "" {
+			mfa = config.Mfa.State
+		}
+		info := fmt.Sprintf("Name: %s\nSign-in Methods: %s\nAuthorized Domains: %s\nMFA: %s\nMulti-tenant: %t",
+			projectID, strings.Join(signInMethods(config.SignIn), ", "), strings.Join(config.AuthorizedDomains, ", "),
+			mfa, config.MultiTenant != nil && config.MultiTenant.AllowTenants)
+		writeResourceRaw("Firebase Authentication", info, config)
+		count++
+	}
+	scanProgress.found(count)
+}
+
+// signInMethods lists the built-in sign-in providers that are enabled.
+// Federated providers such as Google or SAML are configured separately.
+func signInMethods(signIn *identitytoolkit.GoogleCloudIdentitytoolkitAdminV2SignInConfig) []string {
+	if signIn == nil {
+		return nil
+	}
+	var methods []string
+	if signIn.Email != nil && signIn.Email.Enabled {
+		if signIn.Email.PasswordRequired {
+			methods = append(methods, "email/password")
+		} else {
+			methods = append(methods, "email link")
+		}
+	}
+	if signIn.PhoneNumber != nil && signIn.PhoneNumber.Enabled {
+		methods = append(methods, "phone")
+	}
+	if signIn.Anonymous != nil && signIn.Anonymous.Enabled {
+		methods = append(methods, "anonymous")
+	}
+	return methods
+}
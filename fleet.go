@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"sort"
+	"strings"
+
+	"google.golang.org/api/gkehub/v1"
+)
+
+// getFleet lists the project's fleet (GKE Hub) memberships and the fleet
+// features enabled on them, such as Config Management, Policy Controller,
+// Service Mesh and multi-cluster ingress, with each membership's state.
+func getFleet(ctx context.Context) {
+	hubService, err := gkehub.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create GKE Hub service", "error", err)
+		return
+	}
+
+	memberships, err := hubService.Projects.Locations.Memberships.List(fmt.Sprintf("projects/%s/locations/-", projectID)).Context(ctx).Do()
+	if err != nil {
+		// Skip projects that don't have the GKE Hub API enabled
+		slog.Debug("Skipping fleet", "error", err)
+		return
+	}
+
+	count := 0
+	for _, m := range memberships.Resources {
+		cluster, state, issuer := "", "", ""
+		if m.Endpoint != nil && m.Endpoint.GkeCluster != nil {
+			cluster = strings.TrimPrefix(m.Endpoint.GkeCluster.ResourceLink, "//container.googleapis.com/")
+		}
+		if m.State != nil {
+			state = m.State.Code
+		}
+		if m.Authority != nil {
+			issuer = m.Authority.Issuer
+		}
+		info := fmt.Sprintf("Name: %s\nLocation: %s\nCluster: %s\nState: %s\nWorkload Identity Issuer: %s\nCreated: %s",
+			path.Base(m.Name), membershipLocation(m.Name), cluster, state, issuer, m.CreateTime)
+		writeResourceRaw("Fleet Membership", info, m)
+		count++
+	}
+
+	features, err := hubService.Projects.Locations.Features.List(fmt.Sprintf("projects/%s/locations/global", projectID)).Context(ctx).Do()
+	if err != nil {
+		slog.Error("Failed to list fleet features", "error", err)
+		scanProgress.found(count)
+		return
+	}
+	for _, feature := range features.Resources {
+		state, featureState := "", ""
+		if feature.ResourceState != nil {
+			state = feature.ResourceState.State
+		}
+		if feature.State != nil && feature.State.State != nil {
+			featureState = strings.TrimSpace(feature.State.State.Code + " " + feature.State.State.Description)
+		}
+
+		// Per-membership state is how Config Management and Policy
+		// Controller report whether each cluster is installed and in sync.
+		var members []string
+		for membership, ms := range feature.MembershipStates {
+			code := "UNKNOWN"
+			if ms.State != nil && ms.State.Code != "" {
+				code = ms.State.Code
+			}
+			members = append(members, path.Base(membership)+"="+code)
+		}
+		sort.Strings(members)
+
+		info := fmt.Sprintf("Name: %s\nState: %s\nFeature State: %s\nMemberships: %s",
+			path.Base(feature.Name), state, featureState, strings.Join(members, ", "))
+		writeResourceRaw("Fleet Feature", info, feature)
+		count++
+	}
+	scanProgress.found(count)
+}
+
+// membershipLocation returns the location part of a membership name,
+// projects/p/locations/<location>/memberships/m.
+func membershipLocation(name string) string {
+	return path.Base(path.Dir(path.Dir(name)))
+}
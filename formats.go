@@ -0,0 +1,44 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+// reportFormat describes one --format value. Formats with a render function
+// are written from the finished inventory, formats with a stream function
+// one resource at a time as the collectors report them. The text format has
+// neither because it is written to report directly. Each is written by a
+// fileSink or splitSink.
+type reportFormat struct {
+	suffix string
+	render func(w io.Writer, rows []inventoryRow) error
+	stream func(w io.Writer, row inventoryRow) error
+}
+
+var reportFormats = map[string]reportFormat{
+	"text":             {suffix: ".txt"},
+	"terraform-import": {suffix: "_import.sh", render: writeTerraformImports},
+	"dot":              {suffix: ".dot", render: writeTopologyDOT},
+	"mermaid":          {suffix: ".mmd", render: writeTopologyMermaid},
+	"markdown":         {suffix: ".md", render: writeMarkdown},
+	"sqlite":           {suffix: ".db", render: writeSQLite},
+	"ndjson":           {suffix: ".ndjson", stream: writeNDJSON},
+	"parquet":          {suffix: ".parquet", render: writeParquet},
+}
+
+func formatNames() []string {
+	names := make([]string, 0, len(reportFormats))
+	for name := range reportFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// writeNDJSON writes a resource as one line of JSON, with the schema
+// version.
+func writeNDJSON(w io.Writer, row inventoryRow) error {
+	return json.NewEncoder(w).Encode(versionedRow{inventorySchemaVersion, row})
+}
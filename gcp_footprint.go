@@ -3,26 +3,65 @@ package main
 import (
 	"bufio"
 	"context"
+	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
 	"os"
+	"path"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
-	container "cloud.google.com/go/container/apiv1"
-	"cloud.google.com/go/container/apiv1/containerpb"
 	"cloud.google.com/go/storage"
 	"google.golang.org/api/cloudresourcemanager/v1"
 	"google.golang.org/api/compute/v1"
 	"google.golang.org/api/iam/v1"
 	"google.golang.org/api/iterator"
-	sqladmin "google.golang.org/api/sqladmin/v1"
+	"google.golang.org/api/option"
 )
 
 var (
-	outputFile *os.File
-	projectID  string
-	regions    = []string{
+	outputPath    string
+	splitBy       string
+	templatePath  string
+	report        io.Writer
+	outputFormat  string
+	outputFormats []string
+	projectID     string
+	uploadDest    string
+	bigQueryTable string
+	quiet         bool
+
+	impersonateServiceAccount string
+	skipPreflight             bool
+	resume                    bool
+	incremental               bool
+	daemon                    bool
+	scanInterval              time.Duration
+	listenAddr                string
+	metricsAddr               string
+	notifyWebhook             string
+	estimateCosts             bool
+	findIdle                  bool
+	dataResidency             bool
+	sccFindings               bool
+	expandGroups              bool
+	idleDays                  int
+	notifyFormatName          string
+	clientOptions             []option.ClientOption
+
+	// console receives progress and status messages. It is stderr when the
+	// report itself goes to stdout.
+	console = os.Stdout
+
+	scanTimeout      time.Duration
+	collectorTimeout time.Duration
+
+	regions = []string{
 		"us-central1", "us-east1", "us-east4", "us-west1", "us-west2", "us-west3", "us-west4",
 		"europe-west1", "europe-west2", "europe-west3", "europe-west4", "europe-west6",
 		"europe-north1", "europe-central2",
@@ -34,23 +73,434 @@ var (
 		"me-west1", "me-central1",
 		"africa-south1",
 	}
+
+	// globalCollectors run once per scan, before the regional collectors run
+	// for every region. A non-empty section starts a new report section.
+	globalCollectors = []collector{
+		{name: "project info", section: "PROJECT INFORMATION", run: global(getProjectInfo),
+			permissions: []string{"resourcemanager.projects.get"}},
+		{name: "Essential Contacts", run: global(getEssentialContacts),
+			permissions: []string{"essentialcontacts.contacts.list"}},
+		{name: "storage buckets", section: "GLOBAL RESOURCES", run: global(getStorageBuckets),
+			assetType: "storage.googleapis.com/Bucket", permissions: []string{"storage.buckets.list", "storage.buckets.getIamPolicy"}},
+		{name: "BigQuery datasets", run: global(getBigQueryDatasets),
+			assetType: "bigquery.googleapis.com/Dataset", permissions: []string{"bigquery.datasets.get"}},
+		// Reservations are listed with their assignments and capacity
+		// commitments, which one asset count can't follow, and the job
+		// summary covers the last week, so incremental scans always re-check
+		// both.
+		{name: "BigQuery reservations", run: global(getBigQueryReservations),
+			permissions: []string{"bigquery.reservations.list", "bigquery.reservationAssignments.list", "bigquery.capacityCommitments.list"}},
+		{name: "BigQuery jobs", run: global(getBigQueryJobSummary),
+			permissions: []string{"bigquery.jobs.listAll"}},
+		{name: "Pub/Sub topics", run: global(getPubSubTopics),
+			assetType: "pubsub.googleapis.com/Topic", permissions: []string{"pubsub.topics.list"}},
+		{name: "IAM bindings", run: global(getIAMRoles),
+			permissions: []string{"resourcemanager.projects.getIamPolicy"}},
+		{name: "service accounts", run: global(getServiceAccounts),
+			assetType: "iam.googleapis.com/ServiceAccount", permissions: []string{"iam.serviceAccounts.list"}},
+		{name: "custom roles", run: global(getCustomRoles),
+			assetType: "iam.googleapis.com/Role", permissions: []string{"iam.roles.list"}},
+		{name: "workload identity pools", run: global(getWorkloadIdentityPools),
+			permissions: []string{"iam.workloadIdentityPools.list", "iam.workloadIdentityPoolProviders.list"}},
+		{name: "firewall rules", section: "GLOBAL FIREWALL RULES", run: global(getFirewallRules),
+			assetType: "compute.googleapis.com/Firewall", permissions: []string{"compute.firewalls.list"}},
+		{name: "snapshots", section: "GLOBAL SNAPSHOTS", run: global(getSnapshots),
+			assetType: "compute.googleapis.com/Snapshot", permissions: []string{"compute.snapshots.list"}},
+		// Images are flagged stale by age, which changes without the image
+		// asset changing, so incremental scans always re-check them.
+		{name: "images", section: "IMAGES", run: global(getImages),
+			permissions: []string{"compute.images.list"}},
+		{name: "machine images", run: global(getMachineImages),
+			permissions: []string{"compute.machineImages.list"}},
+		{name: "global forwarding rules", section: "GLOBAL FORWARDING RULES", run: global(getGlobalForwardingRules),
+			assetType: "compute.googleapis.com/GlobalForwardingRule", permissions: []string{"compute.globalForwardingRules.list"}},
+		{name: "global addresses", section: "GLOBAL ADDRESSES", run: global(getGlobalAddresses),
+			assetType: "compute.googleapis.com/GlobalAddress", permissions: []string{"compute.globalAddresses.list"}},
+		{name: "VPC peerings", section: "VPC PEERING AND SHARED VPC", run: global(getNetworkPeerings),
+			permissions: []string{"compute.networks.list"}},
+		{name: "Shared VPC", run: global(getSharedVPC),
+			permissions: []string{"compute.projects.get"}},
+		{name: "routes", section: "ROUTES", run: global(getRoutes),
+			assetType: "compute.googleapis.com/Route", permissions: []string{"compute.routes.list"}},
+		{name: "Network Connectivity Center", section: "NETWORK CONNECTIVITY CENTER", run: global(getConnectivityHubs),
+			permissions: []string{"networkconnectivity.hubs.list", "networkconnectivity.spokes.list"}},
+		{name: "logging audit", section: "LOGGING AUDIT", run: global(getLoggingAudit),
+			permissions: []string{"compute.networks.list", "compute.subnetworks.list", "dns.policies.list"}},
+		// The observability summaries count recent traces, profiles and
+		// error groups rather than assets, so incremental scans always
+		// re-check them.
+		{name: "Cloud Trace", section: "OBSERVABILITY", run: global(getTraceSummary),
+			permissions: []string{"cloudtrace.traces.list"}},
+		{name: "Cloud Profiler", run: global(getProfilerSummary),
+			permissions: []string{"cloudprofiler.profiles.list"}},
+		{name: "Error Reporting", run: global(getErrorReportingSummary),
+			permissions: []string{"errorreporting.groups.list"}},
+		{name: "Cloud Armor policies", section: "CLOUD ARMOR SECURITY POLICIES", run: global(getSecurityPolicies),
+			assetType: "compute.googleapis.com/SecurityPolicy", permissions: []string{"compute.securityPolicies.list", "compute.backendServices.list"}},
+		// Whether a certificate is expiring soon depends on the scan time,
+		// not on the certificate asset, so incremental scans always re-check
+		// them.
+		{name: "SSL certificates", section: "CERTIFICATES", run: global(getSSLCertificates),
+			permissions: []string{"compute.sslCertificates.list"}},
+		{name: "Certificate Manager", run: global(getCertificateManager),
+			permissions: []string{"certificatemanager.certs.list", "certificatemanager.certmaps.list", "certificatemanager.certmapentries.list"}},
+		// Assured Workloads permissions are granted on the organization.
+		{name: "Assured Workloads", section: "ASSURED WORKLOADS", run: global(getAssuredWorkloads),
+			permissions: []string{"resourcemanager.projects.get"}},
+		{name: "IAP backends", section: "IDENTITY-AWARE PROXY", run: global(getIAPBackends),
+			permissions: []string{"compute.backendServices.list", "resourcemanager.projects.get", "iap.web.getIamPolicy", "iap.webServices.getIamPolicy"}},
+		{name: "Identity Platform", section: "IDENTITY PLATFORM", run: global(getIdentityPlatform),
+			permissions: []string{"identitytoolkit.tenants.list", "firebaseauth.configs.get"}},
+		{name: "reCAPTCHA Enterprise keys", section: "RECAPTCHA ENTERPRISE", run: global(getRecaptchaKeys),
+			assetType: "recaptchaenterprise.googleapis.com/Key", permissions: []string{"recaptchaenterprise.keys.list"}},
+		// Access Context Manager permissions are granted on the organization
+		// and can't be tested on the project by the preflight check.
+		{name: "VPC Service Controls", section: "VPC SERVICE CONTROLS", run: global(getServiceControls),
+			permissions: []string{"resourcemanager.projects.get"}},
+		{name: "DLP", section: "SENSITIVE DATA PROTECTION", run: global(getDLP),
+			permissions: []string{"dlp.inspectTemplates.list", "dlp.deidentifyTemplates.list", "dlp.jobTriggers.list", "dlp.storedInfoTypes.list"}},
+		// The collector also lists each agent's webhooks and environments,
+		// which an agent asset count can't follow, so incremental scans
+		// always re-check Dialogflow.
+		{name: "Dialogflow agents", section: "DIALOGFLOW", run: global(getDialogflow),
+			permissions: []string{"dialogflow.agents.list", "dialogflow.agents.get", "dialogflow.webhooks.list", "dialogflow.environments.list", "dialogflow.fulfillments.get"}},
+		{name: "Cloud TPUs", section: "ACCELERATORS", run: global(getTPUs),
+			assetType: "tpu.googleapis.com/Node", permissions: []string{"tpu.nodes.list"}},
+		{name: "GPU summary", run: global(getGPUSummary),
+			permissions: []string{"compute.instances.list"}},
+		{name: "fleet", section: "FLEET", run: global(getFleet),
+			permissions: []string{"gkehub.memberships.list", "gkehub.features.list"}},
+		{name: "Binary Authorization", section: "SUPPLY CHAIN", run: global(getBinaryAuthorization),
+			permissions: []string{"binaryauthorization.policy.get", "binaryauthorization.attestors.list"}},
+		{name: "OS patch management", section: "OS PATCH MANAGEMENT", run: global(getPatchManagement),
+			permissions: []string{"osconfig.patchDeployments.list", "osconfig.patchJobs.list"}},
+		{name: "Firebase", section: "FIREBASE", run: global(getFirebase),
+			permissions: []string{"firebase.projects.get", "firebase.clients.list", "firebasehosting.sites.list", "firebasedatabase.instances.list", "firebaseauth.configs.get"}},
+		{name: "Storage Transfer", section: "DATA TRANSFER", run: global(getTransferJobs),
+			permissions: []string{"storagetransfer.jobs.list"}},
+		{name: "Apigee", section: "APIGEE", run: global(getApigee),
+			permissions: []string{"apigee.organizations.get", "apigee.environments.get", "apigee.instances.list", "apigee.instanceattachments.list", "apigee.proxies.list", "apigee.deployments.list"}},
+		{name: "Deployment Manager", section: "DEPLOYMENT MANAGER", run: global(getDeploymentManager),
+			assetType: "deploymentmanager.googleapis.com/Deployment", permissions: []string{"deploymentmanager.deployments.list"}},
+	}
+	regionalCollectors = []collector{
+		{name: "compute instances", run: getComputeInstances,
+			assetType: "compute.googleapis.com/Instance", zonal: true, permissions: []string{"compute.instances.list", "compute.regions.get"}},
+		{name: "GKE clusters", run: getGKEClusters,
+			assetType: "container.googleapis.com/Cluster", permissions: []string{"container.clusters.list"}},
+		// The collector also lists each instance's databases and users,
+		// which an instance asset count can't follow, so incremental scans
+		// always re-check Cloud SQL.
+		{name: "Cloud SQL instances", run: getCloudSQLInstances,
+			permissions: []string{"cloudsql.instances.list", "cloudsql.databases.list", "cloudsql.users.list"}},
+		{name: "VPC networks", run: getVPCs,
+			permissions: []string{"compute.networks.list"}},
+		{name: "subnets", run: getSubnets,
+			assetType: "compute.googleapis.com/Subnetwork", permissions: []string{"compute.subnetworks.list"}},
+		{name: "persistent disks", run: getDisks,
+			assetType: "compute.googleapis.com/Disk", zonal: true, permissions: []string{"compute.disks.list", "compute.regions.get"}},
+		{name: "forwarding rules", run: getForwardingRules,
+			assetType: "compute.googleapis.com/ForwardingRule", permissions: []string{"compute.forwardingRules.list"}},
+		{name: "addresses", run: getAddresses,
+			assetType: "compute.googleapis.com/Address", permissions: []string{"compute.addresses.list"}},
+		{name: "instance groups", run: getInstanceGroups,
+			zonal: true, permissions: []string{"compute.instanceGroups.list", "compute.regions.get"}},
+		{name: "peering routes", run: getPeeringRoutes,
+			permissions: []string{"compute.networks.list", "compute.networks.listPeeringRoutes"}},
+		{name: "Private Service Connect", run: getPrivateServiceConnect,
+			permissions: []string{"compute.forwardingRules.list", "compute.serviceAttachments.list"}},
+		{name: "packet mirroring", run: getPacketMirroring,
+			assetType: "compute.googleapis.com/PacketMirroring", permissions: []string{"compute.packetMirrorings.list"}},
+		{name: "Cloud IDS endpoints", run: getIDSEndpoints,
+			zonal: true, permissions: []string{"ids.endpoints.list", "compute.regions.get"}},
+		{name: "Batch jobs", run: getBatchJobs,
+			assetType: "batch.googleapis.com/Job", permissions: []string{"batch.jobs.list"}},
+		{name: "sole-tenant nodes", run: getSoleTenantNodes,
+			permissions: []string{"compute.nodeTemplates.list", "compute.nodeGroups.list", "compute.regions.get"}},
+		{name: "commitments and reservations", run: getCommitments,
+			permissions: []string{"compute.commitments.list", "compute.reservations.list", "compute.instances.list", "compute.machineTypes.get", "compute.regions.get"}},
+		{name: "VM Manager coverage", run: getVMManagerCoverage,
+			zonal: true, permissions: []string{"compute.instances.list", "osconfig.inventories.list", "compute.regions.get"}},
+		{name: "resource policies", run: getResourcePolicies,
+			permissions: []string{"compute.resourcePolicies.list", "compute.disks.list", "compute.instances.list"}},
+		{name: "Backup and DR", run: getBackupDR,
+			permissions: []string{"backupdr.backupVaults.list", "backupdr.backupPlans.list", "backupdr.backupPlanAssociations.list", "backupdr.managementServers.list"}},
+		{name: "Dataplex lakes", run: getDataplex,
+			permissions: []string{"dataplex.lakes.list", "dataplex.zones.list", "dataplex.assets.list"}},
+		{name: "Data Catalog taxonomies", run: getPolicyTagTaxonomies,
+			permissions: []string{"datacatalog.taxonomies.list", "datacatalog.taxonomies.get"}},
+		{name: "Dataform repositories", run: getDataform,
+			permissions: []string{"dataform.repositories.list", "dataform.workspaces.list"}},
+		{name: "Looker instances", run: getLookerInstances,
+			assetType: "looker.googleapis.com/Instance", permissions: []string{"looker.instances.list"}},
+		{name: "Cloud Deploy", run: getCloudDeploy,
+			permissions: []string{"clouddeploy.deliveryPipelines.list", "clouddeploy.releases.list", "clouddeploy.targets.list"}},
+		// Who may invoke a service is set by its IAM policy, which changes
+		// without the service asset being updated, so incremental scans
+		// always re-check Cloud Run.
+		{name: "Cloud Run services", run: getCloudRunServices,
+			permissions: []string{"run.services.list", "run.services.getIamPolicy"}},
+		{name: "Service Directory", run: getServiceDirectory,
+			permissions: []string{"servicedirectory.namespaces.list", "servicedirectory.services.list", "servicedirectory.endpoints.list"}},
+		{name: "integrations", run: getIntegrations,
+			permissions: []string{"connectors.connections.list", "integrations.integrations.list"}},
+		// The collector lists topics and their subscriptions, which one
+		// asset count can't follow, so incremental scans always re-check
+		// Pub/Sub Lite.
+		{name: "Pub/Sub Lite", run: getPubSubLite,
+			zonal: true, permissions: []string{"pubsublite.topics.list", "pubsublite.subscriptions.list", "compute.regions.get"}},
+		// The collector lists clusters and their topics, which one asset
+		// count can't follow, so incremental scans always re-check Managed
+		// Kafka.
+		{name: "Managed Kafka", run: getManagedKafka,
+			permissions: []string{"managedkafka.clusters.list", "managedkafka.topics.list"}},
+		{name: "Healthcare datasets", run: getHealthcareDatasets,
+			permissions: []string{"healthcare.datasets.list", "healthcare.fhirStores.list", "healthcare.dicomStores.list", "healthcare.hl7V2Stores.list"}},
+		{name: "Workbench instances", run: getWorkbenchInstances,
+			assetType: "notebooks.googleapis.com/Instance", zonal: true, permissions: []string{"notebooks.instances.list", "compute.regions.get"}},
+		{name: "Colab Enterprise runtimes", run: getColabRuntimes,
+			permissions: []string{"aiplatform.notebookRuntimeTemplates.list", "aiplatform.notebookRuntimes.list"}},
+	}
 )
 
+// collector queries one kind of resource. Global collectors are passed an
+// empty region. permissions lists what the collector needs on the project,
+// checked before the scan starts. assetType is the Cloud Asset Inventory
+// type of the resources it lists, used by incremental scans to tell whether
+// it needs to run again; zonal collectors list each of the region's zones,
+// which incremental scans match against the asset's zone.
+type collector struct {
+	name        string
+	section     string
+	run         func(ctx context.Context, region string)
+	permissions []string
+	assetType   string
+	zonal       bool
+}
+
+func global(run func(ctx context.Context)) func(context.Context, string) {
+	return func(ctx context.Context, _ string) { run(ctx) }
+}
+
 func main() {
-	fmt.Println("GCP Footprint Tool")
-	fmt.Println("==================")
+	flag.StringVar(&projectID, "project", "", "GCP project ID to scan (prompted for if empty)")
+	flag.StringVar(&projectList, "projects", "", "Scan several projects: a comma-separated list, @file with one per line, or all")
+	flag.IntVar(&projectsParallel, "parallel", 4, "Projects scanned at once with --projects")
+	flag.StringVar(&shardSpec, "shard", "", "With --projects, only scan shard i of n, e.g. 2/5, for splitting projects across instances")
+	flag.StringVar(&uploadDest, "upload", "", "Cloud Storage destination for the report, e.g. gs://bucket/path/")
+	flag.StringVar(&bigQueryTable, "export-bigquery", "", "BigQuery table (dataset.table or project.dataset.table) to load inventory rows into")
+	flag.StringVar(&outputPath, "output", "", "Report file, or - for stdout (default gcp_footprint_<project> plus the format's extension)")
+	flag.StringVar(&splitBy, "split-by", "", "Write a directory with one report per region or service instead of a single file")
+	flag.StringVar(&templatePath, "template", "", "Go text/template file to render the report with, in place of --format")
+	flag.StringVar(&outputFormat, "format", "text", "Report formats, comma-separated to write several at once: "+strings.Join(formatNames(), ", "))
+	flag.BoolVar(&quiet, "quiet", false, "Suppress the progress display")
+	flag.DurationVar(&scanTimeout, "timeout", 0, "Maximum duration of the whole scan, e.g. 30m (0 for no limit)")
+	flag.DurationVar(&collectorTimeout, "collector-timeout", 2*time.Minute, "Maximum duration of a single collector (0 for no limit)")
+	flag.StringVar(&impersonateServiceAccount, "impersonate-service-account", "", "Service account email to impersonate with short-lived tokens")
+	flag.BoolVar(&skipPreflight, "skip-preflight", false, "Skip the permission check before scanning")
+	flag.StringVar(&cacheDir, "cache-dir", "", "Directory to cache machine types, zones and billing SKUs in between scans")
+	flag.BoolVar(&includeRaw, "include-raw", false, "Keep the full API response of each resource in NDJSON output, the saved scan and the HTTP API")
+	flag.BoolVar(&signReports, "sign", false, "Write a SHA-256 manifest of the report files")
+	flag.StringVar(&signKey, "sign-key", "", "Sign the manifest with a Cloud KMS key version (projects/.../cryptoKeyVersions/N) or a PEM private key file; implies --sign")
+	flag.BoolVar(&redactPII, "redact", false, "Mask IP addresses and user email addresses in the report")
+	flag.BoolVar(&dryRun, "dry-run", false, "List the collectors and API calls a scan would make, without making any")
+	flag.BoolVar(&resume, "resume", false, "Resume an interrupted scan from its state file")
+	flag.BoolVar(&incremental, "incremental", false, "Only re-query resource types that changed since the last full scan")
+	flag.BoolVar(&daemon, "daemon", false, "Keep running and scan every --interval")
+	flag.DurationVar(&scanInterval, "interval", 24*time.Hour, "Time between scans in --daemon mode")
+	logLevel := flag.String("log-level", "info", "Log level: debug, info, warn or error")
+	logFormat := flag.String("log-format", "text", "Log format: text or json")
+	flag.StringVar(&listenAddr, "addr", ":8080", "Listen address for the serve command")
+	flag.BoolVar(&serveUI, "ui", false, "Serve a web dashboard of the latest scan from the serve command's HTTP server")
+	flag.StringVar(&grpcAddr, "grpc-addr", "", "Listen address for the serve command's gRPC API, e.g. :9443 (default: no gRPC API)")
+	flag.BoolVar(&estimateCosts, "estimate-costs", false, "Estimate the monthly cost of instances, disks, Cloud SQL and GKE from the Cloud Billing Catalog")
+	flag.BoolVar(&findIdle, "find-idle", false, "Flag idle and orphaned resources with their estimated monthly waste")
+	flag.BoolVar(&bucketMetrics, "bucket-metrics", false, "Add each bucket's size and object count from Cloud Monitoring")
+	flag.BoolVar(&sccFindings, "scc-findings", false, "Include active Security Command Center findings for the project")
+	flag.BoolVar(&expandGroups, "expand-groups", false, "Expand groups granted project roles into their members with the Cloud Identity API")
+	flag.BoolVar(&gkeWorkloads, "gke-workloads", false, "Connect to each GKE cluster and list its namespaces, deployments, LoadBalancer services and ingresses")
+	flag.BoolVar(&attackSurface, "attack-surface", false, "List every resource reachable from the internet in an attack surface section")
+	flag.StringVar(&terraformStateList, "terraform-state", "", "Comma-separated Terraform states to compare the inventory with: .tfstate files, gs:// objects or prefixes of the GCS backend, or terraform:DIR to pull DIR's state")
+	flag.BoolVar(&cmekAudit, "cmek-audit", false, "Group disks, buckets, Cloud SQL, BigQuery datasets, Pub/Sub topics and Kafka clusters by the KMS key that encrypts them")
+	flag.BoolVar(&dataResidency, "data-residency", false, "Summarize where buckets, Cloud SQL and BigQuery store data against the resource locations policy")
+	flag.IntVar(&idleDays, "idle-days", 30, "Days an instance must have been stopped to be flagged by --find-idle")
+	flag.StringVar(&notifyWebhook, "notify-webhook", "", "Webhook URL to post a scan summary to when a scan completes")
+	flag.StringVar(&notifyFormatName, "notify-format", "", "Notification payload: json or slack (default: slack for hooks.slack.com URLs, json otherwise)")
+	flag.StringVar(&pluginDir, "plugin-dir", "", "Directory of executable collector plugins to run alongside the built-in collectors")
+	flag.StringVar(&resultsStoreURL, "results-store", "", "Keep the history of complete scans in gs://bucket/path/ or firestore://project/collection")
+	flag.DurationVar(&resultsRetention, "results-retention", 0, "Remove stored scans older than this, e.g. 2160h for 90 days (0 keeps them all)")
+	flag.StringVar(&otlpEndpoint, "otlp-endpoint", "", "Export a trace of each scan, with a span per collector, to this OTLP endpoint, e.g. http://localhost:4318")
+	flag.StringVar(&otlpProtocol, "otlp-protocol", defaultOTLPProtocol(), "OTLP protocol for --otlp-endpoint: http/protobuf or grpc")
+	flag.StringVar(&metricsAddr, "metrics-addr", "", "Listen address for Prometheus metrics in --daemon mode, e.g. :9090")
+
+	// serve takes the same flags as a scan; browse and validate take the
+	// inventory file to open, merge the files to combine and reconcile the
+	// inventory and asset export to compare.
+	args := os.Args[1:]
+	command := ""
+	if len(args) > 0 && (args[0] == "serve" || args[0] == "browse" || args[0] == "validate" || args[0] == "merge" || args[0] == "reconcile") {
+		command, args = args[0], args[1:]
+	}
+	flag.CommandLine.Parse(args)
+
+	if err := setupLogging(*logLevel, *logFormat); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if command == "browse" {
+		if flag.NArg() != 1 {
+			fatal("Usage: gcp_footprint browse <inventory.json>")
+		}
+		if err := runBrowse(flag.Arg(0)); err != nil {
+			fatal("Browse failed", "error", err)
+		}
+		return
+	}
+	if command == "validate" {
+		if flag.NArg() != 1 {
+			fatal("Usage: gcp_footprint validate <inventory.json|report.ndjson>")
+		}
+		if err := runValidate(os.Stdout, flag.Arg(0)); err != nil {
+			fatal("Validation failed", "error", err)
+		}
+		return
+	}
+	if command == "reconcile" {
+		if flag.NArg() != 2 {
+			fatal("Usage: gcp_footprint reconcile <inventory.json> <asset-export.json|gs://bucket/export>")
+		}
+		ctx := interruptContext()
+		if strings.HasPrefix(flag.Arg(1), "gs://") {
+			if err := configureCredentials(ctx); err != nil {
+				fatal("Failed to configure credentials", "error", err)
+			}
+		}
+		if err := runReconcile(ctx, os.Stdout, flag.Arg(0), flag.Arg(1)); err != nil {
+			fatal("Reconciliation failed", "error", err)
+		}
+		return
+	}
+	if command == "merge" {
+		if flag.NArg() < 2 {
+			fatal("Usage: gcp_footprint merge [--output merged.json] <gcp-inventory.json> <aws-footprint.json>...")
+		}
+		out := os.Stdout
+		if outputPath != "" && outputPath != "-" {
+			f, err := os.Create(outputPath)
+			if err != nil {
+				fatal("Merge failed", "error", err)
+			}
+			out = f
+		}
+		doc, err := runMerge(out, flag.Args())
+		if err == nil && out != os.Stdout {
+			err = out.Close()
+		}
+		if err != nil {
+			fatal("Merge failed", "error", err)
+		}
+		if out != os.Stdout {
+			fmt.Fprintf(console, "Merged %d GCP and %d AWS resources into: %s\n", doc.Totals["gcp"], doc.Totals["aws"], outputPath)
+		}
+		return
+	}
+
+	formats, err := parseFormats(outputFormat)
+	if err != nil {
+		fatal("Invalid --format", "error", err)
+	}
+	outputFormats = formats
+
+	if templatePath != "" {
+		format, err := loadTemplateFormat(templatePath)
+		if err != nil {
+			fatal("Failed to load report template", "template", templatePath, "error", err)
+		}
+		reportFormats["template"] = format
+		outputFormats = []string{"template"}
+	}
+
+	if f := notifyFormat(notifyWebhook, notifyFormatName); f != "json" && f != "slack" {
+		fatal("Unknown notification format", "format", f, "valid", "json, slack")
+	}
+
+	if splitBy != "" && splitBy != "region" && splitBy != "service" {
+		fatal("Unknown --split-by value", "split_by", splitBy, "valid", "region, service")
+	}
+	if splitBy != "" && outputPath == "-" {
+		fatal("--split-by writes a directory and can't be combined with --output=-")
+	}
+
+	if signKey != "" {
+		signReports = true
+	}
+	for _, source := range strings.Split(terraformStateList, ",") {
+		if source = strings.TrimSpace(source); source != "" {
+			terraformStates = append(terraformStates, source)
+		}
+	}
+
+	if outputPath == "-" {
+		if len(outputFormats) > 1 {
+			fatal("--output=- takes a single --format")
+		}
+		console = os.Stderr
+		if uploadDest != "" {
+			fatal("--upload needs a report file, not --output=-")
+		}
+		if signReports {
+			fatal("--sign needs a report file, not --output=-")
+		}
+	}
+
+	if projectList != "" {
+		switch {
+		case projectID != "":
+			fatal("Use either --project or --projects")
+		case daemon || command == "serve":
+			fatal("--daemon and serve scan a single --project")
+		case outputPath != "":
+			fatal("--output names one report; --projects writes one per project")
+		}
+		ctx := interruptContext()
+		if err := configureCredentials(ctx); err != nil {
+			fatal("Failed to configure credentials", "error", err)
+		}
+		failed, err := runProjects(ctx)
+		if err != nil {
+			fatal("Failed to scan projects", "error", err)
+		}
+		if failed > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	if (daemon || command == "serve") && projectID == "" {
+		fatal("--daemon and serve require --project")
+	}
+
+	if !quiet {
+		fmt.Fprintln(console, "GCP Footprint Tool")
+		fmt.Fprintln(console, "==================")
+	}
 
 	// Get project ID from user
+	interactive := projectID == ""
 	reader := bufio.NewReader(os.Stdin)
-	fmt.Print("Enter GCP Project ID: ")
-	projectID, _ = reader.ReadString('\n')
-	projectID = strings.TrimSpace(projectID)
+	if interactive {
+		fmt.Fprint(console, "Enter GCP Project ID: ")
+		projectID, _ = reader.ReadString('\n')
+		projectID = strings.TrimSpace(projectID)
+	}
 
 	// Check for credentials
 	credsFile := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
-	if credsFile == "" {
-		fmt.Println("\nNo GOOGLE_APPLICATION_CREDENTIALS environment variable found.")
-		fmt.Print("Enter path to service account key JSON file (or press Enter to use default credentials): ")
+	if credsFile == "" && interactive {
+		fmt.Fprintln(console, "\nNo GOOGLE_APPLICATION_CREDENTIALS environment variable found.")
+		fmt.Fprint(console, "Enter path to service account key JSON file (or press Enter to use default credentials): ")
 		credsPath, _ := reader.ReadString('\n')
 		credsPath = strings.TrimSpace(credsPath)
 		if credsPath != "" {
@@ -58,112 +508,363 @@ func main() {
 		}
 	}
 
-	// Create output file
-	fileName := fmt.Sprintf("gcp_footprint_%s.txt", projectID)
-	var err error
-	outputFile, err = os.Create(fileName)
+	checkpointFile = fmt.Sprintf("gcp_footprint_%s.state.json", projectID)
+	snapshotFile = fmt.Sprintf("gcp_footprint_%s.last.json", projectID)
+
+	ctx := interruptContext()
+
+	if err := configureCredentials(ctx); err != nil {
+		fatal("Failed to configure credentials", "error", err)
+	}
+	if tracingEnabled() {
+		if err := setupTracing(ctx); err != nil {
+			fatal("Failed to set up tracing", "error", err)
+		}
+	}
+	if resultsStoreURL != "" {
+		store, err := openResultsStore(resultsStoreURL)
+		if err != nil {
+			fatal("Invalid --results-store", "error", err)
+		}
+		results = store
+	}
+
+	if expandGroups {
+		globalCollectors = append(globalCollectors, groupsCollector)
+	}
+	if sccFindings {
+		globalCollectors = append(globalCollectors, sccCollector)
+	}
+	if gkeWorkloads {
+		for i, c := range regionalCollectors {
+			if c.name == "GKE clusters" {
+				// Workloads change without the cluster asset changing.
+				regionalCollectors[i].permissions = append(slices.Clone(c.permissions), gkeWorkloadPermissions...)
+				regionalCollectors[i].assetType = ""
+			}
+		}
+	}
+
+	if pluginDir != "" {
+		if err := loadPlugins(ctx, pluginDir); err != nil {
+			fatal("Failed to load collector plugins", "error", err)
+		}
+	}
+
+	if dryRun {
+		writeDryRun(os.Stdout)
+		return
+	}
+
+	if command == "serve" {
+		err := runServer(ctx, listenAddr)
+		shutdownTracing()
+		if err != nil {
+			fatal("Server failed", "error", err)
+		}
+		return
+	}
+	if daemon {
+		if metricsAddr != "" {
+			go serveMetrics(metricsAddr)
+		}
+		runDaemon(ctx, runScan)
+		shutdownTracing()
+		return
+	}
+	err = runScan(ctx)
+	shutdownTracing()
+	if err != nil {
+		fatal("Scan failed", "error", err)
+	}
+}
+
+// runScan performs one complete scan of projectID and writes, exports and
+// uploads its report. State left over from a previous scan in the same
+// process is reset first.
+func runScan(ctx context.Context) (err error) {
+	start := time.Now()
+	defer func() { scanMetrics.record(start, err) }()
+	ctx, span := startScanSpan(ctx)
+	defer func() { endScanSpan(span, err) }()
+
+	scanErrors.Store(0)
+	inventory = nil
+	completed = map[string]bool{}
+	checkpointed = -1
+	deniedCollectors = map[string]bool{}
+	currentSection, currentCollector = "", ""
+
+	// Reports are named after the project plus each format's extension
+	// unless --output names them. Split reports are all written from the
+	// inventory at the end, into a directory.
+	baseName := outputPath
+	if baseName == "" {
+		baseName = fmt.Sprintf("gcp_footprint_%s", projectID)
+	}
+	scanSinks, err = newSinks(baseName)
 	if err != nil {
-		log.Fatalf("Failed to create output file: %v", err)
+		return fmt.Errorf("create output file: %w", err)
 	}
-	defer outputFile.Close()
 
+	scanTime = time.Now()
+	if resume {
+		if err := loadCheckpoint(); err != nil {
+			fatal("Failed to resume scan", "state_file", checkpointFile, "error", err)
+		}
+	} else if incremental {
+		if err := planIncrementalScan(ctx); err != nil {
+			slog.Warn("Incremental scan unavailable, running a full scan", "error", err)
+		}
+	}
 	writeHeader()
+	replayInventory()
+
+	// Exports below still run with ctx after a timed-out or interrupted
+	// scan, so only the collectors get the scan deadline and the signal.
+	scanCtx := ctx
+	ctx = context.WithoutCancel(ctx)
+	if scanTimeout > 0 {
+		var cancel context.CancelFunc
+		scanCtx, cancel = context.WithTimeout(scanCtx, scanTimeout)
+		defer cancel()
+	}
 
-	ctx := context.Background()
-
-	// Get project information
-	getProjectInfo(ctx)
+	if !skipPreflight {
+		checkPermissions(scanCtx)
+	}
 
-	// Global resources
-	fmt.Println("\nQuerying global resources...")
-	writeSection("GLOBAL RESOURCES")
+	scanProgress = newProgress(len(globalCollectors)+len(regions)*len(regionalCollectors), quiet)
 
-	getStorageBuckets(ctx)
-	getIAMRoles(ctx)
-	getServiceAccounts(ctx)
+	section := ""
+	for _, c := range globalCollectors {
+		if scanCtx.Err() != nil {
+			break
+		}
+		if c.section != "" {
+			section = c.section
+		}
+		runCollector(scanCtx, section, c, "")
+	}
 
-	// Regional resources
-	fmt.Println("\nQuerying regional resources...")
 	for _, region := range regions {
-		fmt.Printf("\nChecking region: %s\n", region)
-		writeSection(fmt.Sprintf("REGION: %s", region))
+		for _, c := range regionalCollectors {
+			if scanCtx.Err() != nil {
+				break
+			}
+			runCollector(scanCtx, fmt.Sprintf("REGION: %s", region), c, region)
+		}
+	}
+	scanProgress.finish()
 
-		getComputeInstances(ctx, region)
-		getGKEClusters(ctx, region)
-		getCloudSQLInstances(ctx, region)
-		getVPCs(ctx, region)
-		getSubnets(ctx, region)
-		getDisks(ctx, region)
+	if gkeWorkloads {
+		linkKubernetesExposure()
 	}
 
-	// Global resources that should only be queried once
-	writeSection("GLOBAL FIREWALL RULES")
-	getFirewallRules(ctx)
-	
-	writeSection("GLOBAL SNAPSHOTS")
-	getSnapshots(ctx)
+	if estimateCosts || findIdle {
+		estimator, err := newCostEstimator(ctx)
+		if err != nil {
+			slog.Error("Failed to load prices", "error", err)
+		}
+		if estimateCosts && estimator != nil {
+			runCostEstimate(ctx, estimator)
+		}
+		if findIdle {
+			if err := runIdleAnalysis(ctx, estimator); err != nil {
+				slog.Error("Failed to find idle resources", "error", err)
+			}
+		}
+	}
 
-	fmt.Printf("\n\nGCP footprint saved to: %s\n", fileName)
+	if attackSurface {
+		runAttackSurface()
+	}
+	if cmekAudit {
+		runCMEKAudit()
+	}
+	if len(terraformStates) > 0 {
+		if err := runTerraformAudit(ctx); err != nil {
+			slog.Error("Failed to compare with Terraform state", "error", err)
+		}
+	}
+	if dataResidency {
+		if err := runDataResidency(ctx); err != nil {
+			slog.Error("Failed to summarize data residency", "error", err)
+		}
+	}
+
+	if scanCtx.Err() != nil {
+		warnIncomplete(scanCtx)
+	}
+	// The previous snapshot is the baseline the summary and notification
+	// diff against, so read it before this scan replaces it.
+	var previous *scanState
+	if results != nil {
+		if states, err := latestResults(ctx, 1); err != nil {
+			slog.Warn("Failed to load the previous scan from the results store", "error", err)
+		} else if len(states) > 0 {
+			previous = &states[0]
+		}
+	} else if state, err := readState(snapshotFile); err == nil {
+		previous = &state
+	}
+	// Split reports each cover part of the inventory, so only whole
+	// reports get the executive summary.
+	currentSummary = nil
+	if splitBy == "" {
+		currentSummary = buildExecutiveSummary(inventory, previous)
+	}
+	if scanCtx.Err() == nil {
+		removeCheckpoint()
+		saveSnapshot()
+		if results != nil {
+			storeResults(ctx, finishedScan())
+		}
+	}
+	writeScanMetadata(ctx, start, scanCtx.Err() != nil)
+
+	sinkErr := finishSinks(ctx, inventory)
+
+	if notifyWebhook != "" {
+		format := notifyFormat(notifyWebhook, notifyFormatName)
+		if err := notifyScan(ctx, notifyWebhook, format, reportName(baseName), previous, scanCtx.Err() != nil); err != nil {
+			slog.Error("Failed to send scan notification", "error", err)
+		}
+	}
+	return sinkErr
+}
+
+// runCollector runs c under its own deadline so that a single hung API call
+// can't block the rest of the scan, starting section first if the report
+// isn't already in it. Collectors finished in a resumed scan are skipped.
+func runCollector(ctx context.Context, section string, c collector, region string) {
+	key := checkpointKey(c, region)
+	currentCollector = key
+	if completed[key] {
+		scanProgress.begin(region, c.name)
+		scanProgress.end()
+		return
+	}
+	if section != currentSection {
+		writeSection(section)
+	}
+
+	if collectorTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, collectorTimeout)
+		defer cancel()
+	}
+
+	scanProgress.begin(region, c.name)
+	defer scanProgress.end()
+	if deniedCollectors[c.name] {
+		return
+	}
+	ctx, span := startCollectorSpan(ctx, c, region)
+	found, errs := len(inventory), scanErrors.Load()
+	c.run(ctx, region)
+	endCollectorSpan(ctx, span, len(inventory)-found, scanErrors.Load()-errs)
+
+	// A collector cut short isn't checkpointed, so --resume runs it again.
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		slog.Warn("Collector timed out", "collector", c.name, "region", region)
+		return
+	case ctx.Err() != nil:
+		return
+	}
+	saveCheckpoint(key)
 }
 
 func writeHeader() {
-	header := fmt.Sprintf(`GCP FOOTPRINT REPORT
+	_, err := io.WriteString(report, reportHeader())
+	if err != nil {
+		slog.Error("Failed to write header", "error", err)
+	}
+}
+
+func reportHeader() string {
+	return fmt.Sprintf(`GCP FOOTPRINT REPORT
 ====================
 Generated: %s
 Project ID: %s
 
 This report contains information about GCP resources in your project.
-`, time.Now().Format("2006-01-02 15:04:05"), projectID)
-
-	_, err := outputFile.WriteString(header)
-	if err != nil {
-		log.Printf("Failed to write header: %v", err)
-	}
+`, scanTime.Format("2006-01-02 15:04:05"), projectID)
 }
 
 func writeSection(title string) {
-	section := fmt.Sprintf("\n\n%s\n%s\n", title, strings.Repeat("=", len(title)))
-	_, err := outputFile.WriteString(section)
+	currentSection = title
+	_, err := io.WriteString(report, sectionHeading(title))
 	if err != nil {
-		log.Printf("Failed to write section: %v", err)
+		slog.Error("Failed to write section", "error", err)
 	}
 }
 
+func sectionHeading(title string) string {
+	return fmt.Sprintf("\n\n%s\n%s\n", title, strings.Repeat("=", len(title)))
+}
+
 func writeResource(resourceType, info string) {
-	_, err := outputFile.WriteString(fmt.Sprintf("\n[%s]\n%s\n", resourceType, info))
+	writeResourceRaw(resourceType, info, nil)
+}
+
+// writeResourceRaw is writeResource for a resource built from one API
+// object, which is kept on its inventory row with --include-raw.
+func writeResourceRaw(resourceType, info string, raw any) {
+	var data json.RawMessage
+	if includeRaw && raw != nil {
+		var err error
+		if data, err = json.Marshal(raw); err != nil {
+			slog.Warn("Failed to encode API response", "resource_type", resourceType, "error", err)
+		}
+	}
+	if redactPII {
+		info = redact(info)
+		if data != nil {
+			data = json.RawMessage(redact(string(data)))
+		}
+	}
+	streamResource(recordResource(resourceType, info, data))
+	_, err := fmt.Fprintf(report, "\n[%s]\n%s\n", resourceType, info)
 	if err != nil {
-		log.Printf("Failed to write resource: %v", err)
+		slog.Error("Failed to write resource", "error", err)
 	}
 }
 
 func getProjectInfo(ctx context.Context) {
-	writeSection("PROJECT INFORMATION")
-
-	crmService, err := cloudresourcemanager.NewService(ctx)
+	crmService, err := cloudresourcemanager.NewService(ctx, clientOptions...)
 	if err != nil {
-		log.Printf("Failed to create Cloud Resource Manager service: %v", err)
+		slog.Error("Failed to create Cloud Resource Manager service", "error", err)
 		return
 	}
 
-	project, err := crmService.Projects.Get(projectID).Do()
+	project, err := crmService.Projects.Get(projectID).Context(ctx).Do()
 	if err != nil {
-		log.Printf("Failed to get project info: %v", err)
+		slog.Error("Failed to get project info", "error", err)
 		return
 	}
 
 	info := fmt.Sprintf("Name: %s\nProject ID: %s\nProject Number: %d\nState: %s\nCreate Time: %s",
 		project.Name, project.ProjectId, project.ProjectNumber, project.LifecycleState, project.CreateTime)
-	writeResource("Project", info)
+	writeResourceRaw("Project", info, project)
 }
 
 func getStorageBuckets(ctx context.Context) {
-	client, err := storage.NewClient(ctx)
+	client, err := storage.NewClient(ctx, clientOptions...)
 	if err != nil {
-		log.Printf("Failed to create storage client: %v", err)
+		slog.Error("Failed to create storage client", "error", err)
 		return
 	}
 	defer client.Close()
 
+	var usage map[string]bucketUsage
+	if bucketMetrics {
+		if usage, err = getBucketUsage(ctx); err != nil {
+			slog.Error("Failed to get bucket metrics", "error", err)
+		}
+	}
+
 	it := client.Buckets(ctx, projectID)
 	count := 0
 	for {
@@ -172,262 +873,510 @@ func getStorageBuckets(ctx context.Context) {
 			break
 		}
 		if err != nil {
-			log.Printf("Failed to list buckets: %v", err)
+			slog.Error("Failed to list buckets", "error", err)
 			break
 		}
 
-		info := fmt.Sprintf("Name: %s\nLocation: %s\nStorage Class: %s\nCreated: %s",
-			bucketAttrs.Name, bucketAttrs.Location, bucketAttrs.StorageClass, bucketAttrs.Created.Format(time.RFC3339))
-		writeResource("Storage Bucket", info)
+		info := fmt.Sprintf("Name: %s\nLocation: %s\nStorage Class: %s\nCreated: %s\n%s",
+			bucketAttrs.Name, bucketAttrs.Location, bucketAttrs.StorageClass, bucketAttrs.Created.Format(time.RFC3339),
+			bucketDetails(bucketAttrs))
+		info += "\nPublic Access: " + bucketPublicAccess(ctx, client, bucketAttrs.Name)
+		if u, ok := usage[bucketAttrs.Name]; ok {
+			info += fmt.Sprintf("\nStored Bytes: %.0f\nObject Count: %d", u.bytes, u.objects)
+		}
+		writeResourceRaw("Storage Bucket", info, bucketAttrs)
 		count++
 	}
-	fmt.Printf("Found %d storage buckets\n", count)
+	scanProgress.found(count)
+}
+
+// bucketPublicAccess returns the roles a bucket grants to allUsers or
+// allAuthenticatedUsers, e.g. "allUsers: roles/storage.objectViewer".
+func bucketPublicAccess(ctx context.Context, client *storage.Client, bucket string) string {
+	policy, err := client.Bucket(bucket).IAM().Policy(ctx)
+	if err != nil {
+		slog.Error("Failed to get bucket IAM policy", "bucket", bucket, "error", err)
+		return ""
+	}
+	var grants []string
+	for _, role := range policy.Roles() {
+		for _, member := range policy.Members(role) {
+			if member == "allUsers" || member == "allAuthenticatedUsers" {
+				grants = append(grants, fmt.Sprintf("%s: %s", member, role))
+			}
+		}
+	}
+	sort.Strings(grants)
+	return strings.Join(grants, ", ")
+}
+
+// bucketDetails reports a bucket's data protection and access settings:
+// versioning, lifecycle rules, retention policy, uniform bucket-level
+// access, public access prevention, default KMS key and access logging.
+func bucketDetails(attrs *storage.BucketAttrs) string {
+	var rules []string
+	for _, rule := range attrs.Lifecycle.Rules {
+		rules = append(rules, lifecycleRule(rule))
+	}
+
+	retention, retentionLocked := "", false
+	if p := attrs.RetentionPolicy; p != nil {
+		retention = fmt.Sprintf("%d days", int(p.RetentionPeriod.Hours()/24))
+		retentionLocked = p.IsLocked
+	}
+	kmsKey := ""
+	if attrs.Encryption != nil {
+		kmsKey = attrs.Encryption.DefaultKMSKeyName
+	}
+	logging := ""
+	if attrs.Logging != nil && attrs.Logging.LogBucket != "" {
+		logging = attrs.Logging.LogBucket
+		if attrs.Logging.LogObjectPrefix != "" {
+			logging += "/" + attrs.Logging.LogObjectPrefix
+		}
+	}
+
+	return fmt.Sprintf("Versioning: %t\nLifecycle Rules: %s\nRetention Period: %s\nRetention Locked: %t\nUniform Bucket-Level Access: %t\nPublic Access Prevention: %s\nKMS Key: %s\nAccess Logging: %s",
+		attrs.VersioningEnabled, strings.Join(rules, "; "), retention, retentionLocked,
+		attrs.UniformBucketLevelAccess.Enabled, attrs.PublicAccessPrevention, kmsKey, logging)
+}
+
+// lifecycleRule describes a lifecycle rule as its action and conditions,
+// e.g. "SetStorageClass COLDLINE if age >= 90d".
+func lifecycleRule(rule storage.LifecycleRule) string {
+	action := rule.Action.Type
+	if rule.Action.StorageClass != "" {
+		action += " " + rule.Action.StorageClass
+	}
+	var conditions []string
+	c := rule.Condition
+	if c.AgeInDays > 0 {
+		conditions = append(conditions, fmt.Sprintf("age >= %dd", c.AgeInDays))
+	}
+	if !c.CreatedBefore.IsZero() {
+		conditions = append(conditions, "created before "+c.CreatedBefore.Format(time.DateOnly))
+	}
+	switch c.Liveness {
+	case storage.Live:
+		conditions = append(conditions, "live")
+	case storage.Archived:
+		conditions = append(conditions, "noncurrent")
+	}
+	if c.NumNewerVersions > 0 {
+		conditions = append(conditions, fmt.Sprintf("%d newer versions", c.NumNewerVersions))
+	}
+	if c.DaysSinceNoncurrentTime > 0 {
+		conditions = append(conditions, fmt.Sprintf("noncurrent for %dd", c.DaysSinceNoncurrentTime))
+	}
+	if len(c.MatchesStorageClasses) > 0 {
+		conditions = append(conditions, "class "+strings.Join(c.MatchesStorageClasses, "/"))
+	}
+	if len(conditions) == 0 {
+		return action
+	}
+	return action + " if " + strings.Join(conditions, ", ")
 }
 
 func getIAMRoles(ctx context.Context) {
-	crmService, err := cloudresourcemanager.NewService(ctx)
+	crmService, err := cloudresourcemanager.NewService(ctx, clientOptions...)
 	if err != nil {
-		log.Printf("Failed to create Cloud Resource Manager service: %v", err)
+		slog.Error("Failed to create Cloud Resource Manager service", "error", err)
 		return
 	}
 
-	policy, err := crmService.Projects.GetIamPolicy(projectID, &cloudresourcemanager.GetIamPolicyRequest{}).Do()
+	// Version 3 is needed for conditional bindings to be returned with
+	// their conditions.
+	policy, err := crmService.Projects.GetIamPolicy(projectID, &cloudresourcemanager.GetIamPolicyRequest{
+		Options: &cloudresourcemanager.GetPolicyOptions{RequestedPolicyVersion: 3},
+	}).Context(ctx).Do()
 	if err != nil {
-		log.Printf("Failed to get IAM policy: %v", err)
+		slog.Error("Failed to get IAM policy", "error", err)
 		return
 	}
 
 	for _, binding := range policy.Bindings {
 		info := fmt.Sprintf("Role: %s\nMembers: %s", binding.Role, strings.Join(binding.Members, ", "))
-		writeResource("IAM Binding", info)
+		if c := binding.Condition; c != nil {
+			info += fmt.Sprintf("\nCondition Title: %s\nCondition: %s\nCondition Description: %s",
+				oneLine(c.Title), oneLine(c.Expression), oneLine(c.Description))
+		}
+		writeResourceRaw("IAM Binding", info, binding)
 	}
-	fmt.Printf("Found %d IAM bindings\n", len(policy.Bindings))
+
+	// Audit configs record which services have data access logging on.
+	for _, config := range policy.AuditConfigs {
+		var logTypes, exempted []string
+		for _, logConfig := range config.AuditLogConfigs {
+			logTypes = append(logTypes, logConfig.LogType)
+			for _, member := range logConfig.ExemptedMembers {
+				exempted = append(exempted, logConfig.LogType+": "+member)
+			}
+		}
+		info := fmt.Sprintf("Service: %s\nLog Types: %s\nExempted Members: %s",
+			config.Service, strings.Join(logTypes, ", "), strings.Join(exempted, ", "))
+		writeResourceRaw("Audit Config", info, config)
+	}
+	scanProgress.found(len(policy.Bindings) + len(policy.AuditConfigs))
 }
 
 func getServiceAccounts(ctx context.Context) {
-	iamService, err := iam.NewService(ctx)
+	iamService, err := iam.NewService(ctx, clientOptions...)
 	if err != nil {
-		log.Printf("Failed to create IAM service: %v", err)
+		slog.Error("Failed to create IAM service", "error", err)
 		return
 	}
 
 	parent := fmt.Sprintf("projects/%s", projectID)
-	response, err := iamService.Projects.ServiceAccounts.List(parent).Do()
+	response, err := iamService.Projects.ServiceAccounts.List(parent).Context(ctx).Do()
 	if err != nil {
-		log.Printf("Failed to list service accounts: %v", err)
+		slog.Error("Failed to list service accounts", "error", err)
 		return
 	}
 
 	for _, sa := range response.Accounts {
 		info := fmt.Sprintf("Email: %s\nDisplay Name: %s\nUnique ID: %s",
 			sa.Email, sa.DisplayName, sa.UniqueId)
-		writeResource("Service Account", info)
+		writeResourceRaw("Service Account", info, sa)
 	}
-	fmt.Printf("Found %d service accounts\n", len(response.Accounts))
+	scanProgress.found(len(response.Accounts))
 }
 
-func getComputeInstances(ctx context.Context, zone string) {
-	computeService, err := compute.NewService(ctx)
+func getComputeInstances(ctx context.Context, region string) {
+	computeService, err := newComputeAPI(ctx)
 	if err != nil {
-		log.Printf("Failed to create compute service: %v", err)
+		slog.Error("Failed to create compute service", "error", err)
 		return
 	}
-
-	instances, err := computeService.Instances.List(projectID, zone+"-a").Do()
+	zones, err := regionZones(ctx, region)
 	if err != nil {
-		// Silently skip if zone doesn't exist
+		// Skip regions that don't exist or aren't enabled for this project
+		slog.Debug("Skipping region", "region", region, "error", err)
 		return
 	}
 
-	for _, instance := range instances.Items {
-		info := fmt.Sprintf("Name: %s\nMachine Type: %s\nStatus: %s\nZone: %s\nCreated: %s",
-			instance.Name, instance.MachineType, instance.Status,
-			zone+"-a", instance.CreationTimestamp)
-
-		if len(instance.NetworkInterfaces) > 0 && instance.NetworkInterfaces[0].AccessConfigs != nil &&
-			len(instance.NetworkInterfaces[0].AccessConfigs) > 0 {
-			info += fmt.Sprintf("\nExternal IP: %s", instance.NetworkInterfaces[0].AccessConfigs[0].NatIP)
+	count := 0
+	// OS Login and serial port access can be set project-wide and
+	// overridden per instance.
+	var projectMetadata *compute.Metadata
+	for _, zone := range zones {
+		instances, err := computeService.Instances(ctx, zone)
+		if err != nil {
+			// Skip zones that aren't enabled for this project
+			slog.Debug("Skipping zone", "zone", zone, "error", err)
+			continue
+		}
+		if len(instances) > 0 && projectMetadata == nil {
+			project, err := computeService.Project(ctx)
+			if err != nil {
+				slog.Error("Failed to get project metadata", "error", err)
+			} else {
+				projectMetadata = project.CommonInstanceMetadata
+			}
+		}
+		for _, instance := range instances {
+			writeResourceRaw("Compute Instance", computeInstanceInfo(instance, projectMetadata), instance)
 		}
+		count += len(instances)
+	}
+	scanProgress.found(count)
+}
 
-		writeResource("Compute Instance", info)
+// computeInstanceInfo is an instance's report entry, with the zone the API
+// reports it in.
+func computeInstanceInfo(instance *compute.Instance, projectMetadata *compute.Metadata) string {
+	info := fmt.Sprintf("Name: %s\nMachine Type: %s\nStatus: %s\nZone: %s\nCreated: %s\nLast Stopped: %s",
+		instance.Name, instance.MachineType, instance.Status,
+		path.Base(instance.Zone), instance.CreationTimestamp, instance.LastStopTimestamp)
+
+	if len(instance.NetworkInterfaces) > 0 {
+		info += fmt.Sprintf("\nNetwork: %s\nSubnet: %s",
+			instance.NetworkInterfaces[0].Network, instance.NetworkInterfaces[0].Subnetwork)
 	}
 
-	if len(instances.Items) > 0 {
-		fmt.Printf("  Found %d compute instances in %s\n", len(instances.Items), zone)
+	if len(instance.NetworkInterfaces) > 0 && instance.NetworkInterfaces[0].AccessConfigs != nil &&
+		len(instance.NetworkInterfaces[0].AccessConfigs) > 0 {
+		info += fmt.Sprintf("\nExternal IP: %s", instance.NetworkInterfaces[0].AccessConfigs[0].NatIP)
 	}
+	info += "\n" + instanceSecurityInfo(instance, projectMetadata)
+	info += "\nResource Policies: " + instanceSchedules(instance)
+	info += "\nAccelerators: " + instanceAccelerators(instance)
+	return info
 }
 
 func getGKEClusters(ctx context.Context, location string) {
-	client, err := container.NewClusterManagerClient(ctx)
+	client, err := newGKEAPI(ctx)
 	if err != nil {
-		log.Printf("Failed to create GKE client: %v", err)
+		slog.Error("Failed to create GKE client", "error", err)
 		return
 	}
 	defer client.Close()
 
-	parent := fmt.Sprintf("projects/%s/locations/%s", projectID, location)
-	response, err := client.ListClusters(ctx, &containerpb.ListClustersRequest{
-		Parent: parent,
-	})
+	clusters, err := client.Clusters(ctx, location)
 	if err != nil {
-		// Silently skip if location doesn't have GKE
+		// Skip locations where GKE isn't available
+		slog.Debug("Skipping GKE location", "location", location, "error", err)
 		return
 	}
 
-	for _, cluster := range response.Clusters {
-		info := fmt.Sprintf("Name: %s\nLocation: %s\nMaster Version: %s\nNode Count: %d\nStatus: %s",
+	count := 0
+	for _, cluster := range clusters {
+		info := fmt.Sprintf("Name: %s\nLocation: %s\nMaster Version: %s\nNode Count: %d\nStatus: %s\nEndpoint: %s\n%s",
 			cluster.Name, cluster.Location, cluster.CurrentMasterVersion,
-			cluster.CurrentNodeCount, cluster.Status)
-		writeResource("GKE Cluster", info)
+			cluster.CurrentNodeCount, cluster.Status, cluster.Endpoint, gkeSecurityInfo(cluster))
+		info += "\nNode Pools: " + gkeNodePools(cluster)
+		info += "\nDefault SA Node Pools: " + gkeDefaultSANodePools(cluster)
+		writeResourceRaw("GKE Cluster", info, cluster)
+		count++
+		if gkeWorkloads {
+			count += getGKEWorkloads(ctx, cluster)
+		}
 	}
 
-	if len(response.Clusters) > 0 {
-		fmt.Printf("  Found %d GKE clusters in %s\n", len(response.Clusters), location)
-	}
+	scanProgress.found(count)
 }
 
 func getCloudSQLInstances(ctx context.Context, region string) {
-	sqlService, err := sqladmin.NewService(ctx)
+	sqlService, err := newSQLAPI(ctx)
 	if err != nil {
-		log.Printf("Failed to create Cloud SQL service: %v", err)
+		slog.Error("Failed to create Cloud SQL service", "error", err)
 		return
 	}
 
-	instances, err := sqlService.Instances.List(projectID).Do()
+	instances, err := sqlService.Instances(ctx)
 	if err != nil {
-		log.Printf("Failed to list Cloud SQL instances: %v", err)
+		slog.Error("Failed to list Cloud SQL instances", "region", region, "error", err)
 		return
 	}
 
 	count := 0
-	for _, instance := range instances.Items {
+	for _, instance := range instances {
 		if strings.HasPrefix(instance.Region, region) {
-			info := fmt.Sprintf("Name: %s\nDatabase Version: %s\nTier: %s\nRegion: %s\nState: %s",
+			kmsKey := ""
+			if instance.DiskEncryptionConfiguration != nil {
+				kmsKey = instance.DiskEncryptionConfiguration.KmsKeyName
+			}
+			publicIP := ""
+			for _, ip := range instance.IpAddresses {
+				if ip.Type == "PRIMARY" {
+					publicIP = ip.IpAddress
+				}
+			}
+			var authorized []string
+			if c := instance.Settings.IpConfiguration; c != nil {
+				for _, network := range c.AuthorizedNetworks {
+					authorized = append(authorized, network.Value)
+				}
+			}
+			info := fmt.Sprintf("Name: %s\nDatabase Version: %s\nTier: %s\nRegion: %s\nState: %s\nAvailability: %s\nDisk Size: %d GB\nDisk Type: %s\nKMS Key: %s\nPublic IP: %s\nAuthorized Networks: %s",
 				instance.Name, instance.DatabaseVersion, instance.Settings.Tier,
-				instance.Region, instance.State)
-			writeResource("Cloud SQL Instance", info)
+				instance.Region, instance.State, instance.Settings.AvailabilityType,
+				instance.Settings.DataDiskSizeGb, instance.Settings.DataDiskType, kmsKey,
+				publicIP, strings.Join(authorized, ", "))
+			writeResourceRaw("Cloud SQL Instance", info, instance)
 			count++
+			count += getCloudSQLDatabases(ctx, sqlService, instance)
 		}
 	}
 
-	if count > 0 {
-		fmt.Printf("  Found %d Cloud SQL instances in %s\n", count, region)
-	}
+	scanProgress.found(count)
 }
 
 func getVPCs(ctx context.Context, region string) {
-	computeService, err := compute.NewService(ctx)
+	computeService, err := newComputeAPI(ctx)
 	if err != nil {
-		log.Printf("Failed to create compute service: %v", err)
+		slog.Error("Failed to create compute service", "error", err)
 		return
 	}
 
-	networks, err := computeService.Networks.List(projectID).Do()
+	networks, err := computeService.Networks(ctx)
 	if err != nil {
-		log.Printf("Failed to list VPCs: %v", err)
+		slog.Error("Failed to list VPCs", "error", err)
 		return
 	}
 
 	// VPCs are global, so we'll list them only once
 	if region == regions[0] {
-		for _, network := range networks.Items {
-			info := fmt.Sprintf("Name: %s\nDescription: %s\nAuto Create Subnetworks: %v\nCreated: %s",
-				network.Name, network.Description, network.AutoCreateSubnetworks, network.CreationTimestamp)
-			writeResource("VPC Network", info)
+		for _, network := range networks {
+			info := fmt.Sprintf("Name: %s\nDescription: %s\nAuto Create Subnetworks: %v\nDefault Network: %v\nCreated: %s",
+				network.Name, oneLine(network.Description), network.AutoCreateSubnetworks, network.Name == "default", network.CreationTimestamp)
+			if network.Name == "default" {
+				slog.Warn("Project has the default VPC network", "network", network.Name)
+			}
+
+			if len(network.Peerings) > 0 {
+				var peerings []string
+				for _, peering := range network.Peerings {
+					peerings = append(peerings, fmt.Sprintf("%s=%s (%s)", peering.Name, peering.Network, peering.State))
+				}
+				info += fmt.Sprintf("\nPeerings: %s", strings.Join(peerings, ", "))
+			}
+			writeResourceRaw("VPC Network", info, network)
 		}
-		fmt.Printf("  Found %d VPC networks\n", len(networks.Items))
+		scanProgress.found(len(networks))
 	}
 }
 
 func getSubnets(ctx context.Context, region string) {
-	computeService, err := compute.NewService(ctx)
+	computeService, err := newComputeAPI(ctx)
 	if err != nil {
-		log.Printf("Failed to create compute service: %v", err)
+		slog.Error("Failed to create compute service", "error", err)
 		return
 	}
 
-	subnetworks, err := computeService.Subnetworks.List(projectID, region).Do()
+	subnetworks, err := computeService.Subnetworks(ctx, region)
 	if err != nil {
-		// Silently skip if region doesn't have subnets
+		// Skip regions that don't have subnets
+		slog.Debug("Skipping subnets", "region", region, "error", err)
 		return
 	}
 
-	for _, subnet := range subnetworks.Items {
-		info := fmt.Sprintf("Name: %s\nNetwork: %s\nIP Range: %s\nRegion: %s\nCreated: %s",
-			subnet.Name, subnet.Network, subnet.IpCidrRange, subnet.Region, subnet.CreationTimestamp)
-		writeResource("Subnet", info)
+	for _, subnet := range subnetworks {
+		info := fmt.Sprintf("Name: %s\nNetwork: %s\nIP Range: %s\nRegion: %s\nCreated: %s\n%s",
+			subnet.Name, subnet.Network, subnet.IpCidrRange, path.Base(subnet.Region), subnet.CreationTimestamp,
+			flowLogInfo(subnet))
+		writeResourceRaw("Subnet", info, subnet)
 	}
 
-	if len(subnetworks.Items) > 0 {
-		fmt.Printf("  Found %d subnets in %s\n", len(subnetworks.Items), region)
-	}
+	scanProgress.found(len(subnetworks))
 }
 
 func getFirewallRules(ctx context.Context) {
 
-	computeService, err := compute.NewService(ctx)
+	computeService, err := newComputeAPI(ctx)
 	if err != nil {
-		log.Printf("Failed to create compute service: %v", err)
+		slog.Error("Failed to create compute service", "error", err)
 		return
 	}
 
-	firewalls, err := computeService.Firewalls.List(projectID).Do()
+	firewalls, err := computeService.Firewalls(ctx)
 	if err != nil {
-		log.Printf("Failed to list firewall rules: %v", err)
+		slog.Error("Failed to list firewall rules", "error", err)
 		return
 	}
 
-	for _, firewall := range firewalls.Items {
-		info := fmt.Sprintf("Name: %s\nDirection: %s\nPriority: %d\nSource Ranges: %s\nTarget Tags: %s",
+	for _, firewall := range firewalls {
+		info := fmt.Sprintf("Name: %s\nDirection: %s\nPriority: %d\nSource Ranges: %s\nTarget Tags: %s\nDefault Rule: %v",
 			firewall.Name, firewall.Direction, firewall.Priority,
-			strings.Join(firewall.SourceRanges, ", "), strings.Join(firewall.TargetTags, ", "))
-		writeResource("Firewall Rule", info)
+			strings.Join(firewall.SourceRanges, ", "), strings.Join(firewall.TargetTags, ", "), isDefaultFirewallRule(firewall))
+		writeResourceRaw("Firewall Rule", info, firewall)
 	}
-	fmt.Printf("Found %d firewall rules\n", len(firewalls.Items))
+	scanProgress.found(len(firewalls))
 }
 
-func getDisks(ctx context.Context, zone string) {
-	computeService, err := compute.NewService(ctx)
+func getDisks(ctx context.Context, region string) {
+	computeService, err := newComputeAPI(ctx)
+	if err != nil {
+		slog.Error("Failed to create compute service", "error", err)
+		return
+	}
+	zones, err := regionZones(ctx, region)
 	if err != nil {
-		log.Printf("Failed to create compute service: %v", err)
+		// Skip regions that don't exist or aren't enabled for this project
+		slog.Debug("Skipping region", "region", region, "error", err)
 		return
 	}
 
-	disks, err := computeService.Disks.List(projectID, zone+"-a").Do()
+	count := 0
+	for _, zone := range zones {
+		disks, err := computeService.Disks(ctx, zone)
+		if err != nil {
+			// Skip zones that aren't enabled for this project
+			slog.Debug("Skipping zone", "zone", zone, "error", err)
+			continue
+		}
+
+		for _, disk := range disks {
+			var users []string
+			for _, user := range disk.Users {
+				users = append(users, path.Base(user))
+			}
+			kmsKey := ""
+			if disk.DiskEncryptionKey != nil {
+				kmsKey = kmsKeyName(disk.DiskEncryptionKey.KmsKeyName)
+			}
+			info := fmt.Sprintf("Name: %s\nSize: %d GB\nType: %s\nStatus: %s\nZone: %s\nUsers: %s\nKMS Key: %s\n%s",
+				disk.Name, disk.SizeGb, disk.Type, disk.Status, path.Base(disk.Zone), strings.Join(users, ", "), kmsKey, diskBackupInfo(disk))
+			writeResourceRaw("Persistent Disk", info, disk)
+		}
+		count += len(disks)
+	}
+	scanProgress.found(count)
+}
+
+func getSnapshots(ctx context.Context) {
+
+	computeService, err := newComputeAPI(ctx)
 	if err != nil {
-		// Silently skip if zone doesn't exist
+		slog.Error("Failed to create compute service", "error", err)
 		return
 	}
 
-	for _, disk := range disks.Items {
-		info := fmt.Sprintf("Name: %s\nSize: %d GB\nType: %s\nStatus: %s\nZone: %s",
-			disk.Name, disk.SizeGb, disk.Type, disk.Status, zone+"-a")
-		writeResource("Persistent Disk", info)
+	snapshots, err := computeService.Snapshots(ctx)
+	if err != nil {
+		slog.Error("Failed to list snapshots", "error", err)
+		return
 	}
 
-	if len(disks.Items) > 0 {
-		fmt.Printf("  Found %d persistent disks in %s\n", len(disks.Items), zone)
+	for _, snapshot := range snapshots {
+		info := fmt.Sprintf("Name: %s\nDisk Size: %d GB\nStatus: %s\nCreated: %s\nSource Disk: %s\nStorage Bytes: %d",
+			snapshot.Name, snapshot.DiskSizeGb, snapshot.Status, snapshot.CreationTimestamp,
+			snapshot.SourceDisk, snapshot.StorageBytes)
+		writeResourceRaw("Snapshot", info, snapshot)
 	}
+	scanProgress.found(len(snapshots))
 }
 
-func getSnapshots(ctx context.Context) {
+func getForwardingRules(ctx context.Context, region string) {
+	computeService, err := newComputeAPI(ctx)
+	if err != nil {
+		slog.Error("Failed to create compute service", "error", err)
+		return
+	}
 
-	computeService, err := compute.NewService(ctx)
+	rules, err := computeService.ForwardingRules(ctx, region)
 	if err != nil {
-		log.Printf("Failed to create compute service: %v", err)
+		// Skip regions that don't exist or aren't enabled for this project
+		slog.Debug("Skipping forwarding rules", "region", region, "error", err)
 		return
 	}
 
-	snapshots, err := computeService.Snapshots.List(projectID).Do()
+	for _, rule := range rules {
+		writeResourceRaw("Forwarding Rule", forwardingRuleInfo(rule), rule)
+	}
+
+	scanProgress.found(len(rules))
+}
+
+func getGlobalForwardingRules(ctx context.Context) {
+	computeService, err := newComputeAPI(ctx)
 	if err != nil {
-		log.Printf("Failed to list snapshots: %v", err)
+		slog.Error("Failed to create compute service", "error", err)
 		return
 	}
 
-	for _, snapshot := range snapshots.Items {
-		info := fmt.Sprintf("Name: %s\nDisk Size: %d GB\nStatus: %s\nCreated: %s",
-			snapshot.Name, snapshot.DiskSizeGb, snapshot.Status, snapshot.CreationTimestamp)
-		writeResource("Snapshot", info)
+	rules, err := computeService.GlobalForwardingRules(ctx)
+	if err != nil {
+		slog.Error("Failed to list global forwarding rules", "error", err)
+		return
 	}
-	fmt.Printf("Found %d snapshots\n", len(snapshots.Items))
+
+	for _, rule := range rules {
+		writeResourceRaw("Global Forwarding Rule", forwardingRuleInfo(rule), rule)
+	}
+	scanProgress.found(len(rules))
+}
+
+func forwardingRuleInfo(rule *compute.ForwardingRule) string {
+	ports := rule.PortRange
+	if len(rule.Ports) > 0 {
+		ports = strings.Join(rule.Ports, ", ")
+	}
+	target := rule.Target
+	if target == "" {
+		target = rule.BackendService
+	}
+
+	return fmt.Sprintf("Name: %s\nIP Address: %s\nProtocol: %s\nPorts: %s\nScheme: %s\nTarget: %s\nNetwork: %s\nSubnet: %s\nRegion: %s",
+		rule.Name, rule.IPAddress, rule.IPProtocol, ports, rule.LoadBalancingScheme,
+		target, rule.Network, rule.Subnetwork, selfLinkName(rule.Region))
 }
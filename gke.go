@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"cloud.google.com/go/container/apiv1/containerpb"
+)
+
+// gkeSecurityInfo returns the security posture fields of a GKE cluster
+// report entry: control plane exposure, workload identity, admission and
+// network controls, node hardening and upgrade channel.
+func gkeSecurityInfo(cluster *containerpb.Cluster) string {
+	privateNodes, privateEndpoint := false, false
+	if pc := cluster.PrivateClusterConfig; pc != nil {
+		privateNodes, privateEndpoint = pc.EnablePrivateNodes, pc.EnablePrivateEndpoint
+	}
+
+	authorizedNetworks := "disabled"
+	if man := cluster.MasterAuthorizedNetworksConfig; man != nil && man.Enabled {
+		var cidrs []string
+		for _, block := range man.CidrBlocks {
+			cidrs = append(cidrs, block.CidrBlock)
+		}
+		authorizedNetworks = strings.Join(cidrs, ", ")
+		if authorizedNetworks == "" {
+			authorizedNetworks = "enabled (none allowed)"
+		}
+	}
+
+	workloadPool := ""
+	if wi := cluster.WorkloadIdentityConfig; wi != nil {
+		workloadPool = wi.WorkloadPool
+	}
+
+	binAuthz := "DISABLED"
+	if ba := cluster.BinaryAuthorization; ba != nil {
+		if ba.EvaluationMode != containerpb.BinaryAuthorization_EVALUATION_MODE_UNSPECIFIED {
+			binAuthz = ba.EvaluationMode.String()
+		} else if ba.Enabled {
+			binAuthz = "ENABLED"
+		}
+	}
+
+	// Dataplane V2 enforces network policy without the Calico add-on.
+	networkPolicy := "disabled"
+	if np := cluster.NetworkPolicy; np != nil && np.Enabled {
+		networkPolicy = np.Provider.String()
+	} else if nc := cluster.NetworkConfig; nc != nil && nc.DatapathProvider == containerpb.DatapathProvider_ADVANCED_DATAPATH {
+		networkPolicy = "DATAPLANE_V2"
+	}
+
+	shieldedNodes := cluster.ShieldedNodes != nil && cluster.ShieldedNodes.Enabled
+
+	releaseChannel := "NONE"
+	if rc := cluster.ReleaseChannel; rc != nil && rc.Channel != containerpb.ReleaseChannel_UNSPECIFIED {
+		releaseChannel = rc.Channel.String()
+	}
+
+	return fmt.Sprintf("Private Nodes: %v\nPrivate Endpoint: %v\nMaster Authorized Networks: %s\n"+
+		"Workload Identity Pool: %s\nBinary Authorization: %s\nNetwork Policy: %s\nShielded Nodes: %v\nRelease Channel: %s",
+		privateNodes, privateEndpoint, authorizedNetworks, workloadPool, binAuthz, networkPolicy, shieldedNodes, releaseChannel)
+}
+
+// gkeDefaultSANodePools returns the node pools whose nodes run as the
+// Compute Engine default service account with the cloud-platform scope.
+func gkeDefaultSANodePools(cluster *containerpb.Cluster) string {
+	var pools []string
+	for _, pool := range cluster.NodePools {
+		if pool.Config == nil {
+			continue
+		}
+		sa := pool.Config.ServiceAccount
+		if (sa == "" || isDefaultComputeServiceAccount(sa)) && slices.Contains(pool.Config.OauthScopes, cloudPlatformScope) {
+			pools = append(pools, pool.Name)
+		}
+	}
+	return strings.Join(pools, ", ")
+}
+
+// gkeNodePools summarizes a cluster's node pools as "name (machine-type x
+// nodes)", counting the initial nodes in each of the pool's zones.
+func gkeNodePools(cluster *containerpb.Cluster) string {
+	var pools []string
+	for _, pool := range cluster.NodePools {
+		machineType := ""
+		if pool.Config != nil {
+			machineType = pool.Config.MachineType
+		}
+		nodes := pool.InitialNodeCount * int32(max(1, len(pool.Locations)))
+		pools = append(pools, fmt.Sprintf("%s (%s x %d)", pool.Name, machineType, nodes))
+	}
+	return strings.Join(pools, ", ")
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/container/apiv1/containerpb"
+	"golang.org/x/oauth2"
+	"google.golang.org/api/option"
+	"google.golang.org/api/transport"
+)
+
+var gkeWorkloads bool
+
+// gkeWorkloadPermissions are the IAM permissions --gke-workloads needs on
+// top of listing clusters. GKE authorizes them like Kubernetes RBAC.
+var gkeWorkloadPermissions = []string{
+	"container.namespaces.list",
+	"container.deployments.list",
+	"container.services.list",
+	"container.ingresses.list",
+}
+
+// Minimal Kubernetes API objects, with only the fields that are reported.
+type kubeMeta struct {
+	Name              string            `json:"name"`
+	Namespace         string            `json:"namespace"`
+	CreationTimestamp string            `json:"creationTimestamp"`
+	Annotations       map[string]string `json:"annotations"`
+}
+
+type kubeLoadBalancer struct {
+	Ingress []struct {
+		IP       string `json:"ip"`
+		Hostname string `json:"hostname"`
+	} `json:"ingress"`
+}
+
+func (lb kubeLoadBalancer) addresses() string {
+	var addrs []string
+	for _, in := range lb.Ingress {
+		addrs = append(addrs, in.IP+in.Hostname)
+	}
+	return strings.Join(addrs, ", ")
+}
+
+type kubeNamespace struct {
+	Metadata kubeMeta `json:"metadata"`
+	Status   struct {
+		Phase string `json:"phase"`
+	} `json:"status"`
+}
+
+type kubeDeployment struct {
+	Metadata kubeMeta `json:"metadata"`
+	Spec     struct {
+		Replicas int32 `json:"replicas"`
+		Template struct {
+			Spec struct {
+				ServiceAccountName string `json:"serviceAccountName"`
+				Containers         []struct {
+					Image string `json:"image"`
+				} `json:"containers"`
+			} `json:"spec"`
+		} `json:"template"`
+	} `json:"spec"`
+	Status struct {
+		ReadyReplicas int32 `json:"readyReplicas"`
+	} `json:"status"`
+}
+
+type kubeService struct {
+	Metadata kubeMeta `json:"metadata"`
+	Spec     struct {
+		Type  string `json:"type"`
+		Ports []struct {
+			Port     int32  `json:"port"`
+			Protocol string `json:"protocol"`
+		} `json:"ports"`
+		LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges"`
+	} `json:"spec"`
+	Status struct {
+		LoadBalancer kubeLoadBalancer `json:"loadBalancer"`
+	} `json:"status"`
+}
+
+type kubeIngress struct {
+	Metadata kubeMeta `json:"metadata"`
+	Spec     struct {
+		IngressClassName string `json:"ingressClassName"`
+		Rules            []struct {
+			Host string `json:"host"`
+		} `json:"rules"`
+	} `json:"spec"`
+	Status struct {
+		LoadBalancer kubeLoadBalancer `json:"loadBalancer"`
+	} `json:"status"`
+}
+
+// getGKEWorkloads connects to a cluster's Kubernetes API with the scan's
+// credentials and reports its namespaces, deployments, LoadBalancer
+// services and ingresses. System namespaces are left out except for their
+// load balancers, since anything exposed matters wherever it runs. Clusters
+// with only a private endpoint can't be reached from outside their VPC and
+// are skipped. It returns the number of resources reported.
+func getGKEWorkloads(ctx context.Context, cluster *containerpb.Cluster) int {
+	kube, err := newKubeClient(ctx, cluster)
+	if err != nil {
+		slog.Error("Failed to connect to GKE cluster", "cluster", cluster.Name, "error", err)
+		return 0
+	}
+	where := fmt.Sprintf("Cluster: %s\nLocation: %s", cluster.Name, cluster.Location)
+	count := 0
+
+	namespaces, err := kubeList[kubeNamespace](ctx, kube, "/api/v1/namespaces")
+	if err != nil {
+		// Private clusters time out here; everything after would too.
+		slog.Warn("Skipping GKE workloads", "cluster", cluster.Name, "error", err)
+		return 0
+	}
+	for _, ns := range namespaces {
+		if isSystemNamespace(ns.Metadata.Name) {
+			continue
+		}
+		info := fmt.Sprintf("Name: %s\n%s\nStatus: %s\nCreated: %s",
+			ns.Metadata.Name, where, ns.Status.Phase, ns.Metadata.CreationTimestamp)
+		writeResourceRaw("Kubernetes Namespace", info, ns)
+		count++
+	}
+
+	deployments, err := kubeList[kubeDeployment](ctx, kube, "/apis/apps/v1/deployments")
+	if err != nil {
+		slog.Error("Failed to list Kubernetes deployments", "cluster", cluster.Name, "error", err)
+	}
+	for _, d := range deployments {
+		if isSystemNamespace(d.Metadata.Namespace) {
+			continue
+		}
+		var images []string
+		for _, c := range d.Spec.Template.Spec.Containers {
+			images = append(images, c.Image)
+		}
+		info := fmt.Sprintf("Name: %s\nNamespace: %s\n%s\nReplicas: %d\nReady: %d\nImages: %s\nService Account: %s\nCreated: %s",
+			d.Metadata.Name, d.Metadata.Namespace, where, d.Spec.Replicas, d.Status.ReadyReplicas,
+			strings.Join(images, ", "), d.Spec.Template.Spec.ServiceAccountName, d.Metadata.CreationTimestamp)
+		writeResourceRaw("Kubernetes Deployment", info, d)
+		count++
+	}
+
+	services, err := kubeList[kubeService](ctx, kube, "/api/v1/services")
+	if err != nil {
+		slog.Error("Failed to list Kubernetes services", "cluster", cluster.Name, "error", err)
+	}
+	for _, svc := range services {
+		if svc.Spec.Type != "LoadBalancer" {
+			continue
+		}
+		var ports []string
+		for _, p := range svc.Spec.Ports {
+			ports = append(ports, fmt.Sprintf("%d/%s", p.Port, p.Protocol))
+		}
+		// GKE makes an internal passthrough load balancer when annotated,
+		// an external one otherwise.
+		scheme := "EXTERNAL"
+		if svc.Metadata.Annotations["networking.gke.io/load-balancer-type"] == "Internal" ||
+			svc.Metadata.Annotations["cloud.google.com/load-balancer-type"] == "Internal" {
+			scheme = "INTERNAL"
+		}
+		info := fmt.Sprintf("Name: %s\nNamespace: %s\n%s\nScheme: %s\nIP Address: %s\nPorts: %s\nSource Ranges: %s\nCreated: %s",
+			svc.Metadata.Name, svc.Metadata.Namespace, where, scheme, svc.Status.LoadBalancer.addresses(),
+			strings.Join(ports, ", "), strings.Join(svc.Spec.LoadBalancerSourceRanges, ", "), svc.Metadata.CreationTimestamp)
+		writeResourceRaw("Kubernetes LoadBalancer Service", info, svc)
+		count++
+	}
+
+	ingresses, err := kubeList[kubeIngress](ctx, kube, "/apis/networking.k8s.io/v1/ingresses")
+	if err != nil {
+		slog.Error("Failed to list Kubernetes ingresses", "cluster", cluster.Name, "error", err)
+	}
+	for _, ing := range ingresses {
+		var hosts []string
+		for _, rule := range ing.Spec.Rules {
+			if rule.Host != "" {
+				hosts = append(hosts, rule.Host)
+			}
+		}
+		class := ing.Spec.IngressClassName
+		if class == "" {
+			class = ing.Metadata.Annotations["kubernetes.io/ingress.class"]
+		}
+		info := fmt.Sprintf("Name: %s\nNamespace: %s\n%s\nClass: %s\nHosts: %s\nIP Address: %s\nStatic IP: %s\nForwarding Rule: %s\nCreated: %s",
+			ing.Metadata.Name, ing.Metadata.Namespace, where, class, strings.Join(hosts, ", "),
+			ing.Status.LoadBalancer.addresses(), ing.Metadata.Annotations["kubernetes.io/ingress.global-static-ip-name"],
+			ing.Metadata.Annotations["ingress.kubernetes.io/forwarding-rule"], ing.Metadata.CreationTimestamp)
+		writeResourceRaw("Kubernetes Ingress", info, ing)
+		count++
+	}
+	return count
+}
+
+func isSystemNamespace(name string) bool {
+	return strings.HasPrefix(name, "kube-") || strings.HasPrefix(name, "gke-") || strings.HasPrefix(name, "gmp-")
+}
+
+type kubeClient struct {
+	client   *http.Client
+	endpoint string
+}
+
+// newKubeClient returns a client for the cluster's control plane that
+// trusts its CA and authenticates with the scan's OAuth token, the same
+// way gcloud's kubeconfig does.
+func newKubeClient(ctx context.Context, cluster *containerpb.Cluster) (*kubeClient, error) {
+	if cluster.Endpoint == "" || cluster.MasterAuth == nil {
+		return nil, fmt.Errorf("cluster has no endpoint")
+	}
+	ca, err := base64.StdEncoding.DecodeString(cluster.MasterAuth.ClusterCaCertificate)
+	if err != nil {
+		return nil, fmt.Errorf("decode cluster CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("cluster CA is not a PEM certificate")
+	}
+	creds, err := transport.Creds(ctx, append(slices.Clone(clientOptions), option.WithScopes(cloudPlatformScope))...)
+	if err != nil {
+		return nil, err
+	}
+	return &kubeClient{
+		client: &http.Client{
+			Timeout: 30 * time.Second,
+			Transport: &oauth2.Transport{
+				Source: creds.TokenSource,
+				Base:   &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}},
+			},
+		},
+		endpoint: "https://" + cluster.Endpoint,
+	}, nil
+}
+
+// kubeList lists every object at a Kubernetes API collection path,
+// following continue tokens.
+func kubeList[T any](ctx context.Context, kube *kubeClient, path string) ([]T, error) {
+	var items []T
+	next := ""
+	for {
+		query := url.Values{"limit": {"500"}}
+		if next != "" {
+			query.Set("continue", next)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, kube.endpoint+path+"?"+query.Encode(), nil)
+		if err != nil {
+			return items, err
+		}
+		resp, err := kube.client.Do(req)
+		if err != nil {
+			return items, err
+		}
+		var page struct {
+			Metadata struct {
+				Continue string `json:"continue"`
+			} `json:"metadata"`
+			Items   []T    `json:"items"`
+			Message string `json:"message"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return items, fmt.Errorf("%s: %s %s", path, resp.Status, page.Message)
+		}
+		if err != nil {
+			return items, fmt.Errorf("%s: %w", path, err)
+		}
+		items = append(items, page.Items...)
+		if page.Metadata.Continue == "" {
+			return items, nil
+		}
+		next = page.Metadata.Continue
+	}
+}
+
+// linkKubernetesExposure matches Kubernetes load balancers and ingresses to
+// the forwarding rules GKE created for them by IP address, naming the rule
+// on the Kubernetes resource and the workload on the rule.
+func linkKubernetesExposure() {
+	rules := map[string]*inventoryRow{}
+	for i := range inventory {
+		row := &inventory[i]
+		if row.ResourceType == "Forwarding Rule" || row.ResourceType == "Global Forwarding Rule" {
+			if ip := row.field("IP Address"); ip != "" {
+				rules[ip] = row
+			}
+		}
+	}
+	for i := range inventory {
+		row := &inventory[i]
+		if row.ResourceType != "Kubernetes LoadBalancer Service" && row.ResourceType != "Kubernetes Ingress" {
+			continue
+		}
+		for _, ip := range splitList(row.field("IP Address")) {
+			rule, ok := rules[ip]
+			if !ok {
+				continue
+			}
+			if row.field("Forwarding Rule") == "" {
+				row.setField("Forwarding Rule", rule.Name)
+			}
+			rule.setField("Kubernetes Workload", fmt.Sprintf("%s %s/%s in %s",
+				strings.TrimPrefix(row.ResourceType, "Kubernetes "), row.field("Namespace"), row.Name, row.field("Cluster")))
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/markyjacksonfishing/gcp_footprint/inventorypb"
+)
+
+var grpcAddr string
+
+// grpcServer serves the serve command's scans over gRPC, as defined in
+// inventorypb/inventory.proto. It shares the HTTP API's server, so both
+// see the same scans.
+type grpcServer struct {
+	inventorypb.UnimplementedFootprintServiceServer
+	srv *server
+}
+
+// serveGRPC starts the gRPC API on addr in the background. It stops
+// accepting calls when ctx is cancelled, after the calls in progress
+// finish.
+func serveGRPC(ctx context.Context, srv *server, addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("listen for gRPC: %w", err)
+	}
+	grpcSrv := grpc.NewServer()
+	inventorypb.RegisterFootprintServiceServer(grpcSrv, &grpcServer{srv: srv})
+
+	go func() {
+		<-ctx.Done()
+		grpcSrv.GracefulStop()
+	}()
+	go func() {
+		slog.Info("Serving gRPC API", "addr", addr, "project", projectID)
+		if err := grpcSrv.Serve(lis); err != nil {
+			slog.Error("gRPC server failed", "error", err)
+		}
+	}()
+	return nil
+}
+
+func (g *grpcServer) StartScan(ctx context.Context, req *inventorypb.StartScanRequest) (*inventorypb.StartScanResponse, error) {
+	if !g.srv.startScan() {
+		return nil, status.Error(codes.FailedPrecondition, "a scan is already running")
+	}
+	return &inventorypb.StartScanResponse{}, nil
+}
+
+func (g *grpcServer) GetInventory(ctx context.Context, req *inventorypb.GetInventoryRequest) (*inventorypb.Inventory, error) {
+	latest := g.srv.latestScan()
+	if latest == nil {
+		return nil, status.Error(codes.NotFound, "no completed scan yet")
+	}
+	inv := &inventorypb.Inventory{
+		SchemaVersion: latest.SchemaVersion,
+		ProjectId:     latest.ProjectID,
+		ScanTime:      timestamppb.New(latest.ScanTime),
+	}
+	for _, row := range latest.Inventory {
+		inv.Resources = append(inv.Resources, protoResource(row))
+	}
+	return inv, nil
+}
+
+func (g *grpcServer) StreamResources(req *inventorypb.StreamResourcesRequest, stream inventorypb.FootprintService_StreamResourcesServer) error {
+	latest := g.srv.latestScan()
+	if latest == nil {
+		return status.Error(codes.NotFound, "no completed scan yet")
+	}
+	for _, row := range latest.Inventory {
+		if req.ResourceType != "" && row.ResourceType != req.ResourceType {
+			continue
+		}
+		if req.Location != "" && row.Location != req.Location {
+			continue
+		}
+		if err := stream.Send(protoResource(row)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// protoResource converts an inventory row to its protobuf message.
+func protoResource(row inventoryRow) *inventorypb.Resource {
+	r := &inventorypb.Resource{
+		ScanTime:     timestamppb.New(row.ScanTime),
+		ProjectId:    row.ProjectID,
+		Section:      row.Section,
+		ResourceType: row.ResourceType,
+		Name:         row.Name,
+		Collector:    row.Collector,
+		Id:           row.ID,
+		AssetType:    row.AssetType,
+		Location:     row.Location,
+		Zone:         row.Zone,
+		Labels:       row.Labels,
+		CreateTime:   row.CreateTime,
+		Raw:          row.Raw,
+	}
+	for _, f := range row.Fields {
+		r.Fields = append(r.Fields, &inventorypb.Field{Key: f.Key, Value: f.Value})
+	}
+	return r
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+
+	"google.golang.org/api/healthcare/v1"
+)
+
+// getHealthcareDatasets lists the region's Cloud Healthcare API datasets
+// with their FHIR, DICOM and HL7v2 stores. These hold patient data, so
+// each store reports where its changes are published or streamed to.
+func getHealthcareDatasets(ctx context.Context, region string) {
+	healthcareService, err := healthcare.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create Cloud Healthcare service", "error", err)
+		return
+	}
+
+	var datasets []*healthcare.Dataset
+	parent := fmt.Sprintf("projects/%s/locations/%s", projectID, region)
+	err = healthcareService.Projects.Locations.Datasets.List(parent).Pages(ctx, func(page *healthcare.ListDatasetsResponse) error {
+		datasets = append(datasets, page.Datasets...)
+		return nil
+	})
+	if err != nil {
+		// Skip regions without the Healthcare API and projects without it enabled
+		slog.Debug("Skipping Cloud Healthcare", "region", region, "error", err)
+		return
+	}
+
+	count := 0
+	stores := healthcareService.Projects.Locations.Datasets
+	for _, dataset := range datasets {
+		info := fmt.Sprintf("Name: %s\nRegion: %s\nTime Zone: %s",
+			path.Base(dataset.Name), region, dataset.TimeZone)
+		writeResourceRaw("Healthcare Dataset", info, dataset)
+		count++
+
+		err := stores.FhirStores.List(dataset.Name).Pages(ctx, func(page *healthcare.ListFhirStoresResponse) error {
+			for _, store := range page.FhirStores {
+				var topics, bigQuery []string
+				for _, n := range store.NotificationConfigs {
+					topics = append(topics, path.Base(n.PubsubTopic))
+				}
+				for _, s := range store.StreamConfigs {
+					if s.BigqueryDestination != nil {
+						bigQuery = append(bigQuery, strings.TrimPrefix(s.BigqueryDestination.DatasetUri, "bq://"))
+					}
+				}
+				info := fmt.Sprintf("Name: %s\nDataset: %s\nRegion: %s\nFHIR Version: %s\nNotification Topics: %s\nBigQuery Streaming: %s\nLabels: %s",
+					path.Base(store.Name), path.Base(dataset.Name), region, store.Version,
+					strings.Join(topics, ", "), strings.Join(bigQuery, ", "), attributeMapping(store.Labels))
+				writeResourceRaw("FHIR Store", info, store)
+				count++
+			}
+			return nil
+		})
+		if err != nil {
+			slog.Error("Failed to list FHIR stores", "dataset", dataset.Name, "error", err)
+		}
+
+		err = stores.DicomStores.List(dataset.Name).Pages(ctx, func(page *healthcare.ListDicomStoresResponse) error {
+			for _, store := range page.DicomStores {
+				topic := ""
+				if store.NotificationConfig != nil {
+					topic = path.Base(store.NotificationConfig.PubsubTopic)
+				}
+				info := fmt.Sprintf("Name: %s\nDataset: %s\nRegion: %s\nNotification Topics: %s\nLabels: %s",
+					path.Base(store.Name), path.Base(dataset.Name), region, topic, attributeMapping(store.Labels))
+				writeResourceRaw("DICOM Store", info, store)
+				count++
+			}
+			return nil
+		})
+		if err != nil {
+			slog.Error("Failed to list DICOM stores", "dataset", dataset.Name, "error", err)
+		}
+
+		err = stores.Hl7V2Stores.List(dataset.Name).Pages(ctx, func(page *healthcare.ListHl7V2StoresResponse) error {
+			for _, store := range page.Hl7V2Stores {
+				parser := ""
+				if store.ParserConfig != nil {
+					parser = store.ParserConfig.Version
+				}
+				var topics []string
+				for _, n := range store.NotificationConfigs {
+					topics = append(topics, path.Base(n.PubsubTopic))
+				}
+				info := fmt.Sprintf("Name: %s\nDataset: %s\nRegion: %s\nParser Version: %s\nReject Duplicates: %t\nNotification Topics: %s\nLabels: %s",
+					path.Base(store.Name), path.Base(dataset.Name), region, parser, store.RejectDuplicateMessage,
+					strings.Join(topics, ", "), attributeMapping(store.Labels))
+				writeResourceRaw("HL7v2 Store", info, store)
+				count++
+			}
+			return nil
+		})
+		if err != nil {
+			slog.Error("Failed to list HL7v2 stores", "dataset", dataset.Name, "error", err)
+		}
+	}
+	scanProgress.found(count)
+}
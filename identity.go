@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"sort"
+	"strings"
+
+	"google.golang.org/api/cloudresourcemanager/v1"
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/iap/v1"
+	"google.golang.org/api/identitytoolkit/v2"
+)
+
+// getIAPBackends lists the backend services that Identity-Aware Proxy
+// protects, with who may reach each one. Access granted on the project's
+// IAP web resource applies to every backend and is listed separately.
+func getIAPBackends(ctx context.Context) {
+	computeService, err := compute.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create compute service", "error", err)
+		return
+	}
+	crmService, err := cloudresourcemanager.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create Cloud Resource Manager service", "error", err)
+		return
+	}
+	iapService, err := iap.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create IAP service", "error", err)
+		return
+	}
+
+	project, err := crmService.Projects.Get(projectID).Context(ctx).Do()
+	if err != nil {
+		slog.Error("Failed to get project", "error", err)
+		return
+	}
+	// IAP resources are addressed by project number.
+	webResource := fmt.Sprintf("projects/%d/iap_web", project.ProjectNumber)
+
+	services, err := computeService.BackendServices.AggregatedList(projectID).Context(ctx).Do()
+	if err != nil {
+		slog.Error("Failed to list backend services", "error", err)
+		return
+	}
+
+	count := 0
+	projectAccess, err := iapAccess(ctx, iapService, webResource)
+	if err != nil {
+		slog.Error("Failed to get IAP policy", "resource", webResource, "error", err)
+	}
+	for scope, scoped := range services.Items {
+		for _, service := range scoped.BackendServices {
+			if service.Iap == nil || !service.Iap.Enabled {
+				continue
+			}
+			resource := webResource + "/compute/services/" + service.Name
+			region := "global"
+			if scope != "global" {
+				region = path.Base(scope)
+				resource = fmt.Sprintf("%s/compute-%s/services/%s", webResource, region, service.Name)
+			}
+			access, err := iapAccess(ctx, iapService, resource)
+			if err != nil {
+				slog.Error("Failed to get IAP policy", "resource", resource, "error", err)
+			}
+			info := fmt.Sprintf("Name: %s\nRegion: %s\nProtocol: %s\nOAuth Client: %s\nAccess: %s\nProject-wide Access: %s",
+				service.Name, region, service.Protocol, service.Iap.Oauth2ClientId, access, projectAccess)
+			writeResourceRaw("IAP Backend Service", info, service)
+			count++
+		}
+	}
+	scanProgress.found(count)
+}
+
+// iapAccess formats an IAP resource's IAM policy as "role: members" groups.
+func iapAccess(ctx context.Context, iapService *iap.Service, resource string) (string, error) {
+	policy, err := iapService.V1.GetIamPolicy(resource, &iap.GetIamPolicyRequest{}).Context(ctx).Do()
+	if err != nil {
+		return "", err
+	}
+	var bindings []string
+	for _, binding := range policy.Bindings {
+		members := strings.Join(binding.Members, ", ")
+		if binding.Condition != nil && binding.Condition.Title != "" {
+			members += " (if " + binding.Condition.Title + ")"
+		}
+		bindings = append(bindings, path.Base(binding.Role)+": "+members)
+	}
+	sort.Strings(bindings)
+	return strings.Join(bindings, "; "), nil
+}
+
+// getIdentityPlatform lists Identity Platform tenants and the identity
+// providers configured on the project and on each tenant.
+func getIdentityPlatform(ctx context.Context) {
+	identityService, err := identitytoolkit.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create Identity Toolkit service", "error", err)
+		return
+	}
+
+	projectName := "projects/" + projectID
+	count := identityProviders(ctx, identityService, projectName, "")
+
+	tenants, err := identityService.Projects.Tenants.List(projectName).Context(ctx).Do()
+	if err != nil {
+		// Skip projects that don't have Identity Platform multi-tenancy
+		slog.Debug("Skipping Identity Platform tenants", "error", err)
+		scanProgress.found(count)
+		return
+	}
+	for _, tenant := range tenants.Tenants {
+		info := fmt.Sprintf("Name: %s\nDisplay Name: %s\nPassword Sign-up: %t\nEmail Link Sign-in: %t",
+			path.Base(tenant.Name), tenant.DisplayName, tenant.AllowPasswordSignup, tenant.EnableEmailLinkSignin)
+		writeResourceRaw("Identity Platform Tenant", info, tenant)
+		count++
+		count += identityProviders(ctx, identityService, tenant.Name, path.Base(tenant.Name))
+	}
+	scanProgress.found(count)
+}
+
+// identityProviders reports the OIDC and SAML providers of the project, or
+// of a tenant when tenant is set, and returns how many it found.
+func identityProviders(ctx context.Context, identityService *identitytoolkit.Service, parent, tenant string) int {
+	count := 0
+	var oidc *identitytoolkit.GoogleCloudIdentitytoolkitAdminV2ListOAuthIdpConfigsResponse
+	var saml *identitytoolkit.GoogleCloudIdentitytoolkitAdminV2ListInboundSamlConfigsResponse
+	var err error
+	if tenant == "" {
+		oidc, err = identityService.Projects.OauthIdpConfigs.List(parent).Context(ctx).Do()
+	} else {
+		oidc, err = identityService.Projects.Tenants.OauthIdpConfigs.List(parent).Context(ctx).Do()
+	}
+	if err != nil {
+		slog.Debug("Skipping Identity Platform OIDC providers", "parent", parent, "error", err)
+	} else {
+		for _, config := range oidc.OauthIdpConfigs {
+			info := fmt.Sprintf("Name: %s\nTenant: %s\nType: OIDC\nDisplay Name: %s\nIssuer: %s\nEnabled: %t",
+				path.Base(config.Name), tenant, config.DisplayName, config.Issuer, config.Enabled)
+			writeResourceRaw("Identity Provider", info, config)
+			count++
+		}
+	}
+
+	if tenant == "" {
+		saml, err = identityService.Projects.InboundSamlConfigs.List(parent).Context(ctx).Do()
+	} else {
+		saml, err = identityService.Projects.Tenants.InboundSamlConfigs.List(parent).Context(ctx).Do()
+	}
+	if err != nil {
+		slog.Debug("Skipping Identity Platform SAML providers", "parent", parent, "error", err)
+	} else {
+		for _, config := range saml.InboundSamlConfigs {
+			issuer := ""
+			if config.IdpConfig != nil {
+				issuer = config.IdpConfig.IdpEntityId
+			}
+			info := fmt.Sprintf("Name: %s\nTenant: %s\nType: SAML\nDisplay Name: %s\nIssuer: %s\nEnabled: %t",
+				path.Base(config.Name), tenant, config.DisplayName, issuer, config.Enabled)
+			writeResourceRaw("Identity Provider", info, config)
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/api/compute/v1"
+)
+
+// idleFinding is a resource that costs money or clutters the project
+// without doing anything.
+type idleFinding struct {
+	row    *inventoryRow
+	reason string
+	waste  float64
+	priced bool
+}
+
+// runIdleAnalysis flags unattached disks, unused reserved IP addresses,
+// instances stopped for more than --idle-days, empty instance groups and
+// snapshots whose source disk is gone. Each gets Idle and Estimated Monthly
+// Waste fields and is listed in an IDLE AND ORPHANED RESOURCES section. e
+// may be nil if prices couldn't be loaded, in which case waste is left out.
+func runIdleAnalysis(ctx context.Context, e *costEstimator) error {
+	disks, err := existingDisks(ctx)
+	if err != nil {
+		return err
+	}
+
+	stoppedBefore := scanTime.AddDate(0, 0, -idleDays)
+	var findings []idleFinding
+	add := func(row *inventoryRow, reason string, waste float64, priced bool) {
+		findings = append(findings, idleFinding{row: row, reason: reason, waste: waste, priced: priced && e != nil})
+	}
+
+	for i := range inventory {
+		row := &inventory[i]
+		switch row.ResourceType {
+		case "Persistent Disk":
+			if row.field("Users") == "" {
+				waste, ok := diskCost(e, *row)
+				add(row, "unattached disk", waste, ok)
+			}
+
+		case "Static IP Address", "Global Static IP Address":
+			if row.field("Status") != "RESERVED" {
+				continue
+			}
+			// Only external addresses are charged while unused.
+			if row.field("Address Type") == "INTERNAL" {
+				add(row, "unused reserved address", 0, true)
+				continue
+			}
+			region := path.Base(row.field("Region"))
+			if region == "." {
+				region = "global"
+			}
+			waste, ok := 0.0, false
+			if e != nil {
+				var p skuPrice
+				if p, ok = e.find(region, func(d string) bool { return strings.HasPrefix(d, "Static Ip Charge") }); ok {
+					waste = p.monthly(1)
+				}
+			}
+			add(row, "unused reserved address", waste, ok)
+
+		case "Compute Instance":
+			stopped, err := time.Parse(time.RFC3339, row.field("Last Stopped"))
+			if row.field("Status") != "TERMINATED" || err != nil || stopped.After(stoppedBefore) {
+				continue
+			}
+			// A stopped instance only costs its disks.
+			waste, ok := 0.0, true
+			for _, disk := range inventory {
+				if disk.ResourceType == "Persistent Disk" && disk.field("Zone") == row.field("Zone") &&
+					slices.Contains(splitList(disk.field("Users")), row.Name) {
+					cost, priced := diskCost(e, disk)
+					waste += cost
+					ok = ok && priced
+				}
+			}
+			add(row, fmt.Sprintf("stopped for %d days", int(scanTime.Sub(stopped).Hours()/24)), waste, ok)
+
+		case "Instance Group":
+			if row.field("Size") == "0" {
+				add(row, "empty instance group", 0, true)
+			}
+
+		case "Snapshot":
+			source := row.field("Source Disk")
+			if source == "" || disks[resourcePath(source)] {
+				continue
+			}
+			waste, ok := 0.0, false
+			if e != nil {
+				// Snapshot storage is priced about the same everywhere, so
+				// use the first scanned region rather than tracking the
+				// snapshot's storage location.
+				var p skuPrice
+				if p, ok = e.find(regions[0], func(d string) bool { return strings.HasPrefix(d, "Storage PD Snapshot") }); ok {
+					bytes, _ := strconv.ParseFloat(row.field("Storage Bytes"), 64)
+					waste = p.monthly(bytes / (1 << 30))
+				}
+			}
+			add(row, "snapshot of deleted disk", waste, ok)
+		}
+	}
+
+	for _, f := range findings {
+		f.row.setField("Idle", f.reason)
+		if f.priced {
+			f.row.setField("Estimated Monthly Waste", fmt.Sprintf("%.2f USD", f.waste))
+		}
+	}
+	writeIdleSection(findings)
+	return nil
+}
+
+// existingDisks returns the paths of every disk in the project, in all
+// zones, so snapshots of disks outside the scanned zones aren't mistaken
+// for orphans.
+func existingDisks(ctx context.Context) (map[string]bool, error) {
+	computeService, err := compute.NewService(ctx, clientOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("create compute service: %w", err)
+	}
+
+	disks := map[string]bool{}
+	err = computeService.Disks.AggregatedList(projectID).Pages(ctx, func(list *compute.DiskAggregatedList) error {
+		for _, scoped := range list.Items {
+			for _, disk := range scoped.Disks {
+				disks[resourcePath(disk.SelfLink)] = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list disks: %w", err)
+	}
+	return disks, nil
+}
+
+func diskCost(e *costEstimator, row inventoryRow) (float64, bool) {
+	if e == nil {
+		return 0, false
+	}
+	size, _ := strconv.ParseFloat(strings.TrimSuffix(row.field("Size"), " GB"), 64)
+	return e.disk(regionOf(row.field("Zone")), path.Base(row.field("Type")), size)
+}
+
+// resourcePath strips the API host and version from a self link, leaving
+// "projects/...", so links from different API versions compare equal.
+func resourcePath(selfLink string) string {
+	if i := strings.Index(selfLink, "projects/"); i >= 0 {
+		return selfLink[i:]
+	}
+	return selfLink
+}
+
+func writeIdleSection(findings []idleFinding) {
+	writeSection("IDLE AND ORPHANED RESOURCES")
+	if len(findings) == 0 {
+		fmt.Fprintln(report, "\nNo idle or orphaned resources found.")
+		return
+	}
+
+	total := 0.0
+	for _, f := range findings {
+		waste := "unknown"
+		if f.priced {
+			waste = fmt.Sprintf("%.2f", f.waste)
+			total += f.waste
+		}
+		fmt.Fprintf(report, "\n%-24s %-40s %-28s %10s", f.row.ResourceType, f.row.Name, f.reason, waste)
+	}
+	fmt.Fprintf(report, "\n\n%d idle or orphaned resources, estimated waste: %.2f USD/month\n", len(findings), total)
+}
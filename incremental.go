@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"google.golang.org/api/cloudasset/v1"
+)
+
+// snapshotFile holds the inventory of the last complete scan, the baseline
+// for --incremental.
+var snapshotFile string
+
+// assetStats summarizes the Cloud Asset Inventory view of one collector.
+type assetStats struct {
+	count   int
+	updated bool
+}
+
+// finishedScan returns the state of the scan that just finished.
+func finishedScan() scanState {
+	state := scanState{
+		ProjectID: projectID,
+		ScanTime:  scanTime,
+		Inventory: inventory,
+	}
+	for key := range completed {
+		state.Completed = append(state.Completed, key)
+	}
+	return state
+}
+
+// saveSnapshot records the finished scan as the baseline for the next
+// incremental run.
+func saveSnapshot() {
+	if err := writeState(snapshotFile, finishedScan()); err != nil {
+		slog.Error("Failed to save scan snapshot", "error", err)
+	}
+}
+
+// planIncrementalScan compares the last scan against Cloud Asset Inventory
+// and carries forward the results of every collector whose resources are
+// unchanged: same number of assets in its location and none updated since
+// the last scan. Those collectors are marked completed so they don't run;
+// everything else, including collectors without an asset type, is
+// re-queried.
+func planIncrementalScan(ctx context.Context) error {
+	prev, err := readState(snapshotFile)
+	if err != nil {
+		return fmt.Errorf("load previous scan: %w", err)
+	}
+
+	stats, err := searchAssets(ctx, prev.ScanTime)
+	if err != nil {
+		return err
+	}
+
+	prevCompleted := map[string]bool{}
+	for _, key := range prev.Completed {
+		prevCompleted[key] = true
+	}
+	// Collectors can report more than their asset type, such as the GKE
+	// collector's workloads, so only the rows of that type are compared
+	// with the asset count.
+	prevCounts := map[string]int{}
+	for _, row := range prev.Inventory {
+		if row.ID == "" {
+			row.normalize()
+		}
+		prevCounts[row.Collector+" "+row.AssetType]++
+	}
+
+	reused := 0
+	reuse := func(c collector, region string) {
+		key := checkpointKey(c, region)
+		if c.assetType == "" || !prevCompleted[key] {
+			return
+		}
+		if st := stats[key]; st.updated || st.count != prevCounts[key+" "+c.assetType] {
+			return
+		}
+		completed[key] = true
+		reused++
+	}
+	for _, c := range globalCollectors {
+		reuse(c, "")
+	}
+	for _, region := range regions {
+		for _, c := range regionalCollectors {
+			reuse(c, region)
+		}
+	}
+
+	for _, row := range prev.Inventory {
+		if completed[row.Collector] {
+			row.ScanTime = scanTime
+			inventory = append(inventory, row)
+		}
+	}
+
+	slog.Info("Incremental scan", "baseline", prev.ScanTime.Format(time.RFC3339),
+		"reused_collectors", reused, "reused_resources", len(inventory))
+	return nil
+}
+
+// searchAssets lists the project's assets of every collector asset type and
+// groups them by the collector key that would have reported them.
+func searchAssets(ctx context.Context, since time.Time) (map[string]assetStats, error) {
+	assetService, err := cloudasset.NewService(ctx, clientOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("create Cloud Asset service: %w", err)
+	}
+
+	var types []string
+	for _, c := range append(append([]collector{}, globalCollectors...), regionalCollectors...) {
+		if c.assetType != "" {
+			types = append(types, c.assetType)
+		}
+	}
+
+	stats := map[string]assetStats{}
+	err = assetService.V1.SearchAllResources("projects/"+projectID).
+		AssetTypes(types...).
+		PageSize(500).
+		Pages(ctx, func(resp *cloudasset.SearchAllResourcesResponse) error {
+			for _, r := range resp.Results {
+				key, ok := assetCollectorKey(r.AssetType, r.Location)
+				if !ok {
+					continue
+				}
+				st := stats[key]
+				st.count++
+				if t, err := time.Parse(time.RFC3339, r.UpdateTime); err == nil && t.After(since) {
+					st.updated = true
+				}
+				stats[key] = st
+			}
+			return nil
+		})
+	if err != nil {
+		return nil, fmt.Errorf("search assets: %w", err)
+	}
+	return stats, nil
+}
+
+// assetCollectorKey maps an asset to the checkpoint key of the collector that
+// lists it. Zonal collectors list only their region's zones, so they get
+// the zonal assets and not the regional ones of the same type, such as
+// regional disks. Assets outside what the collectors cover, such as those in
+// regions that aren't scanned, are ignored.
+func assetCollectorKey(assetType, location string) (string, bool) {
+	for _, c := range globalCollectors {
+		if c.assetType == assetType {
+			return checkpointKey(c, ""), true
+		}
+	}
+	for _, c := range regionalCollectors {
+		if c.assetType != assetType {
+			continue
+		}
+		zonal := regionOf(location) != location
+		for _, region := range regions {
+			if c.zonal == zonal && regionOf(location) == region {
+				return checkpointKey(c, region), true
+			}
+		}
+	}
+	return "", false
+}
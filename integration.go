@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+
+	"google.golang.org/api/connectors/v1"
+)
+
+// applicationIntegration is an Application Integration workflow, as returned
+// by integrations.googleapis.com/v1. Only the fields the report uses are
+// decoded.
+type applicationIntegration struct {
+	Name              string `json:"name"`
+	Description       string `json:"description"`
+	Active            bool   `json:"active"`
+	CreatorEmail      string `json:"creatorEmail"`
+	LastModifierEmail string `json:"lastModifierEmail"`
+	UpdateTime        string `json:"updateTime"`
+}
+
+// getIntegrations lists the region's Integration Connectors connections
+// and Application Integration workflows.
+func getIntegrations(ctx context.Context, region string) {
+	connectorsService, err := connectors.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create Integration Connectors service", "error", err)
+		return
+	}
+
+	count := 0
+	parent := fmt.Sprintf("projects/%s/locations/%s", projectID, region)
+	connections, err := connectorsService.Projects.Locations.Connections.List(parent).Context(ctx).Do()
+	if err != nil {
+		// Skip projects that don't have the Connectors API enabled
+		slog.Debug("Skipping Integration Connectors", "region", region, "error", err)
+	} else {
+		for _, connection := range connections.Connections {
+			state := ""
+			if connection.Status != nil {
+				state = connection.Status.State
+			}
+			// The connector version is a resource path ending in
+			// providers/<provider>/connectors/<connector>/versions/<version>.
+			connector := ""
+			if parts := strings.Split(connection.ConnectorVersion, "/"); len(parts) >= 6 {
+				n := len(parts)
+				connector = fmt.Sprintf("%s/%s v%s", parts[n-5], parts[n-3], parts[n-1])
+			}
+			info := fmt.Sprintf("Name: %s\nRegion: %s\nConnector: %s\nState: %s\nSuspended: %t\nService Account: %s\nCreated: %s",
+				path.Base(connection.Name), region, connector, state, connection.Suspended,
+				connection.ServiceAccount, connection.CreateTime)
+			writeResourceRaw("Integration Connection", info, connection)
+			count++
+		}
+	}
+
+	err = listREST(ctx, fmt.Sprintf("https://integrations.googleapis.com/v1/%s/integrations", parent), func(page *struct {
+		Integrations []applicationIntegration `json:"integrations"`
+	}) error {
+		for _, integration := range page.Integrations {
+			info := fmt.Sprintf("Name: %s\nRegion: %s\nDescription: %s\nActive: %t\nCreator: %s\nLast Modified By: %s\nUpdated: %s",
+				path.Base(integration.Name), region, oneLine(integration.Description), integration.Active,
+				integration.CreatorEmail, integration.LastModifierEmail, integration.UpdateTime)
+			writeResourceRaw("Application Integration", info, integration)
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		// Skip projects that don't have the Application Integration API enabled
+		slog.Debug("Skipping Application Integration", "region", region, "error", err)
+	}
+	scanProgress.found(count)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// inventoryRow is a discovered resource in normalized form. The text report
+// is written as collectors run; exporters that need structure work from the
+// rows recorded alongside it.
+type inventoryRow struct {
+	ScanTime     time.Time        `json:"scan_time"`
+	ProjectID    string           `json:"project_id"`
+	Section      string           `json:"section"`
+	ResourceType string           `json:"resource_type"`
+	Name         string           `json:"name"`
+	Fields       []inventoryField `json:"fields"`
+	Collector    string           `json:"collector"`
+
+	// Normalized attributes, filled in by normalize from the type and
+	// fields so resources can be matched across scans and exports.
+	ID         string            `json:"id"`
+	AssetType  string            `json:"asset_type,omitempty"`
+	Location   string            `json:"location"`
+	Zone       string            `json:"zone,omitempty"`
+	Labels     map[string]string `json:"labels,omitempty"`
+	CreateTime string            `json:"create_time,omitempty"`
+
+	// Raw is the API object the resource was built from, kept with
+	// --include-raw.
+	Raw json.RawMessage `json:"raw,omitempty"`
+}
+
+// inventoryField is one "Key: Value" line of a resource's report entry.
+type inventoryField struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+var (
+	includeRaw bool
+
+	scanTime         time.Time
+	currentSection   string
+	currentCollector string
+	inventory        []inventoryRow
+)
+
+// recordResource adds a resource to the in-memory inventory, parsing the
+// same "Key: Value" lines that make up its text report entry.
+func recordResource(resourceType, info string, raw json.RawMessage) inventoryRow {
+	row := inventoryRow{
+		ScanTime:     scanTime,
+		ProjectID:    projectID,
+		Section:      currentSection,
+		ResourceType: resourceType,
+		Fields:       parseFields(info),
+		Collector:    currentCollector,
+		Raw:          raw,
+	}
+	if len(row.Fields) > 0 {
+		row.Name = row.Fields[0].Value
+	}
+	row.normalize()
+	inventory = append(inventory, row)
+	return row
+}
+
+func parseFields(info string) []inventoryField {
+	var fields []inventoryField
+	for _, line := range strings.Split(info, "\n") {
+		key, value, ok := strings.Cut(line, ": ")
+		if !ok {
+			continue
+		}
+		fields = append(fields, inventoryField{Key: key, Value: value})
+	}
+	return fields
+}
+
+// oneLine collapses whitespace, newlines included, to single spaces, so
+// free text such as a description or CEL expression stays on its own
+// "Key: Value" line of a resource's info.
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// field returns the value of the named field, or "" if the row has none.
+func (r inventoryRow) field(key string) string {
+	for _, f := range r.Fields {
+		if f.Key == key {
+			return f.Value
+		}
+	}
+	return ""
+}
+
+// setField sets the named field, replacing it if the row already has it.
+func (r *inventoryRow) setField(key, value string) {
+	for i, f := range r.Fields {
+		if f.Key == key {
+			r.Fields[i].Value = value
+			return
+		}
+	}
+	r.Fields = append(r.Fields, inventoryField{Key: key, Value: value})
+}
+
+// info reassembles the row's report entry from its fields.
+func (r inventoryRow) info() string {
+	lines := make([]string, len(r.Fields))
+	for i, f := range r.Fields {
+		lines[i] = f.Key + ": " + f.Value
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,5 @@
+package inventorypb
+
+// Regenerate the Go code after changing inventory.proto. Needs protoc with
+// protoc-gen-go v1.33.0 and protoc-gen-go-grpc v1.3.0 on the PATH.
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative inventory.proto
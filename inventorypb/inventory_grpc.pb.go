@@ -0,0 +1,229 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             (unknown)
+// source: inventory.proto
+
+// The inventory model and scanner API served by `gcp_footprint serve
+// --grpc-addr`. Resource mirrors one record of the JSON inventory schema
+// (inventory.schema.json), so fields are only added within v1.
+
+package inventorypb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	FootprintService_StartScan_FullMethodName       = "/gcpfootprint.v1.FootprintService/StartScan"
+	FootprintService_GetInventory_FullMethodName    = "/gcpfootprint.v1.FootprintService/GetInventory"
+	FootprintService_StreamResources_FullMethodName = "/gcpfootprint.v1.FootprintService/StreamResources"
+)
+
+// FootprintServiceClient is the client API for FootprintService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type FootprintServiceClient interface {
+	// StartScan starts a scan in the background. It fails with
+	// FAILED_PRECONDITION if a scan is already running.
+	StartScan(ctx context.Context, in *StartScanRequest, opts ...grpc.CallOption) (*StartScanResponse, error)
+	// GetInventory returns the last complete scan. It fails with NOT_FOUND
+	// before the first scan has finished.
+	GetInventory(ctx context.Context, in *GetInventoryRequest, opts ...grpc.CallOption) (*Inventory, error)
+	// StreamResources sends the resources of the last complete scan one at a
+	// time, optionally filtered, so large inventories needn't fit in one
+	// message.
+	StreamResources(ctx context.Context, in *StreamResourcesRequest, opts ...grpc.CallOption) (FootprintService_StreamResourcesClient, error)
+}
+
+type footprintServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewFootprintServiceClient(cc grpc.ClientConnInterface) FootprintServiceClient {
+	return &footprintServiceClient{cc}
+}
+
+func (c *footprintServiceClient) StartScan(ctx context.Context, in *StartScanRequest, opts ...grpc.CallOption) (*StartScanResponse, error) {
+	out := new(StartScanResponse)
+	err := c.cc.Invoke(ctx, FootprintService_StartScan_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *footprintServiceClient) GetInventory(ctx context.Context, in *GetInventoryRequest, opts ...grpc.CallOption) (*Inventory, error) {
+	out := new(Inventory)
+	err := c.cc.Invoke(ctx, FootprintService_GetInventory_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *footprintServiceClient) StreamResources(ctx context.Context, in *StreamResourcesRequest, opts ...grpc.CallOption) (FootprintService_StreamResourcesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &FootprintService_ServiceDesc.Streams[0], FootprintService_StreamResources_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &footprintServiceStreamResourcesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type FootprintService_StreamResourcesClient interface {
+	Recv() (*Resource, error)
+	grpc.ClientStream
+}
+
+type footprintServiceStreamResourcesClient struct {
+	grpc.ClientStream
+}
+
+func (x *footprintServiceStreamResourcesClient) Recv() (*Resource, error) {
+	m := new(Resource)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// FootprintServiceServer is the server API for FootprintService service.
+// All implementations must embed UnimplementedFootprintServiceServer
+// for forward compatibility
+type FootprintServiceServer interface {
+	// StartScan starts a scan in the background. It fails with
+	// FAILED_PRECONDITION if a scan is already running.
+	StartScan(context.Context, *StartScanRequest) (*StartScanResponse, error)
+	// GetInventory returns the last complete scan. It fails with NOT_FOUND
+	// before the first scan has finished.
+	GetInventory(context.Context, *GetInventoryRequest) (*Inventory, error)
+	// StreamResources sends the resources of the last complete scan one at a
+	// time, optionally filtered, so large inventories needn't fit in one
+	// message.
+	StreamResources(*StreamResourcesRequest, FootprintService_StreamResourcesServer) error
+	mustEmbedUnimplementedFootprintServiceServer()
+}
+
+// UnimplementedFootprintServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedFootprintServiceServer struct {
+}
+
+func (UnimplementedFootprintServiceServer) StartScan(context.Context, *StartScanRequest) (*StartScanResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method StartScan not implemented")
+}
+func (UnimplementedFootprintServiceServer) GetInventory(context.Context, *GetInventoryRequest) (*Inventory, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInventory not implemented")
+}
+func (UnimplementedFootprintServiceServer) StreamResources(*StreamResourcesRequest, FootprintService_StreamResourcesServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamResources not implemented")
+}
+func (UnimplementedFootprintServiceServer) mustEmbedUnimplementedFootprintServiceServer() {}
+
+// UnsafeFootprintServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to FootprintServiceServer will
+// result in compilation errors.
+type UnsafeFootprintServiceServer interface {
+	mustEmbedUnimplementedFootprintServiceServer()
+}
+
+func RegisterFootprintServiceServer(s grpc.ServiceRegistrar, srv FootprintServiceServer) {
+	s.RegisterService(&FootprintService_ServiceDesc, srv)
+}
+
+func _FootprintService_StartScan_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StartScanRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FootprintServiceServer).StartScan(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FootprintService_StartScan_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FootprintServiceServer).StartScan(ctx, req.(*StartScanRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FootprintService_GetInventory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInventoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(FootprintServiceServer).GetInventory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: FootprintService_GetInventory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(FootprintServiceServer).GetInventory(ctx, req.(*GetInventoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _FootprintService_StreamResources_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamResourcesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(FootprintServiceServer).StreamResources(m, &footprintServiceStreamResourcesServer{stream})
+}
+
+type FootprintService_StreamResourcesServer interface {
+	Send(*Resource) error
+	grpc.ServerStream
+}
+
+type footprintServiceStreamResourcesServer struct {
+	grpc.ServerStream
+}
+
+func (x *footprintServiceStreamResourcesServer) Send(m *Resource) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// FootprintService_ServiceDesc is the grpc.ServiceDesc for FootprintService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var FootprintService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "gcpfootprint.v1.FootprintService",
+	HandlerType: (*FootprintServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "StartScan",
+			Handler:    _FootprintService_StartScan_Handler,
+		},
+		{
+			MethodName: "GetInventory",
+			Handler:    _FootprintService_GetInventory_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamResources",
+			Handler:       _FootprintService_StreamResources_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "inventory.proto",
+}
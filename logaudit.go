@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/dns/v1"
+)
+
+// flowLogInfo returns the VPC Flow Logs fields of a subnet report entry.
+func flowLogInfo(subnet *compute.Subnetwork) string {
+	if !flowLogsEnabled(subnet) {
+		return "Flow Logs: false"
+	}
+	return fmt.Sprintf("Flow Logs: true\nFlow Log Sampling: %g\nFlow Log Aggregation: %s",
+		subnet.LogConfig.FlowSampling, subnet.LogConfig.AggregationInterval)
+}
+
+func flowLogsEnabled(subnet *compute.Subnetwork) bool {
+	return subnet.LogConfig != nil && subnet.LogConfig.Enable
+}
+
+// getLoggingAudit reports, per VPC network, whether Cloud DNS query logging
+// is enabled by a DNS server policy and which subnets have no VPC Flow Logs,
+// listing the gaps so they stand out in the report.
+func getLoggingAudit(ctx context.Context) {
+	computeService, err := compute.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create compute service", "error", err)
+		return
+	}
+
+	networks, err := computeService.Networks.List(projectID).Context(ctx).Do()
+	if err != nil {
+		slog.Error("Failed to list VPCs", "error", err)
+		return
+	}
+
+	subnets, err := computeService.Subnetworks.AggregatedList(projectID).Context(ctx).Do()
+	if err != nil {
+		slog.Error("Failed to list subnets", "error", err)
+		return
+	}
+	total := map[string]int{}
+	withoutFlowLogs := map[string][]string{}
+	for _, scoped := range subnets.Items {
+		for _, subnet := range scoped.Subnetworks {
+			network := path.Base(subnet.Network)
+			total[network]++
+			if !flowLogsEnabled(subnet) {
+				withoutFlowLogs[network] = append(withoutFlowLogs[network],
+					path.Base(subnet.Region)+"/"+subnet.Name)
+			}
+		}
+	}
+
+	dnsLogging := map[string]string{}
+	dnsService, err := dns.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create Cloud DNS service", "error", err)
+		return
+	}
+	policies, err := dnsService.Policies.List(projectID).Context(ctx).Do()
+	if err != nil {
+		// Projects without the Cloud DNS API enabled can't have DNS policies,
+		// so every network is reported without DNS logging.
+		slog.Debug("Skipping DNS policies", "error", err)
+	} else {
+		for _, policy := range policies.Policies {
+			if !policy.EnableLogging {
+				continue
+			}
+			for _, network := range policy.Networks {
+				dnsLogging[path.Base(network.NetworkUrl)] = policy.Name
+			}
+		}
+	}
+
+	for _, network := range networks.Items {
+		var gaps []string
+		policy, dnsLogged := dnsLogging[network.Name]
+		if !dnsLogged {
+			gaps = append(gaps, "DNS logging off")
+		}
+		missing := withoutFlowLogs[network.Name]
+		if len(missing) > 0 {
+			gaps = append(gaps, fmt.Sprintf("flow logs off on %d of %d subnets", len(missing), total[network.Name]))
+		}
+
+		info := fmt.Sprintf("Name: %s\nDNS Logging: %v\nDNS Policy: %s\nSubnets With Flow Logs: %d/%d\nSubnets Without Flow Logs: %s\nGaps: %s",
+			network.Name, dnsLogged, policy, total[network.Name]-len(missing), total[network.Name],
+			strings.Join(missing, ", "), strings.Join(gaps, "; "))
+		writeResource("Network Logging", info)
+	}
+	scanProgress.found(len(networks.Items))
+}
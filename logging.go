@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync/atomic"
+)
+
+// scanErrors counts error-level log records in the current scan and
+// totalErrors over the life of the process. Collector API failures are all
+// logged at error level, so these double as API error counts.
+var scanErrors, totalErrors atomic.Int64
+
+// setupLogging installs the default slog logger. Diagnostics always go to
+// stderr so they never mix with a report written to stdout.
+func setupLogging(level, format string) error {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return fmt.Errorf("invalid --log-level %q, expected debug, info, warn or error", level)
+	}
+	opts := &slog.HandlerOptions{Level: lvl}
+
+	var handler slog.Handler
+	switch format {
+	case "text":
+		handler = slog.NewTextHandler(os.Stderr, opts)
+	case "json":
+		handler = slog.NewJSONHandler(os.Stderr, opts)
+	default:
+		return fmt.Errorf("invalid --log-format %q, expected text or json", format)
+	}
+
+	slog.SetDefault(slog.New(errorCounter{handler}))
+	return nil
+}
+
+// errorCounter is a slog.Handler that counts error records before passing
+// them on.
+type errorCounter struct {
+	slog.Handler
+}
+
+func (h errorCounter) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= slog.LevelError {
+		scanErrors.Add(1)
+		totalErrors.Add(1)
+		traceError(r)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h errorCounter) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return errorCounter{h.Handler.WithAttrs(attrs)}
+}
+
+func (h errorCounter) WithGroup(name string) slog.Handler {
+	return errorCounter{h.Handler.WithGroup(name)}
+}
+
+// fatal logs at error level and exits, replacing log.Fatalf.
+func fatal(msg string, args ...any) {
+	slog.Error(msg, args...)
+	os.Exit(1)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// writeMarkdown renders the inventory as GitHub-flavored Markdown: one
+// heading per report section and one table per resource type within it,
+// with a column for every field any resource of that type has.
+func writeMarkdown(w io.Writer, rows []inventoryRow) error {
+	fmt.Fprintf(w, "# GCP Footprint Report\n\n")
+	fmt.Fprintf(w, "- **Project ID:** %s\n- **Generated:** %s\n- **Resources:** %d\n",
+		markdownCell(projectID), scanTime.Format("2006-01-02 15:04:05"), len(rows))
+	if currentSummary != nil {
+		currentSummary.writeMarkdown(w)
+	}
+
+	// Group rows by section, then by type, keeping the order the
+	// collectors reported them in.
+	var sections []string
+	types := map[string][]string{}
+	byType := map[string][]inventoryRow{}
+	for _, row := range rows {
+		if _, ok := types[row.Section]; !ok {
+			sections = append(sections, row.Section)
+			types[row.Section] = nil
+		}
+		key := row.Section + "\x00" + row.ResourceType
+		if _, ok := byType[key]; !ok {
+			types[row.Section] = append(types[row.Section], row.ResourceType)
+		}
+		byType[key] = append(byType[key], row)
+	}
+
+	for _, section := range sections {
+		fmt.Fprintf(w, "\n## %s\n", markdownCell(section))
+		for _, resourceType := range types[section] {
+			typeRows := byType[section+"\x00"+resourceType]
+			fmt.Fprintf(w, "\n### %s (%d)\n\n", markdownCell(resourceType), len(typeRows))
+			writeMarkdownTable(w, typeRows)
+		}
+	}
+	return nil
+}
+
+func writeMarkdownTable(w io.Writer, rows []inventoryRow) {
+	var columns []string
+	seen := map[string]bool{}
+	for _, row := range rows {
+		for _, f := range row.Fields {
+			if !seen[f.Key] {
+				seen[f.Key] = true
+				columns = append(columns, f.Key)
+			}
+		}
+	}
+
+	header := make([]string, len(columns))
+	rule := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = markdownCell(c)
+		rule[i] = "---"
+	}
+	fmt.Fprintf(w, "| %s |\n| %s |\n", strings.Join(header, " | "), strings.Join(rule, " | "))
+
+	for _, row := range rows {
+		cells := make([]string, len(columns))
+		for i, c := range columns {
+			cells[i] = markdownCell(row.field(c))
+		}
+		fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+	}
+}
+
+// markdownCell escapes a value for use inside a table cell.
+func markdownCell(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", "<br>", "\r", "").Replace(s)
+}
@@ -0,0 +1,361 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// multiCloudInventory is the document merge writes: the resources of GCP
+// scans and AWS footprint reports in one normalized list.
+type multiCloudInventory struct {
+	SchemaVersion string           `json:"schema_version"`
+	Generated     time.Time        `json:"generated"`
+	Sources       []mergeSource    `json:"sources"`
+	Totals        map[string]int   `json:"totals"`
+	Resources     []mergedResource `json:"resources"`
+}
+
+// mergeSource is one input file of a merge.
+type mergeSource struct {
+	File      string   `json:"file"`
+	Cloud     string   `json:"cloud"`
+	Accounts  []string `json:"accounts"`
+	Resources int      `json:"resources"`
+}
+
+// mergedResource is a resource of either cloud. Account is the GCP project
+// ID or the AWS account ID, Location the GCP region or AWS region, and
+// Labels the GCP labels or AWS tags.
+type mergedResource struct {
+	Cloud        string            `json:"cloud"`
+	Account      string            `json:"account"`
+	Service      string            `json:"service,omitempty"`
+	ResourceType string            `json:"resource_type"`
+	ID           string            `json:"id"`
+	Name         string            `json:"name"`
+	Location     string            `json:"location"`
+	Zone         string            `json:"zone,omitempty"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	CreateTime   string            `json:"create_time,omitempty"`
+	ScanTime     string            `json:"scan_time,omitempty"`
+}
+
+// runMerge reads GCP inventories and AWS footprint reports from paths and
+// writes them to w as one multiCloudInventory. Which cloud a file is from is
+// told by its content: this tool's files carry a project ID.
+func runMerge(w io.Writer, paths []string) (multiCloudInventory, error) {
+	doc := multiCloudInventory{
+		SchemaVersion: inventorySchemaVersion,
+		Generated:     time.Now().UTC(),
+		Totals:        map[string]int{},
+	}
+	seen := map[string]bool{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return doc, err
+		}
+		var resources []mergedResource
+		source := mergeSource{File: path, Cloud: "gcp"}
+		if isGCPInventory(data) {
+			rows, err := loadInventoryFile(path)
+			if err != nil {
+				return doc, err
+			}
+			for _, row := range withoutScanMetadata(rows) {
+				resources = append(resources, mergeGCPRow(row))
+			}
+		} else {
+			source.Cloud = "aws"
+			records, err := parseAWSReport(data)
+			if err != nil {
+				return doc, fmt.Errorf("parse %s: %w", path, err)
+			}
+			for _, rec := range records {
+				resources = append(resources, mergeAWSRecord(rec))
+			}
+		}
+
+		accounts := map[string]bool{}
+		for _, r := range resources {
+			// The same resource can be in two inputs, e.g. overlapping scans
+			// of a project; the first one read is kept.
+			key := r.Cloud + "\x00" + r.Account + "\x00" + r.ResourceType + "\x00" + r.ID
+			if r.ID != "" && seen[key] {
+				continue
+			}
+			seen[key] = true
+			if r.Account != "" {
+				accounts[r.Account] = true
+			}
+			source.Resources++
+			doc.Totals[r.Cloud]++
+			doc.Resources = append(doc.Resources, r)
+		}
+		source.Accounts = sortedKeys(accounts)
+		doc.Sources = append(doc.Sources, source)
+	}
+
+	sort.SliceStable(doc.Resources, func(i, j int) bool {
+		a, b := doc.Resources[i], doc.Resources[j]
+		for _, c := range [][2]string{{a.Cloud, b.Cloud}, {a.Account, b.Account}, {a.ResourceType, b.ResourceType}, {a.Location, b.Location}} {
+			if c[0] != c[1] {
+				return c[0] < c[1]
+			}
+		}
+		return a.Name < b.Name
+	})
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return doc, enc.Encode(doc)
+}
+
+// isGCPInventory reports whether data is one of this tool's inventory
+// files, a saved scan or a list or stream of resources with a project ID.
+func isGCPInventory(data []byte) bool {
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("[")) {
+		var first []map[string]json.RawMessage
+		if json.Unmarshal(data, &first) != nil || len(first) == 0 {
+			return false
+		}
+		return first[0]["project_id"] != nil && first[0]["collector"] != nil
+	}
+	first, err := firstJSONValue(data)
+	if err != nil {
+		return false
+	}
+	if first["inventory"] != nil && first["project_id"] != nil {
+		return true
+	}
+	return first["project_id"] != nil && first["collector"] != nil
+}
+
+func mergeGCPRow(row inventoryRow) mergedResource {
+	service, _, _ := strings.Cut(row.AssetType, ".googleapis.com/")
+	if service == row.AssetType {
+		service = ""
+	}
+	r := mergedResource{
+		Cloud:        "gcp",
+		Account:      row.ProjectID,
+		Service:      service,
+		ResourceType: row.ResourceType,
+		ID:           row.ID,
+		Name:         row.Name,
+		Location:     row.Location,
+		Zone:         row.Zone,
+		Labels:       row.Labels,
+		CreateTime:   row.CreateTime,
+	}
+	if !row.ScanTime.IsZero() {
+		r.ScanTime = row.ScanTime.UTC().Format(time.RFC3339)
+	}
+	return r
+}
+
+// parseAWSReport reads the resources of an AWS footprint JSON report. It
+// accepts a JSON array or stream of resources, an object holding them
+// under "resources" or "inventory", or an object of resource type to list
+// of resources. Account, region and scan time set on an enclosing object
+// apply to the resources in it that don't have their own.
+func parseAWSReport(data []byte) ([]map[string]any, error) {
+	data = bytes.TrimSpace(data)
+	var values []any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	for dec.More() {
+		var v any
+		if err := dec.Decode(&v); err != nil {
+			return nil, err
+		}
+		values = append(values, v)
+	}
+
+	var records []map[string]any
+	var walk func(v any, inherited map[string]any, resourceType string)
+	walk = func(v any, inherited map[string]any, resourceType string) {
+		switch v := v.(type) {
+		case []any:
+			for _, item := range v {
+				walk(item, inherited, resourceType)
+			}
+		case map[string]any:
+			if awsValue(v, "arn", "resourcearn", "id", "resourceid", "name", "resourcename") != "" {
+				rec := map[string]any{}
+				for k, val := range inherited {
+					rec[k] = val
+				}
+				if resourceType != "" {
+					rec["resource_type"] = resourceType
+				}
+				for k, val := range v {
+					rec[k] = val
+				}
+				records = append(records, rec)
+				return
+			}
+			// A container: its scalar values apply to everything in it,
+			// and each list in it is resources of the type it is keyed by
+			// unless it is a generic list key.
+			scope := map[string]any{}
+			for k, val := range inherited {
+				scope[k] = val
+			}
+			for k, val := range v {
+				switch val.(type) {
+				case []any, map[string]any:
+				default:
+					scope[k] = val
+				}
+			}
+			for _, k := range sortedKeys(v) {
+				switch v[k].(type) {
+				case []any, map[string]any:
+					childType := k
+					switch awsKey(k) {
+					case "resources", "inventory", "items", "regions":
+						childType = resourceType
+					}
+					if regionLike(k) {
+						childScope := map[string]any{"region": k}
+						for sk, sv := range scope {
+							childScope[sk] = sv
+						}
+						walk(v[k], childScope, resourceType)
+						continue
+					}
+					walk(v[k], scope, childType)
+				}
+			}
+		}
+	}
+	for _, v := range values {
+		walk(v, nil, "")
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("no resources found")
+	}
+	return records, nil
+}
+
+// mergeAWSRecord normalizes an AWS resource, taking the service, region and
+// account from its ARN where the record doesn't give them.
+func mergeAWSRecord(rec map[string]any) mergedResource {
+	r := mergedResource{
+		Cloud:        "aws",
+		Account:      awsValue(rec, "accountid", "account", "ownerid", "owner"),
+		Service:      awsValue(rec, "service"),
+		ResourceType: awsValue(rec, "resourcetype", "type"),
+		ID:           awsValue(rec, "arn", "resourcearn", "id", "resourceid"),
+		Name:         awsValue(rec, "name", "resourcename"),
+		Location:     awsValue(rec, "region", "location"),
+		Zone:         awsValue(rec, "availabilityzone", "zone"),
+		Labels:       awsTags(rec),
+		CreateTime:   awsValue(rec, "createtime", "creationdate", "createdate", "launchtime", "createdat"),
+		ScanTime:     awsValue(rec, "scantime", "generated", "timestamp"),
+	}
+	if r.Name == "" {
+		r.Name = r.Labels["Name"]
+	}
+	if r.Name == "" {
+		r.Name = r.ID
+	}
+	// arn:partition:service:region:account:resource
+	if parts := strings.SplitN(r.ID, ":", 6); len(parts) == 6 && parts[0] == "arn" {
+		if r.Service == "" {
+			r.Service = parts[2]
+		}
+		if r.Location == "" {
+			r.Location = parts[3]
+		}
+		if r.Account == "" {
+			r.Account = parts[4]
+		}
+	}
+	if r.Location == "" && r.Zone != "" {
+		r.Location = strings.TrimRight(r.Zone, "abcdefghijklmnopqrstuvwxyz")
+	}
+	if r.Location == "" {
+		r.Location = "global"
+	}
+	if r.ResourceType == "" && r.Service != "" {
+		r.ResourceType = r.Service
+	}
+	return r
+}
+
+// awsKey folds a JSON key so the snake_case, camelCase and PascalCase
+// spellings of a name match.
+func awsKey(k string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "", " ", "").Replace(k))
+}
+
+// awsValue returns the first of names set in rec, as a string.
+func awsValue(rec map[string]any, names ...string) string {
+	for _, name := range names {
+		for k, v := range rec {
+			if awsKey(k) != name || v == nil {
+				continue
+			}
+			switch v := v.(type) {
+			case string:
+				if v != "" {
+					return v
+				}
+			case json.Number:
+				return v.String()
+			case bool:
+				return fmt.Sprint(v)
+			}
+		}
+	}
+	return ""
+}
+
+// awsTags returns a resource's tags, given either as an object or as the
+// AWS API's list of Key and Value pairs.
+func awsTags(rec map[string]any) map[string]string {
+	for k, v := range rec {
+		if awsKey(k) != "tags" && awsKey(k) != "labels" {
+			continue
+		}
+		tags := map[string]string{}
+		switch v := v.(type) {
+		case map[string]any:
+			for key, val := range v {
+				tags[key] = fmt.Sprint(val)
+			}
+		case []any:
+			for _, item := range v {
+				if pair, ok := item.(map[string]any); ok {
+					tags[awsValue(pair, "key")] = awsValue(pair, "value")
+				}
+			}
+		}
+		if len(tags) > 0 {
+			return tags
+		}
+	}
+	return nil
+}
+
+// regionLike reports whether a key names an AWS region, like us-east-1.
+func regionLike(k string) bool {
+	parts := strings.Split(k, "-")
+	if len(parts) < 3 {
+		return false
+	}
+	for _, c := range parts[len(parts)-1] {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return len(parts[0]) == 2
+}
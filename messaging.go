@@ -0,0 +1,177 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+)
+
+// pubSubLiteTopic is a Pub/Sub Lite topic, as returned by the regional
+// pubsublite.googleapis.com/v1 admin API. Only the fields the report uses
+// are decoded.
+type pubSubLiteTopic struct {
+	Name            string `json:"name"`
+	PartitionConfig struct {
+		Count    string `json:"count"`
+		Capacity struct {
+			PublishMibPerSec   int `json:"publishMibPerSec"`
+			SubscribeMibPerSec int `json:"subscribeMibPerSec"`
+		} `json:"capacity"`
+	} `json:"partitionConfig"`
+	RetentionConfig struct {
+		PerPartitionBytes string `json:"perPartitionBytes"`
+		Period            string `json:"period"`
+	} `json:"retentionConfig"`
+	ReservationConfig struct {
+		ThroughputReservation string `json:"throughputReservation"`
+	} `json:"reservationConfig"`
+}
+
+// pubSubLiteSubscription is a Pub/Sub Lite subscription.
+type pubSubLiteSubscription struct {
+	Name           string `json:"name"`
+	Topic          string `json:"topic"`
+	DeliveryConfig struct {
+		DeliveryRequirement string `json:"deliveryRequirement"`
+	} `json:"deliveryConfig"`
+	ExportConfig *struct {
+		DesiredState string `json:"desiredState"`
+		PubsubConfig *struct {
+			Topic string `json:"topic"`
+		} `json:"pubsubConfig"`
+	} `json:"exportConfig"`
+}
+
+// kafkaCluster is a Managed Service for Apache Kafka cluster, as returned
+// by managedkafka.googleapis.com/v1.
+type kafkaCluster struct {
+	Name           string `json:"name"`
+	State          string `json:"state"`
+	CreateTime     string `json:"createTime"`
+	CapacityConfig struct {
+		VcpuCount   int64 `json:"vcpuCount,string"`
+		MemoryBytes int64 `json:"memoryBytes,string"`
+	} `json:"capacityConfig"`
+	GcpConfig struct {
+		KmsKey       string `json:"kmsKey"`
+		AccessConfig struct {
+			NetworkConfigs []struct {
+				Subnet string `json:"subnet"`
+			} `json:"networkConfigs"`
+		} `json:"accessConfig"`
+	} `json:"gcpConfig"`
+}
+
+// kafkaTopic is a topic in a Managed Kafka cluster.
+type kafkaTopic struct {
+	Name              string `json:"name"`
+	PartitionCount    int    `json:"partitionCount"`
+	ReplicationFactor int    `json:"replicationFactor"`
+}
+
+// getPubSubLite lists the region's Pub/Sub Lite topics and subscriptions.
+// Lite resources are either regional or zonal, so the region and each of
+// its zones are listed.
+func getPubSubLite(ctx context.Context, region string) {
+	zones, err := regionZones(ctx, region)
+	if err != nil {
+		// Skip regions that don't exist or aren't enabled for this project
+		slog.Debug("Skipping region", "region", region, "error", err)
+		return
+	}
+
+	count := 0
+	for _, location := range append([]string{region}, zones...) {
+		base := fmt.Sprintf("https://%s-pubsublite.googleapis.com/v1/admin/projects/%s/locations/%s", region, projectID, location)
+		err := listREST(ctx, base+"/topics", func(page *struct {
+			Topics []pubSubLiteTopic `json:"topics"`
+		}) error {
+			for _, topic := range page.Topics {
+				capacity := fmt.Sprintf("%d MiB/s publish, %d MiB/s subscribe",
+					topic.PartitionConfig.Capacity.PublishMibPerSec, topic.PartitionConfig.Capacity.SubscribeMibPerSec)
+				info := fmt.Sprintf("Name: %s\nLocation: %s\nPartitions: %s\nCapacity Per Partition: %s\nRetention Per Partition: %s bytes\nRetention Period: %s\nThroughput Reservation: %s",
+					path.Base(topic.Name), location, topic.PartitionConfig.Count, capacity,
+					topic.RetentionConfig.PerPartitionBytes, topic.RetentionConfig.Period,
+					path.Base(topic.ReservationConfig.ThroughputReservation))
+				writeResourceRaw("Pub/Sub Lite Topic", info, topic)
+				count++
+			}
+			return nil
+		})
+		if err != nil {
+			// Skip locations without Pub/Sub Lite and projects without the API enabled
+			slog.Debug("Skipping Pub/Sub Lite", "location", location, "error", err)
+			continue
+		}
+
+		err = listREST(ctx, base+"/subscriptions", func(page *struct {
+			Subscriptions []pubSubLiteSubscription `json:"subscriptions"`
+		}) error {
+			for _, sub := range page.Subscriptions {
+				export := ""
+				if sub.ExportConfig != nil && sub.ExportConfig.PubsubConfig != nil {
+					export = fmt.Sprintf("%s (%s)", path.Base(sub.ExportConfig.PubsubConfig.Topic), sub.ExportConfig.DesiredState)
+				}
+				info := fmt.Sprintf("Name: %s\nLocation: %s\nTopic: %s\nDelivery Requirement: %s\nExport To: %s",
+					path.Base(sub.Name), location, path.Base(sub.Topic), sub.DeliveryConfig.DeliveryRequirement, export)
+				writeResourceRaw("Pub/Sub Lite Subscription", info, sub)
+				count++
+			}
+			return nil
+		})
+		if err != nil {
+			slog.Error("Failed to list Pub/Sub Lite subscriptions", "location", location, "error", err)
+		}
+	}
+	scanProgress.found(count)
+}
+
+// getManagedKafka lists the region's Managed Service for Apache Kafka
+// clusters and their topics.
+func getManagedKafka(ctx context.Context, region string) {
+	var clusters []kafkaCluster
+	endpoint := fmt.Sprintf("https://managedkafka.googleapis.com/v1/projects/%s/locations/%s/clusters", projectID, region)
+	err := listREST(ctx, endpoint, func(page *struct {
+		Clusters []kafkaCluster `json:"clusters"`
+	}) error {
+		clusters = append(clusters, page.Clusters...)
+		return nil
+	})
+	if err != nil {
+		// Skip regions without Managed Kafka and projects without the API enabled
+		slog.Debug("Skipping Managed Kafka", "region", region, "error", err)
+		return
+	}
+
+	count := 0
+	for _, cluster := range clusters {
+		var subnets []string
+		for _, network := range cluster.GcpConfig.AccessConfig.NetworkConfigs {
+			subnets = append(subnets, path.Base(network.Subnet))
+		}
+		info := fmt.Sprintf("Name: %s\nRegion: %s\nState: %s\nvCPUs: %d\nMemory: %d GiB\nSubnets: %s\nKMS Key: %s\nCreated: %s",
+			path.Base(cluster.Name), region, cluster.State, cluster.CapacityConfig.VcpuCount,
+			cluster.CapacityConfig.MemoryBytes>>30, strings.Join(subnets, ", "),
+			cluster.GcpConfig.KmsKey, cluster.CreateTime)
+		writeResourceRaw("Kafka Cluster", info, cluster)
+		count++
+
+		err := listREST(ctx, "https://managedkafka.googleapis.com/v1/"+cluster.Name+"/topics", func(page *struct {
+			Topics []kafkaTopic `json:"topics"`
+		}) error {
+			for _, topic := range page.Topics {
+				info := fmt.Sprintf("Name: %s\nCluster: %s\nPartitions: %d\nReplication Factor: %d",
+					path.Base(topic.Name), path.Base(cluster.Name), topic.PartitionCount, topic.ReplicationFactor)
+				writeResourceRaw("Kafka Topic", info, topic)
+				count++
+			}
+			return nil
+		})
+		if err != nil {
+			slog.Error("Failed to list Kafka topics", "cluster", cluster.Name, "error", err)
+		}
+	}
+	scanProgress.found(count)
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/compute/metadata"
+	"golang.org/x/oauth2/google"
+)
+
+// version is the tool version reported in scan metadata, set at build time
+// with -ldflags "-X main.version=v1.2.3". Builds without it fall back to
+// the module version go install recorded, if any.
+var version = "dev"
+
+func toolVersion() string {
+	if version != "dev" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return version
+}
+
+// scanPrincipal is the account the scan runs as, looked up once.
+var scanPrincipal string
+
+// credentialsPrincipal returns the email of the account API calls are made
+// as: the impersonated service account, the service account of a key or
+// federation config, the instance's service account on Compute Engine, or
+// the owner of the access token. It returns "unknown" when none of these
+// work, which doesn't stop the scan.
+func credentialsPrincipal(ctx context.Context) string {
+	if impersonateServiceAccount != "" {
+		return impersonateServiceAccount
+	}
+	creds, err := google.FindDefaultCredentials(ctx, cloudPlatformScope)
+	if err != nil {
+		return "unknown"
+	}
+	if len(creds.JSON) > 0 {
+		var file struct {
+			ClientEmail                    string `json:"client_email"`
+			ServiceAccountImpersonationURL string `json:"service_account_impersonation_url"`
+		}
+		if json.Unmarshal(creds.JSON, &file) == nil {
+			if file.ClientEmail != "" {
+				return file.ClientEmail
+			}
+			// .../serviceAccounts/<email>:generateAccessToken
+			if i := strings.LastIndex(file.ServiceAccountImpersonationURL, "/"); i >= 0 {
+				email, _, _ := strings.Cut(file.ServiceAccountImpersonationURL[i+1:], ":")
+				return email
+			}
+		}
+	} else if metadata.OnGCE() {
+		if email, err := metadata.Email("default"); err == nil {
+			return email
+		}
+	}
+
+	token, err := creds.TokenSource.Token()
+	if err != nil {
+		return "unknown"
+	}
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		"https://oauth2.googleapis.com/tokeninfo?access_token="+url.QueryEscape(token.AccessToken), nil)
+	if err != nil {
+		return "unknown"
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "unknown"
+	}
+	defer resp.Body.Close()
+	var info struct {
+		Email string `json:"email"`
+	}
+	if resp.StatusCode != http.StatusOK || json.NewDecoder(resp.Body).Decode(&info) != nil || info.Email == "" {
+		return "unknown"
+	}
+	return info.Email
+}
+
+// skippedCollectors returns the collectors that didn't finish in this scan:
+// denied by the pre-flight check, timed out, or never reached before
+// --timeout, as "region/name" like checkpoint keys.
+func skippedCollectors() []string {
+	var skipped []string
+	for _, c := range globalCollectors {
+		if key := checkpointKey(c, ""); !completed[key] {
+			skipped = append(skipped, key)
+		}
+	}
+	for _, region := range regions {
+		for _, c := range regionalCollectors {
+			if key := checkpointKey(c, region); !completed[key] {
+				skipped = append(skipped, key)
+			}
+		}
+	}
+	return skipped
+}
+
+// scanMetadataType is the resource type of the Scan Metadata entry. The
+// entry describes the scan rather than a resource, so diffs, metrics and
+// the table exports leave it out.
+const scanMetadataType = "Scan Metadata"
+
+// withoutScanMetadata returns a copy of rows without the Scan Metadata
+// entry.
+func withoutScanMetadata(rows []inventoryRow) []inventoryRow {
+	kept := make([]inventoryRow, 0, len(rows))
+	for _, row := range rows {
+		if row.ResourceType != scanMetadataType {
+			kept = append(kept, row)
+		}
+	}
+	return kept
+}
+
+// writeScanMetadata ends the report with a Scan Metadata entry so
+// consumers of any format can tell how complete the inventory is. It is
+// written after the snapshot is saved, so it never shows up as a change in
+// diffs or the executive summary.
+func writeScanMetadata(ctx context.Context, started time.Time, incomplete bool) {
+	if scanPrincipal == "" {
+		scanPrincipal = credentialsPrincipal(ctx)
+	}
+	skipped := skippedCollectors()
+	total := len(globalCollectors) + len(regions)*len(regionalCollectors)
+
+	currentCollector = ""
+	writeSection("SCAN METADATA")
+	info := fmt.Sprintf("Name: %s\nTool Version: %s\nProject ID: %s\nStarted: %s\nDuration: %s\nPrincipal: %s\nRegions: %s\nCollectors Run: %d\nCollectors Skipped: %s\nAPI Errors: %d\nIncomplete: %t",
+		scanTime.Format(time.RFC3339), toolVersion(), projectID, scanTime.Format(time.RFC3339),
+		time.Since(started).Round(time.Second), scanPrincipal, strings.Join(regions, ", "),
+		total-len(skipped), strings.Join(skipped, ", "), scanErrors.Load(), incomplete)
+	writeResource(scanMetadataType, info)
+}
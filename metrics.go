@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// scanMetrics is exposed in the Prometheus text format on /metrics by the
+// serve command and, with --metrics-addr, in daemon mode. It is small enough
+// that the exposition format is written by hand rather than pulling in the
+// Prometheus client library.
+var scanMetrics = &metrics{}
+
+type metrics struct {
+	mu             sync.Mutex
+	resources      map[[2]string]int // {type, region} -> count
+	lastScan       time.Time
+	lastDuration   time.Duration
+	lastScanErrors int64
+	scansSucceeded int
+	scansFailed    int
+}
+
+// record updates the metrics after a scan. Resource counts are only replaced
+// by successful scans so a failed run doesn't zero the dashboards.
+func (m *metrics) record(start time.Time, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.lastDuration = time.Since(start)
+	m.lastScanErrors = scanErrors.Load()
+	if err != nil {
+		m.scansFailed++
+		return
+	}
+
+	m.scansSucceeded++
+	m.lastScan = scanTime
+	m.resources = make(map[[2]string]int)
+	for _, row := range withoutScanMetadata(inventory) {
+		m.resources[[2]string{row.ResourceType, rowRegion(row)}]++
+	}
+}
+
+func (m *metrics) handle(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	m.write(w)
+}
+
+func (m *metrics) write(w io.Writer) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	labels := fmt.Sprintf(`project="%s"`, escapeLabel(projectID))
+
+	fmt.Fprintln(w, "# HELP gcp_footprint_resources Resources found by the last successful scan.")
+	fmt.Fprintln(w, "# TYPE gcp_footprint_resources gauge")
+	keys := make([][2]string, 0, len(m.resources))
+	for k := range m.resources {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i][0] != keys[j][0] {
+			return keys[i][0] < keys[j][0]
+		}
+		return keys[i][1] < keys[j][1]
+	})
+	for _, k := range keys {
+		fmt.Fprintf(w, "gcp_footprint_resources{%s,type=\"%s\",region=\"%s\"} %d\n",
+			labels, escapeLabel(k[0]), escapeLabel(k[1]), m.resources[k])
+	}
+
+	fmt.Fprintln(w, "# HELP gcp_footprint_scan_duration_seconds Duration of the last scan.")
+	fmt.Fprintln(w, "# TYPE gcp_footprint_scan_duration_seconds gauge")
+	fmt.Fprintf(w, "gcp_footprint_scan_duration_seconds{%s} %g\n", labels, m.lastDuration.Seconds())
+
+	fmt.Fprintln(w, "# HELP gcp_footprint_last_scan_timestamp_seconds Start time of the last successful scan.")
+	fmt.Fprintln(w, "# TYPE gcp_footprint_last_scan_timestamp_seconds gauge")
+	lastScan := 0.0
+	if !m.lastScan.IsZero() {
+		lastScan = float64(m.lastScan.Unix())
+	}
+	fmt.Fprintf(w, "gcp_footprint_last_scan_timestamp_seconds{%s} %g\n", labels, lastScan)
+
+	fmt.Fprintln(w, "# HELP gcp_footprint_scans_total Completed scans by result.")
+	fmt.Fprintln(w, "# TYPE gcp_footprint_scans_total counter")
+	fmt.Fprintf(w, "gcp_footprint_scans_total{%s,result=\"success\"} %d\n", labels, m.scansSucceeded)
+	fmt.Fprintf(w, "gcp_footprint_scans_total{%s,result=\"failure\"} %d\n", labels, m.scansFailed)
+
+	fmt.Fprintln(w, "# HELP gcp_footprint_last_scan_api_errors API errors logged during the last scan.")
+	fmt.Fprintln(w, "# TYPE gcp_footprint_last_scan_api_errors gauge")
+	fmt.Fprintf(w, "gcp_footprint_last_scan_api_errors{%s} %d\n", labels, m.lastScanErrors)
+
+	fmt.Fprintln(w, "# HELP gcp_footprint_api_errors_total API errors logged since the process started.")
+	fmt.Fprintln(w, "# TYPE gcp_footprint_api_errors_total counter")
+	fmt.Fprintf(w, "gcp_footprint_api_errors_total{%s} %d\n", labels, totalErrors.Load())
+}
+
+// serveMetrics exposes only /metrics, for daemon mode without the HTTP API.
+func serveMetrics(addr string) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /metrics", scanMetrics.handle)
+	httpServer := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	slog.Info("Serving metrics", "addr", addr)
+	if err := httpServer.ListenAndServe(); err != nil {
+		slog.Error("Metrics server failed", "error", err)
+	}
+}
+
+// rowRegion returns the region a resource was found in, or "global".
+func rowRegion(row inventoryRow) string {
+	if region, ok := strings.CutPrefix(row.Section, "REGION: "); ok {
+		return region
+	}
+	return "global"
+}
+
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+
+	"google.golang.org/api/networkconnectivity/v1"
+)
+
+// getConnectivityHubs lists Network Connectivity Center hubs and their
+// spokes, with the VPN tunnels, Interconnect attachments, router
+// appliances or VPC networks each spoke attaches.
+func getConnectivityHubs(ctx context.Context) {
+	nccService, err := networkconnectivity.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create Network Connectivity service", "error", err)
+		return
+	}
+
+	hubs, err := nccService.Projects.Locations.Global.Hubs.List(fmt.Sprintf("projects/%s/locations/global", projectID)).Context(ctx).Do()
+	if err != nil {
+		// Skip projects that don't have the Network Connectivity API enabled
+		slog.Debug("Skipping Network Connectivity Center", "error", err)
+		return
+	}
+
+	count := 0
+	for _, hub := range hubs.Hubs {
+		var vpcs []string
+		for _, vpc := range hub.RoutingVpcs {
+			vpcs = append(vpcs, path.Base(vpc.Uri))
+		}
+		info := fmt.Sprintf("Name: %s\nDescription: %s\nState: %s\nRouting VPCs: %s\nCreated: %s",
+			path.Base(hub.Name), oneLine(hub.Description), hub.State, strings.Join(vpcs, ", "), hub.CreateTime)
+		writeResourceRaw("NCC Hub", info, hub)
+		count++
+	}
+
+	spokes, err := nccService.Projects.Locations.Spokes.List(fmt.Sprintf("projects/%s/locations/-", projectID)).Context(ctx).Do()
+	if err != nil {
+		slog.Error("Failed to list NCC spokes", "error", err)
+		scanProgress.found(count)
+		return
+	}
+	for _, spoke := range spokes.Spokes {
+		kind, linked := spokeLinks(spoke)
+		info := fmt.Sprintf("Name: %s\nLocation: %s\nHub: %s\nType: %s\nLinked: %s\nState: %s",
+			path.Base(spoke.Name), membershipLocation(spoke.Name), path.Base(spoke.Hub), kind,
+			strings.Join(linked, ", "), spoke.State)
+		writeResourceRaw("NCC Spoke", info, spoke)
+		count++
+	}
+	scanProgress.found(count)
+}
+
+// spokeLinks returns the kind of resource a spoke attaches to its hub and
+// their names.
+func spokeLinks(spoke *networkconnectivity.Spoke) (string, []string) {
+	var linked []string
+	switch {
+	case spoke.LinkedVpnTunnels != nil:
+		for _, uri := range spoke.LinkedVpnTunnels.Uris {
+			linked = append(linked, path.Base(uri))
+		}
+		return "VPN", linked
+	case spoke.LinkedInterconnectAttachments != nil:
+		for _, uri := range spoke.LinkedInterconnectAttachments.Uris {
+			linked = append(linked, path.Base(uri))
+		}
+		return "Interconnect", linked
+	case spoke.LinkedRouterApplianceInstances != nil:
+		for _, instance := range spoke.LinkedRouterApplianceInstances.Instances {
+			linked = append(linked, path.Base(instance.VirtualMachine))
+		}
+		return "Router appliance", linked
+	case spoke.LinkedVpcNetwork != nil:
+		return "VPC", []string{path.Base(spoke.LinkedVpcNetwork.Uri)}
+	}
+	return "", nil
+}
@@ -0,0 +1,378 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/ids/v1"
+)
+
+// getNetworkPeerings lists every peering of the project's VPC networks with
+// its state and which routes are exchanged in each direction.
+func getNetworkPeerings(ctx context.Context) {
+	computeService, err := compute.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create compute service", "error", err)
+		return
+	}
+
+	networks, err := computeService.Networks.List(projectID).Context(ctx).Do()
+	if err != nil {
+		slog.Error("Failed to list VPCs", "error", err)
+		return
+	}
+
+	count := 0
+	for _, network := range networks.Items {
+		for _, peering := range network.Peerings {
+			peerProject, peerNetwork := networkFromURL(peering.Network)
+			state := peering.State
+			if peering.StateDetails != "" {
+				state += " (" + peering.StateDetails + ")"
+			}
+			info := fmt.Sprintf("Name: %s\nNetwork: %s\nPeer Network: %s\nPeer Project: %s\nState: %s\n"+
+				"Export Custom Routes: %v\nImport Custom Routes: %v\n"+
+				"Export Subnet Routes With Public IP: %v\nImport Subnet Routes With Public IP: %v",
+				peering.Name, network.Name, peerNetwork, peerProject, state,
+				peering.ExportCustomRoutes, peering.ImportCustomRoutes,
+				peering.ExportSubnetRoutesWithPublicIp, peering.ImportSubnetRoutesWithPublicIp)
+			writeResourceRaw("VPC Peering", info, peering)
+			count++
+		}
+	}
+	scanProgress.found(count)
+}
+
+// getSharedVPC reports the project's Shared VPC relationships: the service
+// projects attached to it when it is a host project, or its host project
+// when it is a service project.
+func getSharedVPC(ctx context.Context) {
+	computeService, err := compute.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create compute service", "error", err)
+		return
+	}
+
+	project, err := computeService.Projects.Get(projectID).Context(ctx).Do()
+	if err != nil {
+		slog.Error("Failed to get compute project", "error", err)
+		return
+	}
+
+	count := 0
+	if project.XpnProjectStatus == "HOST" {
+		resources, err := computeService.Projects.GetXpnResources(projectID).Context(ctx).Do()
+		if err != nil {
+			slog.Error("Failed to list Shared VPC service projects", "error", err)
+			return
+		}
+		for _, resource := range resources.Resources {
+			info := fmt.Sprintf("Name: %s\nType: %s\nHost Project: %s", resource.Id, resource.Type, projectID)
+			writeResource("Shared VPC Service Project", info)
+			count++
+		}
+	}
+
+	host, err := computeService.Projects.GetXpnHost(projectID).Context(ctx).Do()
+	if err != nil {
+		slog.Error("Failed to get Shared VPC host project", "error", err)
+	} else if host.Name != "" {
+		info := fmt.Sprintf("Name: %s\nService Project: %s", host.Name, projectID)
+		writeResource("Shared VPC Host Project", info)
+		count++
+	}
+	scanProgress.found(count)
+}
+
+// getPrivateServiceConnect lists the region's Private Service Connect
+// endpoints (consumer side) and service attachments (producer side), with
+// the service each endpoint connects to and the consumers connected to each
+// attachment.
+func getPrivateServiceConnect(ctx context.Context, region string) {
+	computeService, err := compute.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create compute service", "error", err)
+		return
+	}
+
+	rules, err := computeService.ForwardingRules.List(projectID, region).Context(ctx).Do()
+	if err != nil {
+		// Skip regions that don't exist or aren't enabled for this project
+		slog.Debug("Skipping PSC endpoints", "region", region, "error", err)
+		return
+	}
+
+	count := 0
+	for _, rule := range rules.Items {
+		if rule.PscConnectionId == 0 && !strings.Contains(rule.Target, "/serviceAttachments/") {
+			continue
+		}
+		info := fmt.Sprintf("Name: %s\nIP Address: %s\nService Attachment: %s\nConnection Status: %s\nConnection ID: %d\nNetwork: %s\nRegion: %s",
+			rule.Name, rule.IPAddress, rule.Target, rule.PscConnectionStatus, rule.PscConnectionId,
+			rule.Network, path.Base(rule.Region))
+		writeResourceRaw("PSC Endpoint", info, rule)
+		count++
+	}
+
+	attachments, err := computeService.ServiceAttachments.List(projectID, region).Context(ctx).Do()
+	if err != nil {
+		slog.Debug("Skipping PSC service attachments", "region", region, "error", err)
+		scanProgress.found(count)
+		return
+	}
+	for _, attachment := range attachments.Items {
+		var consumers []string
+		for _, endpoint := range attachment.ConnectedEndpoints {
+			consumers = append(consumers, fmt.Sprintf("%s (%s)", endpoint.Endpoint, endpoint.Status))
+		}
+		var natSubnets []string
+		for _, subnet := range attachment.NatSubnets {
+			natSubnets = append(natSubnets, path.Base(subnet))
+		}
+		info := fmt.Sprintf("Name: %s\nTarget Service: %s\nConnection Preference: %s\nNAT Subnets: %s\nConsumers: %s\nRegion: %s",
+			attachment.Name, attachment.TargetService, attachment.ConnectionPreference,
+			strings.Join(natSubnets, ", "), strings.Join(consumers, ", "), path.Base(attachment.Region))
+		writeResourceRaw("PSC Service Attachment", info, attachment)
+		count++
+	}
+	scanProgress.found(count)
+}
+
+// getRoutes lists the project's static and other custom routes with their
+// next hop. Subnet routes are left out; the subnets themselves are reported.
+func getRoutes(ctx context.Context) {
+	computeService, err := compute.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create compute service", "error", err)
+		return
+	}
+
+	routes, err := computeService.Routes.List(projectID).Context(ctx).Do()
+	if err != nil {
+		slog.Error("Failed to list routes", "error", err)
+		return
+	}
+
+	count := 0
+	for _, route := range routes.Items {
+		if route.RouteType == "SUBNET" {
+			continue
+		}
+		info := fmt.Sprintf("Name: %s\nNetwork: %s\nDest Range: %s\nNext Hop: %s\nPriority: %d\nTags: %s\nType: %s",
+			route.Name, route.Network, route.DestRange, routeNextHop(route), route.Priority,
+			strings.Join(route.Tags, ", "), route.RouteType)
+		writeResourceRaw("Route", info, route)
+		count++
+	}
+	scanProgress.found(count)
+}
+
+func routeNextHop(route *compute.Route) string {
+	hops := []struct{ kind, value string }{
+		{"gateway", route.NextHopGateway},
+		{"instance", route.NextHopInstance},
+		{"ip", route.NextHopIp},
+		{"ilb", route.NextHopIlb},
+		{"vpn-tunnel", route.NextHopVpnTunnel},
+		{"peering", route.NextHopPeering},
+		{"hub", route.NextHopHub},
+		{"network", route.NextHopNetwork},
+	}
+	for _, hop := range hops {
+		if hop.value != "" {
+			return hop.kind + " " + path.Base(hop.value)
+		}
+	}
+	return ""
+}
+
+// getPeeringRoutes lists the routes each VPC peering imports into the
+// project's networks in the region: the peer's subnet routes and, if
+// exchanged, its custom static and dynamic routes.
+func getPeeringRoutes(ctx context.Context, region string) {
+	computeService, err := compute.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create compute service", "error", err)
+		return
+	}
+
+	networks, err := computeService.Networks.List(projectID).Context(ctx).Do()
+	if err != nil {
+		slog.Error("Failed to list VPCs", "error", err)
+		return
+	}
+
+	count := 0
+	for _, network := range networks.Items {
+		for _, peering := range network.Peerings {
+			if peering.State != "ACTIVE" {
+				continue
+			}
+			routes, err := computeService.Networks.ListPeeringRoutes(projectID, network.Name).
+				Direction("INCOMING").PeeringName(peering.Name).Region(region).Context(ctx).Do()
+			if err != nil {
+				// Skip regions that don't exist or aren't enabled for this project
+				slog.Debug("Skipping peering routes", "region", region, "peering", peering.Name, "error", err)
+				continue
+			}
+			for _, route := range routes.Items {
+				info := fmt.Sprintf("Name: %s/%s\nNetwork: %s\nPeering: %s\nDest Range: %s\nNext Hop Region: %s\nPriority: %d\nType: %s",
+					peering.Name, route.DestRange, network.SelfLink, peering.Name, route.DestRange,
+					route.NextHopRegion, route.Priority, route.Type)
+				writeResourceRaw("Peering Route", info, route)
+				count++
+			}
+		}
+	}
+	scanProgress.found(count)
+}
+
+// getAddresses lists the region's reserved static IP addresses, internal and
+// external, with what they are assigned to.
+func getAddresses(ctx context.Context, region string) {
+	computeService, err := compute.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create compute service", "error", err)
+		return
+	}
+
+	addresses, err := computeService.Addresses.List(projectID, region).Context(ctx).Do()
+	if err != nil {
+		// Skip regions that don't exist or aren't enabled for this project
+		slog.Debug("Skipping addresses", "region", region, "error", err)
+		return
+	}
+
+	for _, address := range addresses.Items {
+		writeResourceRaw("Static IP Address", addressInfo(address), address)
+	}
+	scanProgress.found(len(addresses.Items))
+}
+
+func getGlobalAddresses(ctx context.Context) {
+	computeService, err := compute.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create compute service", "error", err)
+		return
+	}
+
+	addresses, err := computeService.GlobalAddresses.List(projectID).Context(ctx).Do()
+	if err != nil {
+		slog.Error("Failed to list global addresses", "error", err)
+		return
+	}
+
+	for _, address := range addresses.Items {
+		writeResourceRaw("Global Static IP Address", addressInfo(address), address)
+	}
+	scanProgress.found(len(addresses.Items))
+}
+
+func addressInfo(address *compute.Address) string {
+	var users []string
+	for _, user := range address.Users {
+		users = append(users, path.Base(user))
+	}
+	return fmt.Sprintf("Name: %s\nAddress: %s\nAddress Type: %s\nPurpose: %s\nStatus: %s\nUsers: %s\nNetwork: %s\nSubnet: %s\nRegion: %s\nCreated: %s",
+		address.Name, address.Address, address.AddressType, address.Purpose, address.Status,
+		strings.Join(users, ", "), address.Network, address.Subnetwork, selfLinkName(address.Region), address.CreationTimestamp)
+}
+
+// selfLinkName is the last segment of a self-link, or "" for none, where
+// path.Base would give ".".
+func selfLinkName(selfLink string) string {
+	if selfLink == "" {
+		return ""
+	}
+	return path.Base(selfLink)
+}
+
+// getPacketMirroring lists the region's packet mirroring policies: what
+// traffic is copied, from which subnets, instances or tags, to which
+// collector load balancer.
+func getPacketMirroring(ctx context.Context, region string) {
+	computeService, err := compute.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create compute service", "error", err)
+		return
+	}
+
+	policies, err := computeService.PacketMirrorings.List(projectID, region).Context(ctx).Do()
+	if err != nil {
+		// Skip regions that don't exist or aren't enabled for this project
+		slog.Debug("Skipping region", "region", region, "error", err)
+		return
+	}
+
+	for _, policy := range policies.Items {
+		network, collector := "", ""
+		if policy.Network != nil {
+			network = path.Base(policy.Network.Url)
+		}
+		if policy.CollectorIlb != nil {
+			collector = path.Base(policy.CollectorIlb.Url)
+		}
+		var sources []string
+		if m := policy.MirroredResources; m != nil {
+			for _, subnet := range m.Subnetworks {
+				sources = append(sources, "subnet "+path.Base(subnet.Url))
+			}
+			for _, instance := range m.Instances {
+				sources = append(sources, "instance "+path.Base(instance.Url))
+			}
+			for _, tag := range m.Tags {
+				sources = append(sources, "tag "+tag)
+			}
+		}
+		filter := ""
+		if f := policy.Filter; f != nil {
+			filter = strings.TrimSpace(fmt.Sprintf("%s %s %s", f.Direction,
+				strings.Join(f.IPProtocols, ","), strings.Join(f.CidrRanges, ",")))
+		}
+		info := fmt.Sprintf("Name: %s\nRegion: %s\nNetwork: %s\nEnabled: %s\nPriority: %d\nCollector: %s\nMirrored: %s\nFilter: %s",
+			policy.Name, region, network, policy.Enable, policy.Priority, collector, strings.Join(sources, ", "), filter)
+		writeResourceRaw("Packet Mirroring Policy", info, policy)
+	}
+	scanProgress.found(len(policies.Items))
+}
+
+// getIDSEndpoints lists the Cloud IDS endpoints in each of the region's
+// zones.
+func getIDSEndpoints(ctx context.Context, region string) {
+	idsService, err := ids.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create Cloud IDS service", "error", err)
+		return
+	}
+
+	zones, err := regionZones(ctx, region)
+	if err != nil {
+		// Skip regions that don't exist or aren't enabled for this project
+		slog.Debug("Skipping region", "region", region, "error", err)
+		return
+	}
+
+	count := 0
+	for _, zone := range zones {
+		endpoints, err := idsService.Projects.Locations.Endpoints.List(fmt.Sprintf("projects/%s/locations/%s", projectID, zone)).Context(ctx).Do()
+		if err != nil {
+			// Skip zones without Cloud IDS and projects without the API enabled
+			slog.Debug("Skipping Cloud IDS", "zone", zone, "error", err)
+			continue
+		}
+
+		for _, endpoint := range endpoints.Endpoints {
+			info := fmt.Sprintf("Name: %s\nZone: %s\nNetwork: %s\nMinimum Alert Severity: %s\nState: %s\nEndpoint IP: %s\nForwarding Rule: %s\nThreat Exceptions: %s\nTraffic Logs: %t",
+				path.Base(endpoint.Name), zone, path.Base(endpoint.Network), endpoint.Severity, endpoint.State,
+				endpoint.EndpointIp, path.Base(endpoint.EndpointForwardingRule),
+				strings.Join(endpoint.ThreatExceptions, ", "), endpoint.TrafficLogs)
+			writeResourceRaw("Cloud IDS Endpoint", info, endpoint)
+		}
+		count += len(endpoints.Endpoints)
+	}
+	scanProgress.found(count)
+}
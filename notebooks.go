@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+
+	notebooks "google.golang.org/api/notebooks/v2"
+)
+
+// colabRuntimeTemplate is a Colab Enterprise runtime template, as returned
+// by aiplatform.googleapis.com/v1. Only the fields the report uses are
+// decoded.
+type colabRuntimeTemplate struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+	MachineSpec struct {
+		MachineType      string `json:"machineType"`
+		AcceleratorType  string `json:"acceleratorType"`
+		AcceleratorCount int    `json:"acceleratorCount"`
+	} `json:"machineSpec"`
+	IdleShutdownConfig struct {
+		IdleTimeout          string `json:"idleTimeout"`
+		IdleShutdownDisabled bool   `json:"idleShutdownDisabled"`
+	} `json:"idleShutdownConfig"`
+}
+
+// colabRuntime is a Colab Enterprise runtime.
+type colabRuntime struct {
+	Name                       string `json:"name"`
+	DisplayName                string `json:"displayName"`
+	RuntimeUser                string `json:"runtimeUser"`
+	RuntimeState               string `json:"runtimeState"`
+	HealthState                string `json:"healthState"`
+	ServiceAccount             string `json:"serviceAccount"`
+	CreateTime                 string `json:"createTime"`
+	NotebookRuntimeTemplateRef struct {
+		NotebookRuntimeTemplate string `json:"notebookRuntimeTemplate"`
+	} `json:"notebookRuntimeTemplateRef"`
+}
+
+// getWorkbenchInstances lists the Vertex AI Workbench instances in each of
+// the region's zones, with who owns them and when they shut down when idle.
+// Instances left running are a common source of forgotten spend.
+func getWorkbenchInstances(ctx context.Context, region string) {
+	notebooksService, err := notebooks.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create Notebooks service", "error", err)
+		return
+	}
+	zones, err := regionZones(ctx, region)
+	if err != nil {
+		// Skip regions that don't exist or aren't enabled for this project
+		slog.Debug("Skipping region", "region", region, "error", err)
+		return
+	}
+
+	count := 0
+	for _, zone := range zones {
+		count += zoneWorkbenchInstances(ctx, notebooksService, zone)
+	}
+	scanProgress.found(count)
+}
+
+// zoneWorkbenchInstances writes the zone's Workbench instances and returns
+// how many there were.
+func zoneWorkbenchInstances(ctx context.Context, notebooksService *notebooks.Service, zone string) int {
+	count := 0
+	parent := fmt.Sprintf("projects/%s/locations/%s", projectID, zone)
+	err := notebooksService.Projects.Locations.Instances.List(parent).Pages(ctx, func(page *notebooks.ListInstancesResponse) error {
+		for _, instance := range page.Instances {
+			machineType, idleShutdown := "", "disabled"
+			var gpus, serviceAccounts []string
+			publicIP := true
+			if setup := instance.GceSetup; setup != nil {
+				machineType = setup.MachineType
+				if timeout := setup.Metadata["idle-timeout-seconds"]; timeout != "" {
+					idleShutdown = timeout + "s"
+				}
+				for _, a := range setup.AcceleratorConfigs {
+					gpus = append(gpus, fmt.Sprintf("%d x %s", a.CoreCount, a.Type))
+				}
+				for _, sa := range setup.ServiceAccounts {
+					serviceAccounts = append(serviceAccounts, sa.Email)
+				}
+				publicIP = !setup.DisablePublicIp
+			}
+			owner := strings.Join(instance.InstanceOwners, ", ")
+			if owner == "" {
+				owner = instance.Creator
+			}
+			info := fmt.Sprintf("Name: %s\nZone: %s\nState: %s\nMachine Type: %s\nAccelerators: %s\nIdle Shutdown: %s\nOwner: %s\nService Account: %s\nPublic IP: %t\nCreated: %s",
+				path.Base(instance.Name), zone, instance.State, path.Base(machineType), strings.Join(gpus, ", "), idleShutdown,
+				owner, strings.Join(serviceAccounts, ", "), publicIP, instance.CreateTime)
+			writeResourceRaw("Workbench Instance", info, instance)
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		// Skip zones without Workbench and projects without the Notebooks API enabled
+		slog.Debug("Skipping Workbench instances", "zone", zone, "error", err)
+	}
+	return count
+}
+
+// getColabRuntimes lists the region's Colab Enterprise runtimes. A runtime
+// gets its machine and idle shutdown settings from its template, so the
+// templates are listed first.
+func getColabRuntimes(ctx context.Context, region string) {
+	base := fmt.Sprintf("https://%s-aiplatform.googleapis.com/v1/projects/%s/locations/%s", region, projectID, region)
+	templates := map[string]colabRuntimeTemplate{}
+	err := listREST(ctx, base+"/notebookRuntimeTemplates", func(page *struct {
+		NotebookRuntimeTemplates []colabRuntimeTemplate `json:"notebookRuntimeTemplates"`
+	}) error {
+		for _, t := range page.NotebookRuntimeTemplates {
+			templates[path.Base(t.Name)] = t
+		}
+		return nil
+	})
+	if err != nil {
+		// Skip regions without Colab Enterprise and projects without the Vertex AI API enabled
+		slog.Debug("Skipping Colab Enterprise", "region", region, "error", err)
+		return
+	}
+
+	count := 0
+	err = listREST(ctx, base+"/notebookRuntimes", func(page *struct {
+		NotebookRuntimes []colabRuntime `json:"notebookRuntimes"`
+	}) error {
+		for _, runtime := range page.NotebookRuntimes {
+			// Runtimes refer to their template by its full name.
+			template := templates[path.Base(runtime.NotebookRuntimeTemplateRef.NotebookRuntimeTemplate)]
+			machineType, idleShutdown := template.MachineSpec.MachineType, "disabled"
+			if !template.IdleShutdownConfig.IdleShutdownDisabled && template.IdleShutdownConfig.IdleTimeout != "" {
+				idleShutdown = template.IdleShutdownConfig.IdleTimeout
+			}
+			gpus := ""
+			if template.MachineSpec.AcceleratorCount > 0 {
+				gpus = fmt.Sprintf("%d x %s", template.MachineSpec.AcceleratorCount, template.MachineSpec.AcceleratorType)
+			}
+			info := fmt.Sprintf("Name: %s\nRegion: %s\nState: %s\nHealth: %s\nTemplate: %s\nMachine Type: %s\nAccelerators: %s\nIdle Shutdown: %s\nOwner: %s\nService Account: %s\nCreated: %s",
+				runtime.DisplayName, region, runtime.RuntimeState, runtime.HealthState, template.DisplayName,
+				machineType, gpus, idleShutdown, runtime.RuntimeUser, runtime.ServiceAccount, runtime.CreateTime)
+			writeResourceRaw("Colab Enterprise Runtime", info, runtime)
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Error("Failed to list Colab Enterprise runtimes", "region", region, "error", err)
+	}
+	scanProgress.found(count)
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// notifyHighlights caps how many added, removed and changed resources are
+// listed in a notification; the counts always cover the full diff.
+const notifyHighlights = 10
+
+// scanSummary is the payload posted to --notify-webhook in the json format.
+type scanSummary struct {
+	ProjectID  string      `json:"project_id"`
+	ScanTime   time.Time   `json:"scan_time"`
+	Resources  int         `json:"resources"`
+	Errors     int64       `json:"errors"`
+	Incomplete bool        `json:"incomplete"`
+	Report     string      `json:"report"`
+	Diff       *diffCounts `json:"diff,omitempty"`
+	Highlights []string    `json:"highlights,omitempty"`
+	Text       string      `json:"text"`
+}
+
+type diffCounts struct {
+	Added   int `json:"added"`
+	Removed int `json:"removed"`
+	Changed int `json:"changed"`
+}
+
+// notifyScan posts a summary of the finished scan to a webhook, with the
+// highlights of what changed since previous when there is one.
+func notifyScan(ctx context.Context, webhook, format, fileName string, previous *scanState, incomplete bool) error {
+	summary := scanSummary{
+		ProjectID:  projectID,
+		ScanTime:   scanTime,
+		Resources:  len(withoutScanMetadata(inventory)),
+		Errors:     scanErrors.Load(),
+		Incomplete: incomplete,
+		Report:     fileName,
+	}
+	if previous != nil {
+		diff := diffInventories(previous.Inventory, inventory)
+		summary.Diff = &diffCounts{len(diff.Added), len(diff.Removed), len(diff.Changed)}
+		summary.Highlights = diffHighlights(diff)
+	}
+	summary.Text = summaryText(summary, format == "slack")
+
+	var payload any = summary
+	if format == "slack" {
+		payload = map[string]string{"text": summary.Text}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+// notifyFormat picks the payload format for a webhook: Slack incoming
+// webhooks only accept their own message format.
+func notifyFormat(webhook, format string) string {
+	if format != "" {
+		return format
+	}
+	if u, err := url.Parse(webhook); err == nil && u.Host == "hooks.slack.com" {
+		return "slack"
+	}
+	return "json"
+}
+
+func diffHighlights(diff inventoryDiff) []string {
+	var lines []string
+	add := func(prefix, resourceType, name, section string) {
+		if len(lines) < notifyHighlights {
+			lines = append(lines, fmt.Sprintf("%s %s %s (%s)", prefix, resourceType, name, section))
+		}
+	}
+	for _, row := range diff.Added {
+		add("+", row.ResourceType, row.Name, row.Section)
+	}
+	for _, row := range diff.Removed {
+		add("-", row.ResourceType, row.Name, row.Section)
+	}
+	for _, c := range diff.Changed {
+		add("~", c.ResourceType, c.Name, c.Section)
+	}
+	return lines
+}
+
+func summaryText(s scanSummary, slack bool) string {
+	bold := func(t string) string {
+		if slack {
+			return "*" + t + "*"
+		}
+		return t
+	}
+
+	var b strings.Builder
+	status := "completed"
+	if s.Incomplete {
+		status = "incomplete (timed out or interrupted)"
+	}
+	fmt.Fprintf(&b, "%s scan %s: %d resources, %d errors\n",
+		bold("GCP footprint for "+s.ProjectID), status, s.Resources, s.Errors)
+
+	if s.Diff == nil {
+		b.WriteString("No previous scan to compare against.\n")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "Since the last scan: %d added, %d removed, %d changed\n",
+		s.Diff.Added, s.Diff.Removed, s.Diff.Changed)
+	if len(s.Highlights) == 0 {
+		return b.String()
+	}
+	if slack {
+		b.WriteString("```\n")
+	}
+	for _, line := range s.Highlights {
+		b.WriteString(line + "\n")
+	}
+	if more := s.Diff.Added + s.Diff.Removed + s.Diff.Changed - len(s.Highlights); more > 0 {
+		fmt.Fprintf(&b, "... and %d more\n", more)
+	}
+	if slack {
+		b.WriteString("```\n")
+	}
+	return b.String()
+}
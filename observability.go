@@ -0,0 +1,171 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"google.golang.org/api/clouderrorreporting/v1beta1"
+	"google.golang.org/api/cloudprofiler/v2"
+	cloudtrace "google.golang.org/api/cloudtrace/v1"
+)
+
+// traceWindow is how far back the Cloud Trace summary looks. Error
+// Reporting uses its one-week period, and Profiler keeps 30 days.
+const traceWindow = 24 * time.Hour
+
+// observabilitySampleLimit caps the traces and profiles read to find which
+// services send them, since the summaries only need each service once.
+const observabilitySampleLimit = 5000
+
+// errSampleLimit stops paging once observabilitySampleLimit items have
+// been read.
+var errSampleLimit = fmt.Errorf("more than %d items", observabilitySampleLimit)
+
+// traceServiceLabels are the span labels that name the sending service,
+// in the order they're tried: the OpenTelemetry resource, App Engine and
+// the older agents. Spans without any are counted under their own name.
+var traceServiceLabels = []string{"service.name", "g.co/gae/app/module", "g.co/r/generic_task/job", "/component"}
+
+// getTraceSummary reports whether Cloud Trace received traces in the last
+// traceWindow, and which services sent them.
+func getTraceSummary(ctx context.Context) {
+	traceService, err := cloudtrace.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create Cloud Trace service", "error", err)
+		return
+	}
+
+	since := time.Now().Add(-traceWindow)
+	total, truncated := 0, false
+	services := map[string]int{}
+	err = traceService.Projects.Traces.List(projectID).StartTime(since.UTC().Format(time.RFC3339)).
+		View("ROOTSPAN").PageSize(1000).Pages(ctx, func(page *cloudtrace.ListTracesResponse) error {
+		for _, trace := range page.Traces {
+			if total == observabilitySampleLimit {
+				truncated = true
+				return errSampleLimit
+			}
+			total++
+			if len(trace.Spans) > 0 {
+				services[traceServiceName(trace.Spans[0])]++
+			}
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errSampleLimit) {
+		// Skip projects that don't have the Cloud Trace API enabled
+		slog.Debug("Skipping Cloud Trace", "error", err)
+		return
+	}
+
+	info := fmt.Sprintf("Name: %s\nSince: %s\nHas Data: %t\nTraces: %d\nServices: %s\nTruncated: %t",
+		projectID, since.UTC().Format(time.RFC3339), total > 0, total, serviceCounts(services), truncated)
+	writeResource("Trace Summary", info)
+	scanProgress.found(1)
+}
+
+func traceServiceName(span *cloudtrace.TraceSpan) string {
+	for _, key := range traceServiceLabels {
+		if v := span.Labels[key]; v != "" {
+			return v
+		}
+	}
+	return span.Name
+}
+
+// getProfilerSummary reports whether Cloud Profiler holds profiles for the
+// project, and the services (deployment targets) they came from with the
+// profile types each sends.
+func getProfilerSummary(ctx context.Context) {
+	profilerService, err := cloudprofiler.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create Cloud Profiler service", "error", err)
+		return
+	}
+
+	total, truncated := 0, false
+	types := map[string]map[string]bool{}
+	err = profilerService.Projects.Profiles.List("projects/"+projectID).PageSize(1000).Pages(ctx, func(page *cloudprofiler.ListProfilesResponse) error {
+		for _, profile := range page.Profiles {
+			if total == observabilitySampleLimit {
+				truncated = true
+				return errSampleLimit
+			}
+			total++
+			target := ""
+			if profile.Deployment != nil {
+				target = profile.Deployment.Target
+			}
+			if types[target] == nil {
+				types[target] = map[string]bool{}
+			}
+			types[target][profile.ProfileType] = true
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errSampleLimit) {
+		// Skip projects that don't have the Cloud Profiler API enabled
+		slog.Debug("Skipping Cloud Profiler", "error", err)
+		return
+	}
+
+	var services []string
+	for _, target := range sortedKeys(types) {
+		services = append(services, fmt.Sprintf("%s (%s)", target, strings.Join(sortedKeys(types[target]), " ")))
+	}
+	info := fmt.Sprintf("Name: %s\nHas Data: %t\nProfiles: %d\nServices: %s\nTruncated: %t",
+		projectID, total > 0, total, strings.Join(services, ", "), truncated)
+	writeResource("Profiler Summary", info)
+	scanProgress.found(1)
+}
+
+// getErrorReportingSummary reports the error groups Error Reporting saw in
+// the last week, how many errors they hold and which services raised them.
+func getErrorReportingSummary(ctx context.Context) {
+	errService, err := clouderrorreporting.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create Error Reporting service", "error", err)
+		return
+	}
+
+	groups, open := 0, 0
+	var errorCount int64
+	services := map[string]int{}
+	err = errService.Projects.Groupstats.List("projects/"+projectID).TimeRangePeriod("PERIOD_1_WEEK").
+		Pages(ctx, func(page *clouderrorreporting.ListGroupStatsResponse) error {
+			for _, stats := range page.ErrorGroupStats {
+				groups++
+				errorCount += stats.Count
+				if stats.Group == nil || stats.Group.ResolutionStatus == "OPEN" || stats.Group.ResolutionStatus == "" {
+					open++
+				}
+				for _, service := range stats.AffectedServices {
+					services[service.Service]++
+				}
+			}
+			return nil
+		})
+	if err != nil {
+		// Skip projects that don't have the Error Reporting API enabled
+		slog.Debug("Skipping Error Reporting", "error", err)
+		return
+	}
+
+	info := fmt.Sprintf("Name: %s\nPeriod: 1 week\nHas Data: %t\nError Groups: %d\nOpen Groups: %d\nErrors: %d\nServices: %s",
+		projectID, groups > 0, groups, open, errorCount, serviceCounts(services))
+	writeResource("Error Reporting Summary", info)
+	scanProgress.found(1)
+}
+
+// serviceCounts formats a count per service as "name (n), ...".
+func serviceCounts(counts map[string]int) string {
+	var parts []string
+	for _, name := range sortedKeys(counts) {
+		parts = append(parts, fmt.Sprintf("%s (%d)", name, counts[name]))
+	}
+	return strings.Join(parts, ", ")
+}
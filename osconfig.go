@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+
+	"google.golang.org/api/compute/v1"
+	"google.golang.org/api/osconfig/v1"
+)
+
+// recentPatchJobs is how many of the latest patch jobs are reported.
+const recentPatchJobs = 25
+
+// getPatchManagement lists OS Config patch deployments (scheduled
+// patching) and the most recent patch jobs with their results.
+func getPatchManagement(ctx context.Context) {
+	osService, err := osconfig.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create OS Config service", "error", err)
+		return
+	}
+
+	parent := "projects/" + projectID
+	deployments, err := osService.Projects.PatchDeployments.List(parent).Context(ctx).Do()
+	if err != nil {
+		// Skip projects that don't have the OS Config API enabled
+		slog.Debug("Skipping OS patch management", "error", err)
+		return
+	}
+
+	count := 0
+	for _, d := range deployments.PatchDeployments {
+		schedule := ""
+		switch {
+		case d.RecurringSchedule != nil:
+			schedule = strings.ToLower(d.RecurringSchedule.Frequency)
+			if tz := d.RecurringSchedule.TimeZone; tz != nil {
+				schedule += " " + tz.Id
+			}
+		case d.OneTimeSchedule != nil:
+			schedule = "once at " + d.OneTimeSchedule.ExecuteTime
+		}
+		info := fmt.Sprintf("Name: %s\nDescription: %s\nState: %s\nSchedule: %s\nTargets: %s\nLast Run: %s",
+			path.Base(d.Name), oneLine(d.Description), d.State, schedule, patchTargets(d.InstanceFilter), d.LastExecuteTime)
+		writeResourceRaw("Patch Deployment", info, d)
+		count++
+	}
+
+	jobs, err := osService.Projects.PatchJobs.List(parent).PageSize(recentPatchJobs).Context(ctx).Do()
+	if err != nil {
+		slog.Error("Failed to list patch jobs", "error", err)
+		scanProgress.found(count)
+		return
+	}
+	for _, job := range jobs.PatchJobs {
+		succeeded, failed := int64(0), int64(0)
+		if s := job.InstanceDetailsSummary; s != nil {
+			succeeded = s.SucceededInstanceCount + s.SucceededRebootRequiredInstanceCount
+			failed = s.FailedInstanceCount + s.TimedOutInstanceCount
+		}
+		info := fmt.Sprintf("Name: %s\nDisplay Name: %s\nDeployment: %s\nState: %s\nCreated: %s\nSucceeded Instances: %d\nFailed Instances: %d",
+			path.Base(job.Name), job.DisplayName, path.Base(job.PatchDeployment), job.State, job.CreateTime, succeeded, failed)
+		writeResourceRaw("Patch Job", info, job)
+		count++
+	}
+	scanProgress.found(count)
+}
+
+func patchTargets(filter *osconfig.PatchInstanceFilter) string {
+	if filter == nil {
+		return ""
+	}
+	if filter.All {
+		return "all instances"
+	}
+	var targets []string
+	if len(filter.Zones) > 0 {
+		targets = append(targets, "zones "+strings.Join(filter.Zones, " "))
+	}
+	if len(filter.InstanceNamePrefixes) > 0 {
+		targets = append(targets, "prefixes "+strings.Join(filter.InstanceNamePrefixes, " "))
+	}
+	for _, group := range filter.GroupLabels {
+		var labels []string
+		for k, v := range group.Labels {
+			labels = append(labels, k+"="+v)
+		}
+		targets = append(targets, "labels "+strings.Join(labels, " "))
+	}
+	if len(filter.Instances) > 0 {
+		targets = append(targets, fmt.Sprintf("%d instances", len(filter.Instances)))
+	}
+	return strings.Join(targets, ", ")
+}
+
+// getVMManagerCoverage reports, for each of the region's zones with
+// instances, which of them send OS inventory to VM Manager. Instances that
+// don't can't be covered by patch compliance or vulnerability reports.
+func getVMManagerCoverage(ctx context.Context, region string) {
+	computeService, err := compute.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create compute service", "error", err)
+		return
+	}
+	osService, err := osconfig.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create OS Config service", "error", err)
+		return
+	}
+	zones, err := regionZones(ctx, region)
+	if err != nil {
+		// Skip regions that don't exist or aren't enabled for this project
+		slog.Debug("Skipping region", "region", region, "error", err)
+		return
+	}
+
+	count := 0
+	for _, zone := range zones {
+		if zoneVMManagerCoverage(ctx, computeService, osService, zone) {
+			count++
+		}
+	}
+	scanProgress.found(count)
+}
+
+// zoneVMManagerCoverage writes the zone's VM Manager coverage and reports
+// whether it had any instances to write it for.
+func zoneVMManagerCoverage(ctx context.Context, computeService *compute.Service, osService *osconfig.Service, zone string) bool {
+	instances, err := computeService.Instances.List(projectID, zone).Context(ctx).Do()
+	if err != nil {
+		// Skip zones that aren't enabled for this project
+		slog.Debug("Skipping zone", "zone", zone, "error", err)
+		return false
+	}
+	if len(instances.Items) == 0 {
+		return false
+	}
+
+	parent := fmt.Sprintf("projects/%s/locations/%s/instances/-", projectID, zone)
+	reporting := map[string]string{}
+	err = osService.Projects.Locations.Instances.Inventories.List(parent).Pages(ctx, func(resp *osconfig.ListInventoriesResponse) error {
+		for _, inv := range resp.Inventories {
+			// Inventory names are .../instances/<instance ID or name>/inventory.
+			instance := path.Base(path.Dir(inv.Name))
+			osName := ""
+			if inv.OsInfo != nil {
+				osName = strings.TrimSpace(inv.OsInfo.ShortName + " " + inv.OsInfo.Version)
+			}
+			reporting[instance] = osName
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Debug("Skipping VM Manager coverage", "zone", zone, "error", err)
+		return false
+	}
+
+	var covered, missing []string
+	for _, instance := range instances.Items {
+		if _, ok := reporting[fmt.Sprint(instance.Id)]; ok {
+			covered = append(covered, instance.Name)
+		} else if _, ok := reporting[instance.Name]; ok {
+			covered = append(covered, instance.Name)
+		} else {
+			missing = append(missing, instance.Name)
+		}
+	}
+	info := fmt.Sprintf("Name: %s\nZone: %s\nInstances: %d\nReporting Inventory: %d\nCoverage: %s\nNot Reporting: %s",
+		zone, zone, len(instances.Items), len(covered),
+		utilization(int64(len(covered)), int64(len(instances.Items))), strings.Join(missing, ", "))
+	writeResource("VM Manager Coverage", info)
+	return true
+}
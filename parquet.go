@@ -0,0 +1,277 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+)
+
+// writeParquet writes the inventory as a Parquet file with one row per
+// resource: the inventory columns plus fields, a JSON object of the
+// resource's fields, so every resource type shares one schema, and the
+// normalized ID, asset type, location and zone. The file is a single
+// uncompressed row group with PLAIN-encoded required columns, the simplest
+// layout every reader (BigQuery external tables, DuckDB, Spark, pandas)
+// understands, which keeps the writer small enough not to need a Parquet
+// library.
+func writeParquet(w io.Writer, rows []inventoryRow) error {
+	rows = withoutScanMetadata(rows)
+	columns := []parquetColumn{
+		{name: "scan_time", physicalType: parquetInt64, convertedType: parquetTimestampMillis},
+		{name: "project_id", physicalType: parquetByteArray, convertedType: parquetUTF8},
+		{name: "section", physicalType: parquetByteArray, convertedType: parquetUTF8},
+		{name: "resource_type", physicalType: parquetByteArray, convertedType: parquetUTF8},
+		{name: "name", physicalType: parquetByteArray, convertedType: parquetUTF8},
+		{name: "collector", physicalType: parquetByteArray, convertedType: parquetUTF8},
+		{name: "fields", physicalType: parquetByteArray, convertedType: parquetJSON},
+		{name: "id", physicalType: parquetByteArray, convertedType: parquetUTF8},
+		{name: "asset_type", physicalType: parquetByteArray, convertedType: parquetUTF8},
+		{name: "location", physicalType: parquetByteArray, convertedType: parquetUTF8},
+		{name: "zone", physicalType: parquetByteArray, convertedType: parquetUTF8},
+	}
+	for _, row := range rows {
+		fields := map[string]string{}
+		for _, f := range row.Fields {
+			fields[f.Key] = f.Value
+		}
+		fieldsJSON, err := json.Marshal(fields)
+		if err != nil {
+			return err
+		}
+		columns[0].appendInt64(row.ScanTime.UnixMilli())
+		columns[1].appendBytes([]byte(row.ProjectID))
+		columns[2].appendBytes([]byte(row.Section))
+		columns[3].appendBytes([]byte(row.ResourceType))
+		columns[4].appendBytes([]byte(row.Name))
+		columns[5].appendBytes([]byte(row.Collector))
+		columns[6].appendBytes(fieldsJSON)
+		columns[7].appendBytes([]byte(row.key()))
+		columns[8].appendBytes([]byte(row.AssetType))
+		columns[9].appendBytes([]byte(row.Location))
+		columns[10].appendBytes([]byte(row.Zone))
+	}
+
+	var file bytes.Buffer
+	file.WriteString("PAR1")
+	for i := range columns {
+		columns[i].writeChunk(&file, len(rows))
+	}
+
+	footer := parquetFooter(columns, len(rows))
+	file.Write(footer)
+	binary.Write(&file, binary.LittleEndian, uint32(len(footer)))
+	file.WriteString("PAR1")
+
+	_, err := w.Write(file.Bytes())
+	return err
+}
+
+// Parquet format enum values used by writeParquet.
+const (
+	parquetInt64     = 2
+	parquetByteArray = 6
+
+	parquetUTF8            = 0
+	parquetTimestampMillis = 9
+	parquetJSON            = 19
+
+	parquetRequired     = 0
+	parquetPlain        = 0
+	parquetRLE          = 3
+	parquetUncompressed = 0
+	parquetDataPage     = 0
+)
+
+type parquetColumn struct {
+	name          string
+	physicalType  int32
+	convertedType int32
+	values        bytes.Buffer // PLAIN-encoded values
+
+	// Set by writeChunk.
+	offset int64
+	size   int64
+}
+
+func (c *parquetColumn) appendInt64(v int64) {
+	binary.Write(&c.values, binary.LittleEndian, v)
+}
+
+func (c *parquetColumn) appendBytes(v []byte) {
+	binary.Write(&c.values, binary.LittleEndian, uint32(len(v)))
+	c.values.Write(v)
+}
+
+// writeChunk writes the column as a single data page. Required top-level
+// columns have no repetition or definition levels, so the page is just the
+// values.
+func (c *parquetColumn) writeChunk(file *bytes.Buffer, numRows int) {
+	var header thriftWriter
+	header.i32(1, parquetDataPage)
+	header.i32(2, int32(c.values.Len()))
+	header.i32(3, int32(c.values.Len()))
+	header.structBegin(5)
+	header.i32(1, int32(numRows))
+	header.i32(2, parquetPlain)
+	header.i32(3, parquetRLE)
+	header.i32(4, parquetRLE)
+	header.structEnd()
+	header.stop()
+
+	c.offset = int64(file.Len())
+	file.Write(header.Bytes())
+	file.Write(c.values.Bytes())
+	c.size = int64(file.Len()) - c.offset
+}
+
+// parquetFooter encodes the FileMetaData.
+func parquetFooter(columns []parquetColumn, numRows int) []byte {
+	var t thriftWriter
+	t.i32(1, 1) // version
+
+	t.listBegin(2, thriftStruct, len(columns)+1)
+	t.binary(4, []byte("schema"))
+	t.i32(5, int32(len(columns)))
+	t.stop()
+	for _, c := range columns {
+		t.i32(1, c.physicalType)
+		t.i32(3, parquetRequired)
+		t.binary(4, []byte(c.name))
+		t.i32(6, c.convertedType)
+		t.stop()
+	}
+	t.listEnd()
+
+	t.i64(3, int64(numRows))
+
+	var totalSize int64
+	for _, c := range columns {
+		totalSize += c.size
+	}
+	t.listBegin(4, thriftStruct, 1)
+	t.listBegin(1, thriftStruct, len(columns))
+	for _, c := range columns {
+		t.i64(2, c.offset)
+		t.structBegin(3)
+		t.i32(1, c.physicalType)
+		t.listBegin(2, thriftI32, 1)
+		t.rawVarint(zigzag(parquetPlain))
+		t.listEnd()
+		t.listBegin(3, thriftBinary, 1)
+		t.rawBinary([]byte(c.name))
+		t.listEnd()
+		t.i32(4, parquetUncompressed)
+		t.i64(5, int64(numRows))
+		t.i64(6, c.size)
+		t.i64(7, c.size)
+		t.i64(9, c.offset)
+		t.structEnd()
+		t.stop()
+	}
+	t.listEnd()
+	t.i64(2, totalSize)
+	t.i64(3, int64(numRows))
+	t.stop()
+	t.listEnd()
+
+	t.binary(6, []byte("gcp_footprint"))
+	t.stop()
+	return t.Bytes()
+}
+
+// Thrift compact protocol type IDs.
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes the Thrift compact protocol that Parquet metadata
+// uses. Struct fields must be written in increasing ID order, and every
+// struct, including each struct element of a list, ends with stop.
+type thriftWriter struct {
+	bytes.Buffer
+	lastID  int16
+	lastIDs []int16
+}
+
+func (t *thriftWriter) fieldHeader(id int16, typ byte) {
+	if delta := id - t.lastID; delta > 0 && delta <= 15 {
+		t.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		t.WriteByte(typ)
+		t.rawVarint(zigzag(int64(id)))
+	}
+	t.lastID = id
+}
+
+func (t *thriftWriter) i32(id int16, v int32) {
+	t.fieldHeader(id, thriftI32)
+	t.rawVarint(zigzag(int64(v)))
+}
+
+func (t *thriftWriter) i64(id int16, v int64) {
+	t.fieldHeader(id, thriftI64)
+	t.rawVarint(zigzag(v))
+}
+
+func (t *thriftWriter) binary(id int16, v []byte) {
+	t.fieldHeader(id, thriftBinary)
+	t.rawBinary(v)
+}
+
+func (t *thriftWriter) structBegin(id int16) {
+	t.fieldHeader(id, thriftStruct)
+	t.lastIDs = append(t.lastIDs, t.lastID)
+	t.lastID = 0
+}
+
+// structEnd closes a struct field opened with structBegin.
+func (t *thriftWriter) structEnd() {
+	t.stop()
+	t.lastID = t.lastIDs[len(t.lastIDs)-1]
+	t.lastIDs = t.lastIDs[:len(t.lastIDs)-1]
+}
+
+// stop ends a struct; inside a list of structs it also resets the field IDs
+// for the next element.
+func (t *thriftWriter) stop() {
+	t.WriteByte(0)
+	t.lastID = 0
+}
+
+// listBegin starts a list field. Struct elements are written as bare fields
+// each ended by stop.
+func (t *thriftWriter) listBegin(id int16, elemType byte, size int) {
+	t.fieldHeader(id, thriftList)
+	if size < 15 {
+		t.WriteByte(byte(size)<<4 | elemType)
+	} else {
+		t.WriteByte(0xf0 | elemType)
+		t.rawVarint(uint64(size))
+	}
+	t.lastIDs = append(t.lastIDs, t.lastID)
+	t.lastID = 0
+}
+
+func (t *thriftWriter) listEnd() {
+	t.lastID = t.lastIDs[len(t.lastIDs)-1]
+	t.lastIDs = t.lastIDs[:len(t.lastIDs)-1]
+}
+
+func (t *thriftWriter) rawBinary(v []byte) {
+	t.rawVarint(uint64(len(v)))
+	t.Write(v)
+}
+
+func (t *thriftWriter) rawVarint(v uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	t.Write(buf[:binary.PutUvarint(buf[:], v)])
+}
+
+func zigzag(v int64) uint64 {
+	return uint64((v << 1) ^ (v >> 63))
+}
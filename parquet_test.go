@@ -0,0 +1,290 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestWriteParquetRoundTrip decodes what writeParquet wrote, with a
+// minimal Thrift compact reader, and checks the footer, each column's page
+// header and the values against the rows it was given.
+func TestWriteParquetRoundTrip(t *testing.T) {
+	scanned := time.Date(2026, 3, 2, 15, 4, 5, 0, time.UTC)
+	rows := []inventoryRow{
+		{
+			ScanTime: scanned, ProjectID: "my-project", Section: "REGION: us-central1",
+			ResourceType: "Compute Instance", Name: "vm-1", Collector: "us-central1/compute instances",
+			Fields:    []inventoryField{{"Name", "vm-1"}, {"Zone", "us-central1-a"}},
+			ID:        "//compute.googleapis.com/projects/my-project/zones/us-central1-a/instances/vm-1",
+			AssetType: "compute.googleapis.com/Instance", Location: "us-central1-a", Zone: "us-central1-a",
+		},
+		{
+			// Empty strings are still written, as zero-length values.
+			ScanTime: scanned, ProjectID: "my-project", Section: "GLOBAL RESOURCES",
+			ResourceType: "Attestor", Name: "built-by-ci", Collector: "Binary Authorization",
+			Fields:   []inventoryField{{"Name", "built-by-ci"}},
+			ID:       "//gcp_footprint/projects/my-project/locations/global/attestor/built-by-ci",
+			Location: "global",
+		},
+		{
+			ScanTime: scanned, ProjectID: "my-project", ResourceType: scanMetadataType, Name: "scan",
+			ID: "//gcp_footprint/projects/my-project/locations/global/scan-metadata/scan",
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := writeParquet(&buf, rows); err != nil {
+		t.Fatal(err)
+	}
+	file := buf.Bytes()
+	if !bytes.HasPrefix(file, []byte("PAR1")) || !bytes.HasSuffix(file, []byte("PAR1")) {
+		t.Fatalf("file doesn't start and end with PAR1")
+	}
+	footerLen := int(binary.LittleEndian.Uint32(file[len(file)-8:]))
+	footerStart := len(file) - 8 - footerLen
+	meta, n, err := readThriftStruct(file[footerStart:])
+	if err != nil {
+		t.Fatalf("decode footer: %v", err)
+	}
+	if n != footerLen {
+		t.Errorf("footer is %d bytes, length says %d", n, footerLen)
+	}
+
+	// The Scan Metadata row is left out.
+	const numRows = 2
+	if got := meta[1]; got != int64(1) {
+		t.Errorf("version = %v, want 1", got)
+	}
+	if got := meta[3]; got != int64(numRows) {
+		t.Errorf("num_rows = %v, want %d", got, numRows)
+	}
+	if got := string(meta[6].([]byte)); got != "gcp_footprint" {
+		t.Errorf("created_by = %q", got)
+	}
+
+	wantColumns := []struct {
+		name          string
+		physicalType  int64
+		convertedType int64
+	}{
+		{"scan_time", parquetInt64, parquetTimestampMillis},
+		{"project_id", parquetByteArray, parquetUTF8},
+		{"section", parquetByteArray, parquetUTF8},
+		{"resource_type", parquetByteArray, parquetUTF8},
+		{"name", parquetByteArray, parquetUTF8},
+		{"collector", parquetByteArray, parquetUTF8},
+		{"fields", parquetByteArray, parquetJSON},
+		{"id", parquetByteArray, parquetUTF8},
+		{"asset_type", parquetByteArray, parquetUTF8},
+		{"location", parquetByteArray, parquetUTF8},
+		{"zone", parquetByteArray, parquetUTF8},
+	}
+	schema := meta[2].([]any)
+	if len(schema) != len(wantColumns)+1 {
+		t.Fatalf("schema has %d elements, want %d", len(schema), len(wantColumns)+1)
+	}
+	root := schema[0].(map[int16]any)
+	if string(root[4].([]byte)) != "schema" || root[5] != int64(len(wantColumns)) {
+		t.Errorf("schema root = %v", root)
+	}
+	for i, want := range wantColumns {
+		got := schema[i+1].(map[int16]any)
+		// Every column is required: there are no optional columns, so
+		// pages carry no definition levels.
+		if string(got[4].([]byte)) != want.name || got[1] != want.physicalType ||
+			got[3] != int64(parquetRequired) || got[6] != want.convertedType {
+			t.Errorf("schema column %d = %v, want %+v, required", i, got, want)
+		}
+	}
+
+	rowGroups := meta[4].([]any)
+	if len(rowGroups) != 1 {
+		t.Fatalf("%d row groups, want 1", len(rowGroups))
+	}
+	rowGroup := rowGroups[0].(map[int16]any)
+	if rowGroup[3] != int64(numRows) {
+		t.Errorf("row group num_rows = %v, want %d", rowGroup[3], numRows)
+	}
+	chunks := rowGroup[1].([]any)
+	if len(chunks) != len(wantColumns) {
+		t.Fatalf("%d column chunks, want %d", len(chunks), len(wantColumns))
+	}
+
+	var totalSize int64
+	values := make([][]any, len(chunks))
+	for i, chunk := range chunks {
+		chunk := chunk.(map[int16]any)
+		md := chunk[3].(map[int16]any)
+		name := wantColumns[i].name
+		if md[1] != wantColumns[i].physicalType || md[4] != int64(parquetUncompressed) || md[5] != int64(numRows) {
+			t.Errorf("%s: column metadata = %v", name, md)
+		}
+		if path := md[3].([]any); len(path) != 1 || string(path[0].([]byte)) != name {
+			t.Errorf("%s: path_in_schema = %q", name, path)
+		}
+		// PLAIN is the only encoding: there's no dictionary page.
+		if enc := md[2].([]any); !reflect.DeepEqual(enc, []any{int64(parquetPlain)}) {
+			t.Errorf("%s: encodings = %v, want PLAIN only", name, enc)
+		}
+		if _, ok := md[11]; ok {
+			t.Errorf("%s: has a dictionary page offset", name)
+		}
+		offset, size := md[9].(int64), md[6].(int64)
+		if chunk[2] != offset || md[7] != size {
+			t.Errorf("%s: file_offset = %v, compressed size = %v, want %d and %d", name, chunk[2], md[7], offset, size)
+		}
+		totalSize += size
+
+		page := file[offset : offset+size]
+		header, n, err := readThriftStruct(page)
+		if err != nil {
+			t.Fatalf("%s: decode page header: %v", name, err)
+		}
+		body := page[n:]
+		if header[1] != int64(parquetDataPage) || header[2] != int64(len(body)) || header[3] != int64(len(body)) {
+			t.Errorf("%s: page header = %v, body is %d bytes", name, header, len(body))
+		}
+		dataPage := header[5].(map[int16]any)
+		if dataPage[1] != int64(numRows) || dataPage[2] != int64(parquetPlain) ||
+			dataPage[3] != int64(parquetRLE) || dataPage[4] != int64(parquetRLE) {
+			t.Errorf("%s: data page header = %v", name, dataPage)
+		}
+		values[i], err = readPlainValues(body, wantColumns[i].physicalType, numRows)
+		if err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+	}
+	if rowGroup[2] != totalSize {
+		t.Errorf("row group total_byte_size = %v, want %d", rowGroup[2], totalSize)
+	}
+	if got := int64(footerStart); got != 4+totalSize {
+		t.Errorf("footer starts at %d, want %d", got, 4+totalSize)
+	}
+
+	want := [][]any{
+		{scanned.UnixMilli(), "my-project", "REGION: us-central1", "Compute Instance", "vm-1",
+			"us-central1/compute instances", `{"Name":"vm-1","Zone":"us-central1-a"}`,
+			"//compute.googleapis.com/projects/my-project/zones/us-central1-a/instances/vm-1",
+			"compute.googleapis.com/Instance", "us-central1-a", "us-central1-a"},
+		{scanned.UnixMilli(), "my-project", "GLOBAL RESOURCES", "Attestor", "built-by-ci",
+			"Binary Authorization", `{"Name":"built-by-ci"}`,
+			"//gcp_footprint/projects/my-project/locations/global/attestor/built-by-ci",
+			"", "global", ""},
+	}
+	for r := range want {
+		for c, column := range wantColumns {
+			if values[c] != nil && values[c][r] != want[r][c] {
+				t.Errorf("row %d %s = %v, want %v", r, column.name, values[c][r], want[r][c])
+			}
+		}
+	}
+}
+
+// readPlainValues decodes n PLAIN-encoded values of a required column.
+func readPlainValues(b []byte, physicalType int64, n int) ([]any, error) {
+	var values []any
+	for range n {
+		switch physicalType {
+		case parquetInt64:
+			if len(b) < 8 {
+				return nil, fmt.Errorf("truncated INT64")
+			}
+			values = append(values, int64(binary.LittleEndian.Uint64(b)))
+			b = b[8:]
+		case parquetByteArray:
+			if len(b) < 4 {
+				return nil, fmt.Errorf("truncated BYTE_ARRAY length")
+			}
+			size := int(binary.LittleEndian.Uint32(b))
+			if len(b) < 4+size {
+				return nil, fmt.Errorf("truncated BYTE_ARRAY")
+			}
+			values = append(values, string(b[4:4+size]))
+			b = b[4+size:]
+		}
+	}
+	if len(b) != 0 {
+		return nil, fmt.Errorf("%d bytes left after %d values", len(b), n)
+	}
+	return values, nil
+}
+
+// readThriftStruct decodes a Thrift compact protocol struct into its
+// fields by ID, with integers as int64, binaries as []byte, lists as []any
+// and structs as map[int16]any. It returns the number of bytes read.
+func readThriftStruct(b []byte) (map[int16]any, int, error) {
+	fields := map[int16]any{}
+	pos := 0
+	var lastID int16
+	for {
+		if pos >= len(b) {
+			return nil, 0, fmt.Errorf("struct not stopped")
+		}
+		header := b[pos]
+		pos++
+		if header == 0 {
+			return fields, pos, nil
+		}
+		typ := header & 0x0f
+		if delta := int16(header >> 4); delta != 0 {
+			lastID += delta
+		} else {
+			id, n := binary.Uvarint(b[pos:])
+			pos += n
+			lastID = int16(unzigzag(id))
+		}
+		v, n, err := readThriftValue(b[pos:], typ)
+		if err != nil {
+			return nil, 0, fmt.Errorf("field %d: %w", lastID, err)
+		}
+		fields[lastID] = v
+		pos += n
+	}
+}
+
+func readThriftValue(b []byte, typ byte) (any, int, error) {
+	switch typ {
+	case thriftI32, thriftI64:
+		v, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, 0, fmt.Errorf("bad varint")
+		}
+		return unzigzag(v), n, nil
+	case thriftBinary:
+		size, n := binary.Uvarint(b)
+		if n <= 0 || len(b) < n+int(size) {
+			return nil, 0, fmt.Errorf("truncated binary")
+		}
+		return b[n : n+int(size)], n + int(size), nil
+	case thriftStruct:
+		return readThriftStruct(b)
+	case thriftList:
+		if len(b) == 0 {
+			return nil, 0, fmt.Errorf("truncated list")
+		}
+		size, elemType, pos := int(b[0]>>4), b[0]&0x0f, 1
+		if size == 15 {
+			s, n := binary.Uvarint(b[1:])
+			size, pos = int(s), 1+n
+		}
+		list := make([]any, 0, size)
+		for range size {
+			v, n, err := readThriftValue(b[pos:], elemType)
+			if err != nil {
+				return nil, 0, err
+			}
+			list = append(list, v)
+			pos += n
+		}
+		return list, pos, nil
+	}
+	return nil, 0, fmt.Errorf("unexpected type %d", typ)
+}
+
+func unzigzag(v uint64) int64 {
+	return int64(v>>1) ^ -int64(v&1)
+}
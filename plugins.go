@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Collector is a source of resources that lives outside the built-in
+// collector tables, for proprietary or niche services. Go implementations
+// register themselves from an init function in their own file with
+// registerCollector; anything else can be an executable in --plugin-dir.
+//
+// Collect is called once per scan for a global collector and once per
+// region for a regional one, and reports each resource it finds through
+// emit as a report entry of "Key: Value" lines, the first being its name.
+type Collector interface {
+	Name() string
+	Regional() bool
+	Permissions() []string
+	Collect(ctx context.Context, region string, emit func(resourceType, info string)) error
+}
+
+// pluginSection is the report section of global plugin collectors.
+const pluginSection = "CUSTOM RESOURCES"
+
+var pluginDir string
+
+// registerCollector schedules c alongside the built-in collectors. It
+// panics on a duplicate name, since that would mix up checkpoints.
+func registerCollector(c Collector) {
+	if err := addCollector(c); err != nil {
+		panic(err)
+	}
+}
+
+func addCollector(c Collector) error {
+	for _, existing := range append(append([]collector{}, globalCollectors...), regionalCollectors...) {
+		if existing.name == c.Name() {
+			return fmt.Errorf("collector %q is already registered", c.Name())
+		}
+	}
+
+	entry := collector{
+		name:        c.Name(),
+		permissions: c.Permissions(),
+		run: func(ctx context.Context, region string) {
+			count := 0
+			err := c.Collect(ctx, region, func(resourceType, info string) {
+				writeResource(resourceType, info)
+				count++
+			})
+			scanProgress.found(count)
+			if err != nil {
+				slog.Error("Failed to run collector", "collector", c.Name(), "region", region, "error", err)
+			}
+		},
+	}
+	if c.Regional() {
+		regionalCollectors = append(regionalCollectors, entry)
+	} else {
+		entry.section = pluginSection
+		globalCollectors = append(globalCollectors, entry)
+	}
+	return nil
+}
+
+// execCollector runs an executable plugin. The plugin is first run as
+// "<plugin> describe" and prints its description as JSON:
+//
+//	{"name": "...", "regional": true, "permissions": ["..."]}
+//
+// Each collection runs it as "<plugin> collect" with GCP_FOOTPRINT_PROJECT
+// and, for regional plugins, GCP_FOOTPRINT_REGION set. It prints one JSON
+// object per resource in the same shape as the ndjson format:
+//
+//	{"resource_type": "...", "fields": [{"key": "Name", "value": "..."}]}
+//
+// Anything it writes to stderr is passed through, and a non-zero exit is
+// reported as the collector failing.
+type execCollector struct {
+	path        string
+	name        string
+	regional    bool
+	permissions []string
+}
+
+func (c *execCollector) Name() string          { return c.name }
+func (c *execCollector) Regional() bool        { return c.regional }
+func (c *execCollector) Permissions() []string { return c.permissions }
+
+func (c *execCollector) Collect(ctx context.Context, region string, emit func(resourceType, info string)) error {
+	cmd := exec.CommandContext(ctx, c.path, "collect")
+	cmd.Env = append(os.Environ(), "GCP_FOOTPRINT_PROJECT="+projectID)
+	if region != "" {
+		cmd.Env = append(cmd.Env, "GCP_FOOTPRINT_REGION="+region)
+	}
+	cmd.Stderr = os.Stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	var parseErr error
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || parseErr != nil {
+			continue
+		}
+		var row inventoryRow
+		if err := json.Unmarshal([]byte(line), &row); err != nil {
+			parseErr = fmt.Errorf("parse output: %w", err)
+			continue
+		}
+		if row.ResourceType == "" || len(row.Fields) == 0 {
+			parseErr = fmt.Errorf("resource without resource_type or fields: %s", line)
+			continue
+		}
+		emit(row.ResourceType, row.info())
+	}
+	if err := cmd.Wait(); err != nil {
+		return err
+	}
+	if parseErr != nil {
+		return parseErr
+	}
+	return scanner.Err()
+}
+
+// loadPlugins registers every executable in dir as a collector.
+func loadPlugins(ctx context.Context, dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || info.IsDir() || info.Mode()&0o111 == 0 {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+
+		out, err := exec.CommandContext(ctx, path, "describe").Output()
+		if err != nil {
+			return fmt.Errorf("describe %s: %w", path, err)
+		}
+		var desc struct {
+			Name        string   `json:"name"`
+			Regional    bool     `json:"regional"`
+			Permissions []string `json:"permissions"`
+		}
+		if err := json.Unmarshal(out, &desc); err != nil {
+			return fmt.Errorf("describe %s: %w", path, err)
+		}
+		if desc.Name == "" {
+			desc.Name = entry.Name()
+		}
+
+		c := &execCollector{path: path, name: desc.Name, regional: desc.Regional, permissions: desc.Permissions}
+		if err := addCollector(c); err != nil {
+			return fmt.Errorf("load %s: %w", path, err)
+		}
+		slog.Debug("Loaded collector plugin", "name", c.name, "path", path, "regional", c.regional)
+	}
+	return nil
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// ticketCollector is the README's example of a compiled-in collector. A
+// real one registers itself from init with registerCollector.
+type ticketCollector struct{}
+
+func (ticketCollector) Name() string          { return "support tickets" }
+func (ticketCollector) Regional() bool        { return false }
+func (ticketCollector) Permissions() []string { return nil }
+
+func (ticketCollector) Collect(ctx context.Context, region string, emit func(resourceType, info string)) error {
+	emit("Support Ticket", "Name: INC-1234\nStatus: open")
+	return nil
+}
+
+// withPluginScan restores the collector tables and scan globals that
+// registering and running plugins change.
+func withPluginScan(t *testing.T) {
+	t.Helper()
+	globals, regionals := globalCollectors, regionalCollectors
+	savedReport, savedInventory, savedProgress := report, inventory, scanProgress
+	t.Cleanup(func() {
+		globalCollectors, regionalCollectors = globals, regionals
+		report, inventory, scanProgress = savedReport, savedInventory, savedProgress
+	})
+	report, inventory, scanProgress = io.Discard, nil, newProgress(1, true)
+}
+
+func findCollector(t *testing.T, collectors []collector, name string) collector {
+	t.Helper()
+	for _, c := range collectors {
+		if c.name == name {
+			return c
+		}
+	}
+	t.Fatalf("collector %q not registered", name)
+	return collector{}
+}
+
+func TestRegisterCollector(t *testing.T) {
+	withPluginScan(t)
+
+	registerCollector(ticketCollector{})
+	c := findCollector(t, globalCollectors, "support tickets")
+	if c.section != pluginSection {
+		t.Errorf("section = %q, want %q", c.section, pluginSection)
+	}
+	c.run(context.Background(), "")
+	if len(inventory) != 1 {
+		t.Fatalf("got %d resources, want 1", len(inventory))
+	}
+	if row := inventory[0]; row.ResourceType != "Support Ticket" || row.Name != "INC-1234" || row.field("Status") != "open" {
+		t.Errorf("resource = %+v", row)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("registering a duplicate name didn't panic")
+		}
+	}()
+	registerCollector(ticketCollector{})
+}
+
+func TestLoadPlugins(t *testing.T) {
+	withPluginScan(t)
+
+	dir := t.TempDir()
+	script := `#!/bin/sh
+case "$1" in
+describe) echo '{"name": "on-prem VMs", "regional": true, "permissions": ["compute.regions.get"]}' ;;
+collect) echo '{"resource_type": "On-Prem VM", "fields": [{"key": "Name", "value": "db-01"}, {"key": "Site", "value": "'"$GCP_FOOTPRINT_REGION"'"}]}' ;;
+esac
+`
+	if err := os.WriteFile(filepath.Join(dir, "onprem"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// Files that aren't executable are skipped.
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("notes"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	globals := len(globalCollectors)
+	if err := loadPlugins(context.Background(), dir); err != nil {
+		t.Fatal(err)
+	}
+	if len(globalCollectors) != globals {
+		t.Errorf("a regional plugin was added to the global collectors")
+	}
+	c := findCollector(t, regionalCollectors, "on-prem VMs")
+	if len(c.permissions) != 1 || c.permissions[0] != "compute.regions.get" {
+		t.Errorf("permissions = %q", c.permissions)
+	}
+	c.run(context.Background(), "europe-west4")
+	if len(inventory) != 1 {
+		t.Fatalf("got %d resources, want 1", len(inventory))
+	}
+	if row := inventory[0]; row.ResourceType != "On-Prem VM" || row.Name != "db-01" || row.field("Site") != "europe-west4" {
+		t.Errorf("resource = %+v", row)
+	}
+}
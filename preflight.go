@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"google.golang.org/api/cloudresourcemanager/v1"
+)
+
+// deniedCollectors holds the collectors skipped because the pre-flight check
+// found the credentials lack one of their permissions.
+var deniedCollectors = map[string]bool{}
+
+// checkPermissions tests every collector's permissions against the project
+// in a single testIamPermissions call, prints which services can and can't
+// be read, and marks the unreadable ones to be skipped. If the check itself
+// fails, every collector runs as before.
+func checkPermissions(ctx context.Context) {
+	collectors := append(append([]collector{}, globalCollectors...), regionalCollectors...)
+
+	wanted := map[string]bool{}
+	for _, c := range collectors {
+		for _, p := range c.permissions {
+			wanted[p] = true
+		}
+	}
+	permissions := make([]string, 0, len(wanted))
+	for p := range wanted {
+		permissions = append(permissions, p)
+	}
+	sort.Strings(permissions)
+
+	crmService, err := cloudresourcemanager.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Warn("Skipping permission check", "error", err)
+		return
+	}
+
+	granted := map[string]bool{}
+	// testIamPermissions accepts at most 100 permissions per call.
+	for start := 0; start < len(permissions); start += 100 {
+		batch := permissions[start:min(start+100, len(permissions))]
+		resp, err := crmService.Projects.TestIamPermissions(projectID, &cloudresourcemanager.TestIamPermissionsRequest{
+			Permissions: batch,
+		}).Context(ctx).Do()
+		if err != nil {
+			slog.Warn("Skipping permission check", "error", err)
+			return
+		}
+		for _, p := range resp.Permissions {
+			granted[p] = true
+		}
+	}
+
+	tw := tabwriter.NewWriter(console, 0, 0, 2, ' ', 0)
+	if !quiet {
+		fmt.Fprintln(console, "\nPermission check")
+		fmt.Fprintln(tw, "COLLECTOR\tSTATUS\tMISSING PERMISSIONS")
+	}
+	for _, c := range collectors {
+		var missing []string
+		for _, p := range c.permissions {
+			if !granted[p] {
+				missing = append(missing, p)
+			}
+		}
+
+		status := "ok"
+		if len(missing) > 0 {
+			status = "DENIED"
+			deniedCollectors[c.name] = true
+			slog.Warn("Skipping collector, missing permissions", "collector", c.name, "missing", strings.Join(missing, ","))
+		}
+		if !quiet {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", c.name, status, strings.Join(missing, ", "))
+		}
+	}
+	tw.Flush()
+	if !quiet {
+		fmt.Fprintln(console)
+	}
+}
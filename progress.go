@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+var scanProgress *progress
+
+// progress tracks completed collectors and renders a status line with an
+// ETA. On a terminal the line is redrawn in place; otherwise only the
+// per-collector findings are printed, one per line, so CI logs stay readable.
+type progress struct {
+	total  int
+	done   int
+	start  time.Time
+	quiet  bool
+	tty    bool
+	region string
+	name   string
+}
+
+func newProgress(total int, quiet bool) *progress {
+	p := &progress{total: total, start: time.Now(), quiet: quiet}
+	if fi, err := console.Stat(); err == nil {
+		p.tty = fi.Mode()&os.ModeCharDevice != 0
+	}
+	return p
+}
+
+// begin marks the start of a collector. region is empty for global ones.
+func (p *progress) begin(region, name string) {
+	p.region, p.name = region, name
+	p.draw()
+}
+
+// found records how many resources the current collector discovered.
+// Regional collectors only report non-zero counts to keep output short.
+func (p *progress) found(count int) {
+	if p.quiet || (count == 0 && p.region != "") {
+		return
+	}
+
+	where := "global"
+	if p.region != "" {
+		where = p.region
+	}
+	p.clear()
+	fmt.Fprintf(console, "[%*d/%d] %s: %d %s\n", len(fmt.Sprint(p.total)), p.done+1, p.total, where, count, p.name)
+	p.draw()
+}
+
+func (p *progress) end() {
+	p.done++
+	p.draw()
+}
+
+// finish clears the status line and prints the total elapsed time.
+func (p *progress) finish() {
+	if p.quiet {
+		return
+	}
+	p.clear()
+	fmt.Fprintf(console, "Completed %d collectors in %s\n", p.done, time.Since(p.start).Round(time.Second))
+}
+
+func (p *progress) draw() {
+	if p.quiet || !p.tty {
+		return
+	}
+
+	const width = 30
+	filled := width * p.done / max(p.total, 1)
+	where := "global"
+	if p.region != "" {
+		where = p.region
+	}
+
+	line := fmt.Sprintf("[%s%s] %d/%d %s › %s", strings.Repeat("=", filled), strings.Repeat(" ", width-filled),
+		p.done, p.total, where, p.name)
+	if eta := p.eta(); eta > 0 {
+		line += fmt.Sprintf("  ETA %s", eta.Round(time.Second))
+	}
+	fmt.Fprintf(console, "\r\033[K%s", line)
+}
+
+func (p *progress) clear() {
+	if p.tty {
+		fmt.Fprint(console, "\r\033[K")
+	}
+}
+
+// eta extrapolates the remaining time from the average collector duration.
+func (p *progress) eta() time.Duration {
+	if p.done == 0 {
+		return 0
+	}
+	perCollector := time.Since(p.start) / time.Duration(p.done)
+	return perCollector * time.Duration(p.total-p.done)
+}
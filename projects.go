@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"log/slog"
+	"os"
+	"os/exec"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"google.golang.org/api/cloudresourcemanager/v1"
+)
+
+var (
+	projectList      string
+	projectsParallel int
+	shardSpec        string
+)
+
+// multiProjectFlags are the flags handled by the parent process of a
+// multi-project scan and not passed to the per-project scans.
+var multiProjectFlags = map[string]bool{"project": true, "projects": true, "parallel": true, "shard": true}
+
+// runProjects scans every project in --projects that falls in this
+// instance's --shard, up to --parallel at a time. Each project is scanned by
+// a child process of this binary with the same flags, so its report, state
+// files and checkpoints are exactly those of a single-project scan. Output
+// from each child is prefixed with its project. It returns the number of
+// projects whose scan failed.
+func runProjects(ctx context.Context) (int, error) {
+	projects, err := resolveProjects(ctx, projectList)
+	if err != nil {
+		return 0, err
+	}
+	shard, shards, err := parseShard(shardSpec)
+	if err != nil {
+		return 0, err
+	}
+	projects = shardProjects(projects, shard, shards)
+	if len(projects) == 0 {
+		return 0, fmt.Errorf("no projects to scan in shard %d/%d", shard, shards)
+	}
+
+	self, err := os.Executable()
+	if err != nil {
+		return 0, err
+	}
+	// Progress lines from hundreds of scans would bury everything else.
+	childArgs := []string{"--quiet"}
+	flag.Visit(func(f *flag.Flag) {
+		if !multiProjectFlags[f.Name] && f.Name != "quiet" {
+			childArgs = append(childArgs, "--"+f.Name+"="+f.Value.String())
+		}
+	})
+
+	fmt.Fprintf(console, "Scanning %d projects, %d at a time\n", len(projects), max(projectsParallel, 1))
+	var (
+		mu     sync.Mutex
+		failed []string
+		wg     sync.WaitGroup
+		slots  = make(chan struct{}, max(projectsParallel, 1))
+	)
+	for _, project := range projects {
+		slots <- struct{}{}
+		if ctx.Err() != nil {
+			// Interrupted: let running scans write their reports but
+			// don't start any more.
+			<-slots
+			mu.Lock()
+			failed = append(failed, project)
+			mu.Unlock()
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer func() { <-slots; wg.Done() }()
+			args := append([]string{"--project=" + project}, childArgs...)
+			if err := runProjectScan(ctx, self, project, args); err != nil {
+				slog.Error("Project scan failed", "project", project, "error", err)
+				mu.Lock()
+				failed = append(failed, project)
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	sort.Strings(failed)
+	fmt.Fprintf(console, "Scanned %d projects, %d failed\n", len(projects), len(failed))
+	if len(failed) > 0 {
+		fmt.Fprintf(console, "Failed: %s\n", strings.Join(failed, ", "))
+	}
+	return len(failed), nil
+}
+
+func runProjectScan(ctx context.Context, self, project string, args []string) error {
+	cmd := exec.CommandContext(ctx, self, args...)
+	// Pass an interrupt on rather than killing the scan, so it still
+	// writes a partial report.
+	cmd.Cancel = func() error { return cmd.Process.Signal(syscall.SIGTERM) }
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() { defer wg.Done(); prefixLines(console, stdout, project) }()
+	go func() { defer wg.Done(); prefixLines(os.Stderr, stderr, project) }()
+	wg.Wait()
+	return cmd.Wait()
+}
+
+var prefixMu sync.Mutex
+
+// prefixLines copies r to w a line at a time with the project in front, so
+// interleaved output from parallel scans can be told apart.
+func prefixLines(w io.Writer, r io.Reader, project string) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		prefixMu.Lock()
+		fmt.Fprintf(w, "[%s] %s\n", project, scanner.Text())
+		prefixMu.Unlock()
+	}
+}
+
+// resolveProjects expands --projects: a comma-separated list, @file with
+// one project per line, or "all" for every active project the credentials
+// can see.
+func resolveProjects(ctx context.Context, spec string) ([]string, error) {
+	var projects []string
+	switch {
+	case spec == "all":
+		crmService, err := cloudresourcemanager.NewService(ctx, clientOptions...)
+		if err != nil {
+			return nil, fmt.Errorf("create Cloud Resource Manager service: %w", err)
+		}
+		err = crmService.Projects.List().Filter("lifecycleState:ACTIVE").Pages(ctx, func(resp *cloudresourcemanager.ListProjectsResponse) error {
+			for _, p := range resp.Projects {
+				projects = append(projects, p.ProjectId)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list projects: %w", err)
+		}
+	case strings.HasPrefix(spec, "@"):
+		data, err := os.ReadFile(spec[1:])
+		if err != nil {
+			return nil, err
+		}
+		for _, line := range strings.Split(string(data), "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				projects = append(projects, line)
+			}
+		}
+	default:
+		for _, p := range strings.Split(spec, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				projects = append(projects, p)
+			}
+		}
+	}
+	sort.Strings(projects)
+	return slices.Compact(projects), nil
+}
+
+// parseShard parses --shard i/n, where i counts from 1. An empty spec is
+// the single shard 1/1.
+func parseShard(spec string) (shard, shards int, err error) {
+	if spec == "" {
+		return 1, 1, nil
+	}
+	i, n, ok := strings.Cut(spec, "/")
+	if ok {
+		shard, err = strconv.Atoi(i)
+		if err == nil {
+			shards, err = strconv.Atoi(n)
+		}
+	}
+	if !ok || err != nil || shards < 1 || shard < 1 || shard > shards {
+		return 0, 0, fmt.Errorf("invalid --shard %q, want i/n with 1 <= i <= n", spec)
+	}
+	return shard, shards, nil
+}
+
+// shardProjects keeps the projects that hash into the shard. Hashing rather
+// than splitting the sorted list keeps every project in the same shard as
+// projects are created and deleted.
+func shardProjects(projects []string, shard, shards int) []string {
+	var kept []string
+	for _, p := range projects {
+		h := fnv.New32a()
+		h.Write([]byte(p))
+		if int(h.Sum32()%uint32(shards)) == shard-1 {
+			kept = append(kept, p)
+		}
+	}
+	return kept
+}
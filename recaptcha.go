@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+
+	"google.golang.org/api/recaptchaenterprise/v1"
+)
+
+// getRecaptchaKeys lists reCAPTCHA Enterprise keys with the platform they
+// are for, how a web key is integrated and where it may be used.
+func getRecaptchaKeys(ctx context.Context) {
+	recaptchaService, err := recaptchaenterprise.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create reCAPTCHA Enterprise service", "error", err)
+		return
+	}
+
+	count := 0
+	err = recaptchaService.Projects.Keys.List("projects/"+projectID).Pages(ctx, func(page *recaptchaenterprise.GoogleCloudRecaptchaenterpriseV1ListKeysResponse) error {
+		for _, key := range page.Keys {
+			platform, integration, allowed := "", "", ""
+			switch {
+			case key.WebSettings != nil:
+				platform, integration = "web", key.WebSettings.IntegrationType
+				allowed = strings.Join(key.WebSettings.AllowedDomains, ", ")
+				if key.WebSettings.AllowAllDomains {
+					allowed = "all domains"
+				}
+			case key.AndroidSettings != nil:
+				platform = "android"
+				allowed = strings.Join(key.AndroidSettings.AllowedPackageNames, ", ")
+				if key.AndroidSettings.AllowAllPackageNames {
+					allowed = "all packages"
+				}
+			case key.IosSettings != nil:
+				platform = "ios"
+				allowed = strings.Join(key.IosSettings.AllowedBundleIds, ", ")
+				if key.IosSettings.AllowAllBundleIds {
+					allowed = "all bundles"
+				}
+			}
+			info := fmt.Sprintf("Name: %s\nDisplay Name: %s\nPlatform: %s\nIntegration Type: %s\nAllowed: %s\nCreated: %s",
+				path.Base(key.Name), key.DisplayName, platform, integration, allowed, key.CreateTime)
+			writeResourceRaw("reCAPTCHA Key", info, key)
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		// Skip projects that don't have the reCAPTCHA Enterprise API enabled
+		slog.Debug("Skipping reCAPTCHA Enterprise keys", "error", err)
+	}
+	scanProgress.found(count)
+}
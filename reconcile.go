@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// exportAsset is one resource of a Cloud Asset Inventory export.
+type exportAsset struct {
+	Name      string
+	AssetType string
+	Location  string
+}
+
+// runReconcile compares the inventory at inventoryPath with the Cloud Asset
+// Inventory export at exportPath and writes the differences to w, for each
+// asset type the tool collects. It returns an error if they differ, so CI
+// can fail on it.
+func runReconcile(ctx context.Context, w io.Writer, inventoryPath, exportPath string) error {
+	rows, err := loadInventoryFile(inventoryPath)
+	if err != nil {
+		return err
+	}
+	rows = withoutScanMetadata(rows)
+	assets, err := readAssetExport(ctx, exportPath)
+	if err != nil {
+		return err
+	}
+
+	resourceTypes := map[string]string{}
+	for resourceType, kind := range resourceKinds {
+		if kind.assetType != "" {
+			resourceTypes[kind.assetType] = resourceType
+		}
+	}
+
+	// Resources are matched by full resource name, which normalize uses as
+	// the ID of every type with a resourceKind.
+	scanned := map[string]map[string]inventoryRow{}
+	uncomparable := 0
+	for _, row := range rows {
+		if row.AssetType == "" {
+			uncomparable++
+			continue
+		}
+		if scanned[row.AssetType] == nil {
+			scanned[row.AssetType] = map[string]inventoryRow{}
+		}
+		scanned[row.AssetType][row.ID] = row
+	}
+	exported := map[string]map[string]exportAsset{}
+	notCollected := map[string]int{}
+	for _, asset := range assets {
+		if _, ok := resourceTypes[asset.AssetType]; !ok {
+			notCollected[asset.AssetType]++
+			continue
+		}
+		if exported[asset.AssetType] == nil {
+			exported[asset.AssetType] = map[string]exportAsset{}
+		}
+		exported[asset.AssetType][asset.Name] = asset
+	}
+
+	fmt.Fprintf(w, "Reconciling %s (%d resources) with %s (%d assets)\n", inventoryPath, len(rows), exportPath, len(assets))
+	discrepancies := 0
+	for _, assetType := range sortedKeys(scanned) {
+		inScan, inExport := scanned[assetType], exported[assetType]
+		var onlyScan, onlyExport, moved []string
+		for _, id := range sortedKeys(inScan) {
+			asset, ok := inExport[id]
+			switch {
+			case !ok:
+				onlyScan = append(onlyScan, id)
+			case asset.Location != "" && !strings.EqualFold(asset.Location, inScan[id].Location):
+				moved = append(moved, fmt.Sprintf("%s (scan: %s, export: %s)", id, inScan[id].Location, asset.Location))
+			}
+		}
+		for _, name := range sortedKeys(inExport) {
+			if _, ok := inScan[name]; !ok {
+				onlyExport = append(onlyExport, name)
+			}
+		}
+
+		fmt.Fprintf(w, "\n%s (%s): %d scanned, %d exported\n", resourceTypes[assetType], assetType, len(inScan), len(inExport))
+		writeReconcileList(w, "Only in the scan", onlyScan)
+		writeReconcileList(w, "Only in the export", onlyExport)
+		writeReconcileList(w, "Location differs", moved)
+		discrepancies += len(onlyScan) + len(onlyExport) + len(moved)
+	}
+
+	// Types the scan has none of may be collectors that were skipped rather
+	// than resources it missed, so they are listed apart.
+	var unscanned []string
+	for _, assetType := range sortedKeys(exported) {
+		if scanned[assetType] == nil {
+			unscanned = append(unscanned, fmt.Sprintf("%s (%s): %d", resourceTypes[assetType], assetType, len(exported[assetType])))
+			discrepancies += len(exported[assetType])
+		}
+	}
+	if len(unscanned) > 0 {
+		fmt.Fprintln(w)
+		writeReconcileList(w, "Exported types the scan found none of", unscanned)
+	}
+	if len(notCollected) > 0 {
+		fmt.Fprintf(w, "\n%d exported assets of %d types the tool doesn't collect were not compared.\n", sum(notCollected), len(notCollected))
+	}
+	if uncomparable > 0 {
+		fmt.Fprintf(w, "%d scanned resources of types without a Cloud Asset Inventory equivalent were not compared.\n", uncomparable)
+	}
+
+	if discrepancies > 0 {
+		return fmt.Errorf("%d discrepancies found", discrepancies)
+	}
+	fmt.Fprintln(w, "\nThe scan and the export agree.")
+	return nil
+}
+
+func writeReconcileList(w io.Writer, title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "  %s (%d):\n", title, len(items))
+	for _, item := range items {
+		fmt.Fprintf(w, "    %s\n", item)
+	}
+}
+
+func sum(counts map[string]int) int {
+	n := 0
+	for _, c := range counts {
+		n += c
+	}
+	return n
+}
+
+// readAssetExport reads a Cloud Asset Inventory export from a file or a
+// gs:// object, or every object under a gs:// prefix ending in / (as
+// export writes with --per-asset-type). It accepts the newline-delimited
+// JSON of gcloud asset export and the JSON arrays of gcloud asset list and
+// search-all-resources.
+func readAssetExport(ctx context.Context, path string) ([]exportAsset, error) {
+	if !strings.HasPrefix(path, "gs://") {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return parseAssetExport(path, data)
+	}
+
+	objects, err := readGCSObjects(ctx, path, "")
+	if err != nil {
+		return nil, err
+	}
+	var assets []exportAsset
+	for _, obj := range objects {
+		found, err := parseAssetExport(obj.url, obj.data)
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, found...)
+	}
+	return assets, nil
+}
+
+func parseAssetExport(path string, data []byte) ([]exportAsset, error) {
+	type record struct {
+		Name           string `json:"name"`
+		AssetType      string `json:"asset_type"`
+		AssetTypeCamel string `json:"assetType"`
+		Location       string `json:"location"`
+		Resource       struct {
+			Location string `json:"location"`
+		} `json:"resource"`
+	}
+	var records []record
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("[")) {
+		if err := json.Unmarshal(data, &records); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+	} else {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		for dec.More() {
+			var rec record
+			if err := dec.Decode(&rec); err != nil {
+				return nil, fmt.Errorf("parse %s: asset %d: %w", path, len(records)+1, err)
+			}
+			records = append(records, rec)
+		}
+	}
+
+	assets := make([]exportAsset, 0, len(records))
+	for _, rec := range records {
+		asset := exportAsset{Name: rec.Name, AssetType: rec.AssetType, Location: rec.Location}
+		if asset.AssetType == "" {
+			asset.AssetType = rec.AssetTypeCamel
+		}
+		if asset.Location == "" {
+			asset.Location = rec.Resource.Location
+		}
+		if asset.Name == "" || asset.AssetType == "" {
+			return nil, fmt.Errorf("parse %s: not a Cloud Asset Inventory export, asset without a name or asset type", path)
+		}
+		assets = append(assets, asset)
+	}
+	return assets, nil
+}
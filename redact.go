@@ -0,0 +1,93 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var redactPII bool
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@([A-Za-z0-9-]+\.)+[A-Za-z]{2,}`)
+	ipv4Pattern  = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}(/\d{1,2})?\b`)
+	// ipv6Pattern finds candidate IPv6 addresses; net.ParseIP decides, so
+	// times and MAC addresses aren't taken for them.
+	ipv6Pattern = regexp.MustCompile(`[0-9A-Fa-f:.]*:[0-9A-Fa-f:.]*(/\d{1,3})?`)
+)
+
+// redactKey keys the hashes that replace redacted values. It is random per
+// run, so the same address maps to the same token throughout one report
+// but tokens can't be matched across reports or reversed by hashing
+// guesses.
+var redactKey = func() []byte {
+	key := make([]byte, 32)
+	rand.Read(key)
+	return key
+}()
+
+// redact masks personal data in a report entry: the local part of email
+// addresses other than service accounts, and IPv4 and IPv6 addresses. CIDR
+// ranges wider than a single host describe the network layout rather than
+// anyone's address, so they are kept.
+func redact(s string) string {
+	s = emailPattern.ReplaceAllStringFunc(s, func(email string) string {
+		local, domain, _ := strings.Cut(email, "@")
+		if strings.HasSuffix(domain, "gserviceaccount.com") {
+			return email
+		}
+		return "redacted-" + redactToken(local) + "@" + domain
+	})
+	// IPv6 first, so an IPv4-mapped address becomes one token.
+	s = redactIPv6(s)
+	return ipv4Pattern.ReplaceAllStringFunc(s, func(ip string) string {
+		if _, prefix, ok := strings.Cut(ip, "/"); ok {
+			if n, err := strconv.Atoi(prefix); err == nil && n < 32 {
+				return ip
+			}
+		}
+		return "ip-" + redactToken(ip)
+	})
+}
+
+// redactIPv6 masks the IPv6 addresses in s, except ranges wider than a
+// host. A candidate must stand alone, not be part of a longer word such as
+// std::string.
+func redactIPv6(s string) string {
+	var b strings.Builder
+	last := 0
+	for _, m := range ipv6Pattern.FindAllStringIndex(s, -1) {
+		start, end := m[0], m[1]
+		candidate := s[start:end]
+		addr, prefix, hasPrefix := strings.Cut(candidate, "/")
+		if strings.Count(addr, ":") < 2 || net.ParseIP(addr) == nil ||
+			start > 0 && isWordByte(s[start-1]) || end < len(s) && isWordByte(s[end]) {
+			continue
+		}
+		if hasPrefix {
+			if n, err := strconv.Atoi(prefix); err == nil && n < 128 {
+				continue
+			}
+		}
+		b.WriteString(s[last:start])
+		b.WriteString("ip-" + redactToken(candidate))
+		last = end
+	}
+	b.WriteString(s[last:])
+	return b.String()
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || '0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+func redactToken(value string) string {
+	mac := hmac.New(sha256.New, redactKey)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))[:8]
+}
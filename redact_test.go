@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRedact(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		masked  []string
+		kept    []string
+		wantOut string
+	}{
+		{
+			name:   "user email",
+			in:     "Members: user:alice@example.com, serviceAccount:scanner@my-project.iam.gserviceaccount.com",
+			masked: []string{"alice@"},
+			kept:   []string{"@example.com", "scanner@my-project.iam.gserviceaccount.com"},
+		},
+		{
+			name:   "IPv4 address and host range",
+			in:     "External IP: 34.120.1.2\nSource Ranges: 203.0.113.7/32, 10.0.0.0/8",
+			masked: []string{"34.120.1.2", "203.0.113.7"},
+			kept:   []string{"10.0.0.0/8"},
+		},
+		{
+			name:   "IPv6 address",
+			in:     "IPv6 Address: 2600:1900:4000:8d2a:8000:0:0:0\nNext Hop: fe80::1",
+			masked: []string{"2600:1900:4000:8d2a:8000:0:0:0", "fe80::1"},
+		},
+		{
+			name:   "IPv6 host and network ranges",
+			in:     "Source Ranges: 2001:db8::5/128, 2600:1900:4000:8d2a::/64",
+			masked: []string{"2001:db8::5"},
+			kept:   []string{"2600:1900:4000:8d2a::/64"},
+		},
+		{
+			name:   "IPv4-mapped IPv6 address",
+			in:     "Address: ::ffff:192.0.2.1",
+			masked: []string{"::ffff", "192.0.2.1"},
+		},
+		{
+			name:    "times and MAC addresses",
+			in:      "Created: 2024-01-15T10:30:45.123-08:00\nMAC: 42:01:0a:80:00:02\nType: std::string",
+			wantOut: "Created: 2024-01-15T10:30:45.123-08:00\nMAC: 42:01:0a:80:00:02\nType: std::string",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := redact(tt.in)
+			for _, s := range tt.masked {
+				if strings.Contains(out, s) {
+					t.Errorf("redact(%q) = %q, still contains %q", tt.in, out, s)
+				}
+			}
+			for _, s := range tt.kept {
+				if !strings.Contains(out, s) {
+					t.Errorf("redact(%q) = %q, lost %q", tt.in, out, s)
+				}
+			}
+			if tt.wantOut != "" && out != tt.wantOut {
+				t.Errorf("redact(%q) = %q, want %q", tt.in, out, tt.wantOut)
+			}
+		})
+	}
+
+	// The same address gets the same token, so links survive.
+	if a, b := redact("Next Hop: fe80::1"), redact("Peer: fe80::1"); a[len("Next Hop: "):] != b[len("Peer: "):] {
+		t.Errorf("fe80::1 redacted to %q and %q", a, b)
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"google.golang.org/api/assuredworkloads/v1"
+	"google.golang.org/api/cloudresourcemanager/v1"
+)
+
+// resourceLocationsConstraint is the organization policy that restricts
+// where resources may be created.
+const resourceLocationsConstraint = "constraints/gcp.resourceLocations"
+
+// getAssuredWorkloads lists the organization's Assured Workloads in the
+// scanned regions, noting which one the project belongs to. Workloads are
+// folders created under a compliance regime such as FedRAMP or EU
+// sovereignty controls.
+func getAssuredWorkloads(ctx context.Context) {
+	crmService, err := cloudresourcemanager.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create Cloud Resource Manager service", "error", err)
+		return
+	}
+	project, err := crmService.Projects.Get(projectID).Context(ctx).Do()
+	if err != nil {
+		slog.Error("Failed to get project", "error", err)
+		return
+	}
+	orgID, err := organizationID(ctx, crmService)
+	if err != nil {
+		slog.Error("Failed to get project ancestry", "error", err)
+		return
+	}
+	if orgID == "" {
+		slog.Debug("Skipping Assured Workloads, project has no organization")
+		return
+	}
+
+	awService, err := assuredworkloads.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create Assured Workloads service", "error", err)
+		return
+	}
+
+	count := 0
+	for _, region := range regions {
+		parent := fmt.Sprintf("organizations/%s/locations/%s", orgID, region)
+		workloads, err := awService.Organizations.Locations.Workloads.List(parent).Context(ctx).Do()
+		if err != nil {
+			// Listing workloads needs organization-level access
+			slog.Debug("Skipping Assured Workloads", "region", region, "error", err)
+			continue
+		}
+		for _, workload := range workloads.Workloads {
+			var folders []string
+			inWorkload := false
+			for _, resource := range workload.Resources {
+				switch resource.ResourceType {
+				case "CONSUMER_FOLDER":
+					folders = append(folders, fmt.Sprintf("folders/%d", resource.ResourceId))
+				case "CONSUMER_PROJECT", "ENCRYPTION_KEYS_PROJECT":
+					inWorkload = inWorkload || resource.ResourceId == project.ProjectNumber
+				}
+			}
+			violations := int64(0)
+			if workload.ComplianceStatus != nil {
+				violations = workload.ComplianceStatus.ActiveViolationCount
+			}
+			info := fmt.Sprintf("Name: %s\nRegion: %s\nCompliance Regime: %s\nFolder: %s\nContains Project: %t\nSovereign Controls: %t\nActive Violations: %d\nCreated: %s",
+				workload.DisplayName, region, workload.ComplianceRegime, strings.Join(folders, ", "), inWorkload,
+				workload.EnableSovereignControls, violations, workload.CreateTime)
+			writeResourceRaw("Assured Workload", info, workload)
+			count++
+		}
+	}
+	scanProgress.found(count)
+}
+
+// dataLocation returns where a row stores data, for the resource types
+// the data residency summary covers.
+func dataLocation(row inventoryRow) string {
+	switch row.ResourceType {
+	case "Storage Bucket", "BigQuery Dataset":
+		return strings.ToLower(row.field("Location"))
+	case "Cloud SQL Instance":
+		return row.field("Region")
+	}
+	return ""
+}
+
+// runDataResidency summarizes which locations hold data in buckets, Cloud
+// SQL instances and BigQuery datasets, and compares them with the locations
+// the project's resource locations policy allows. Resources outside them
+// get an Outside Allowed Locations field.
+func runDataResidency(ctx context.Context) error {
+	crmService, err := cloudresourcemanager.NewService(ctx, clientOptions...)
+	if err != nil {
+		return fmt.Errorf("create Cloud Resource Manager service: %w", err)
+	}
+	policy, err := crmService.Projects.GetEffectiveOrgPolicy("projects/"+projectID, &cloudresourcemanager.GetEffectiveOrgPolicyRequest{
+		Constraint: resourceLocationsConstraint,
+	}).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("get %s policy: %w", resourceLocationsConstraint, err)
+	}
+	var allowed, denied []string
+	if policy.ListPolicy != nil && policy.ListPolicy.AllValues == "" {
+		allowed, denied = policy.ListPolicy.AllowedValues, policy.ListPolicy.DeniedValues
+	}
+
+	type locationSummary struct {
+		counts  map[string]int
+		allowed bool
+	}
+	summary := map[string]*locationSummary{}
+	var outside []*inventoryRow
+	for i := range inventory {
+		row := &inventory[i]
+		location := dataLocation(*row)
+		if location == "" {
+			continue
+		}
+		s := summary[location]
+		if s == nil {
+			s = &locationSummary{counts: map[string]int{}, allowed: locationAllowed(location, allowed, denied)}
+			summary[location] = s
+		}
+		s.counts[row.ResourceType]++
+		if !s.allowed {
+			row.setField("Outside Allowed Locations", "true")
+			outside = append(outside, row)
+		}
+	}
+
+	writeSection("DATA RESIDENCY")
+	policyDesc := "any location (no resource locations policy)"
+	if len(allowed) > 0 || len(denied) > 0 {
+		policyDesc = "allowed " + strings.Join(allowed, ", ")
+		if len(denied) > 0 {
+			policyDesc += "; denied " + strings.Join(denied, ", ")
+		}
+	}
+	fmt.Fprintf(report, "\nPolicy: %s\n", policyDesc)
+	if len(summary) == 0 {
+		fmt.Fprintln(report, "\nNo buckets, Cloud SQL instances or BigQuery datasets found.")
+		return nil
+	}
+	fmt.Fprintf(report, "\n%-24s %8s %8s %9s  %s", "Location", "Buckets", "SQL", "Datasets", "Allowed")
+	for _, location := range sortedKeys(summary) {
+		s := summary[location]
+		fmt.Fprintf(report, "\n%-24s %8d %8d %9d  %t", location,
+			s.counts["Storage Bucket"], s.counts["Cloud SQL Instance"], s.counts["BigQuery Dataset"], s.allowed)
+	}
+	fmt.Fprintln(report)
+	if len(outside) > 0 {
+		fmt.Fprintln(report, "\nOutside allowed locations:")
+		for _, row := range outside {
+			fmt.Fprintf(report, "\n%-24s %-40s %s", row.ResourceType, row.Name, dataLocation(*row))
+		}
+		fmt.Fprintln(report)
+		slog.Warn("Data stored outside the allowed locations", "resources", len(outside))
+	}
+	return nil
+}
+
+// locationAllowed reports whether a resources location policy permits
+// location. Policy values are locations ("us-central1"), or value groups
+// ("in:eu-locations", "in:us-central1-locations") that cover every location
+// whose name starts with the group's.
+func locationAllowed(location string, allowed, denied []string) bool {
+	matches := func(values []string) bool {
+		return slices.ContainsFunc(values, func(v string) bool {
+			v = strings.ToLower(v)
+			if group, ok := strings.CutPrefix(v, "in:"); ok {
+				group = strings.TrimSuffix(group, "-locations")
+				if group == "eu" && strings.HasPrefix(location, "europe-") {
+					return true
+				}
+				return location == group || strings.HasPrefix(location, group+"-")
+			}
+			return location == v
+		})
+	}
+	if matches(denied) {
+		return false
+	}
+	return len(allowed) == 0 || matches(allowed)
+}
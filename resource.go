@@ -0,0 +1,184 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path"
+	"strings"
+)
+
+// resourceKind maps a report resource type onto Cloud Asset Inventory: its
+// asset type and the pattern of its full resource name. In the pattern,
+// {project} is the project ID, {name} the resource's name and any other
+// {Key} the value of that field. assetType is empty for types Cloud Asset
+// Inventory doesn't list, which still get their full resource name as ID.
+type resourceKind struct {
+	assetType string
+	name      string
+}
+
+var resourceKinds = map[string]resourceKind{
+	"Project":                         {"cloudresourcemanager.googleapis.com/Project", "//cloudresourcemanager.googleapis.com/projects/{Project Number}"},
+	"Compute Instance":                {"compute.googleapis.com/Instance", "//compute.googleapis.com/projects/{project}/zones/{Zone}/instances/{name}"},
+	"Persistent Disk":                 {"compute.googleapis.com/Disk", "//compute.googleapis.com/projects/{project}/zones/{Zone}/disks/{name}"},
+	"Snapshot":                        {"compute.googleapis.com/Snapshot", "//compute.googleapis.com/projects/{project}/global/snapshots/{name}"},
+	"Image":                           {"compute.googleapis.com/Image", "//compute.googleapis.com/projects/{project}/global/images/{name}"},
+	"Machine Image":                   {"compute.googleapis.com/MachineImage", "//compute.googleapis.com/projects/{project}/global/machineImages/{name}"},
+	"Reservation":                     {"compute.googleapis.com/Reservation", "//compute.googleapis.com/projects/{project}/zones/{Zone}/reservations/{name}"},
+	"Sole-Tenant Node Group":          {"compute.googleapis.com/NodeGroup", "//compute.googleapis.com/projects/{project}/zones/{Zone}/nodeGroups/{name}"},
+	"VPC Network":                     {"compute.googleapis.com/Network", "//compute.googleapis.com/projects/{project}/global/networks/{name}"},
+	"Subnet":                          {"compute.googleapis.com/Subnetwork", "//compute.googleapis.com/projects/{project}/regions/{Region}/subnetworks/{name}"},
+	"Firewall Rule":                   {"compute.googleapis.com/Firewall", "//compute.googleapis.com/projects/{project}/global/firewalls/{name}"},
+	"Route":                           {"compute.googleapis.com/Route", "//compute.googleapis.com/projects/{project}/global/routes/{name}"},
+	"Static IP Address":               {"compute.googleapis.com/Address", "//compute.googleapis.com/projects/{project}/regions/{Region}/addresses/{name}"},
+	"Global Static IP Address":        {"compute.googleapis.com/GlobalAddress", "//compute.googleapis.com/projects/{project}/global/addresses/{name}"},
+	"Forwarding Rule":                 {"compute.googleapis.com/ForwardingRule", "//compute.googleapis.com/projects/{project}/regions/{Region}/forwardingRules/{name}"},
+	"Packet Mirroring Policy":         {"compute.googleapis.com/PacketMirroring", "//compute.googleapis.com/projects/{project}/regions/{Region}/packetMirrorings/{name}"},
+	"Global Forwarding Rule":          {"compute.googleapis.com/GlobalForwardingRule", "//compute.googleapis.com/projects/{project}/global/forwardingRules/{name}"},
+	"Cloud Armor Policy":              {"compute.googleapis.com/SecurityPolicy", "//compute.googleapis.com/projects/{project}/global/securityPolicies/{name}"},
+	"TPU Node":                        {"tpu.googleapis.com/Node", "//tpu.googleapis.com/projects/{project}/locations/{Zone}/nodes/{name}"},
+	"GKE Cluster":                     {"container.googleapis.com/Cluster", "//container.googleapis.com/projects/{project}/locations/{Location}/clusters/{name}"},
+	"Kubernetes Namespace":            {"k8s.io/Namespace", "//container.googleapis.com/projects/{project}/locations/{Location}/clusters/{Cluster}/k8s/namespaces/{name}"},
+	"Kubernetes Deployment":           {"apps.k8s.io/Deployment", "//container.googleapis.com/projects/{project}/locations/{Location}/clusters/{Cluster}/k8s/namespaces/{Namespace}/apps/deployments/{name}"},
+	"Kubernetes LoadBalancer Service": {"k8s.io/Service", "//container.googleapis.com/projects/{project}/locations/{Location}/clusters/{Cluster}/k8s/namespaces/{Namespace}/services/{name}"},
+	"Kubernetes Ingress":              {"networking.k8s.io/Ingress", "//container.googleapis.com/projects/{project}/locations/{Location}/clusters/{Cluster}/k8s/namespaces/{Namespace}/networking.k8s.io/ingresses/{name}"},
+	"Cloud SQL Instance":              {"sqladmin.googleapis.com/Instance", "//cloudsql.googleapis.com/projects/{project}/instances/{name}"},
+	"Cloud SQL Database":              {"", "//cloudsql.googleapis.com/projects/{project}/instances/{Instance}/databases/{name}"},
+	"Cloud SQL User":                  {"", "//cloudsql.googleapis.com/projects/{project}/instances/{Instance}/users/{name}"},
+	"Storage Bucket":                  {"storage.googleapis.com/Bucket", "//storage.googleapis.com/{name}"},
+	"BigQuery Dataset":                {"bigquery.googleapis.com/Dataset", "//bigquery.googleapis.com/projects/{project}/datasets/{name}"},
+	"Pub/Sub Topic":                   {"pubsub.googleapis.com/Topic", "//pubsub.googleapis.com/projects/{project}/topics/{name}"},
+	"Service Account":                 {"iam.googleapis.com/ServiceAccount", "//iam.googleapis.com/projects/{project}/serviceAccounts/{Unique ID}"},
+	"Custom Role":                     {"iam.googleapis.com/Role", "//iam.googleapis.com/projects/{project}/roles/{name}"},
+	"Deployment Manager Deployment":   {"deploymentmanager.googleapis.com/Deployment", "//deploymentmanager.googleapis.com/projects/{project}/global/deployments/{name}"},
+	"Workbench Instance":              {"notebooks.googleapis.com/Instance", "//notebooks.googleapis.com/projects/{project}/locations/{Zone}/instances/{name}"},
+	"Cloud Run Service":               {"run.googleapis.com/Service", "//run.googleapis.com/projects/{project}/locations/{Region}/services/{name}"},
+	"Batch Job":                       {"batch.googleapis.com/Job", "//batch.googleapis.com/projects/{project}/locations/{Region}/jobs/{name}"},
+	"Looker Instance":                 {"looker.googleapis.com/Instance", "//looker.googleapis.com/projects/{project}/locations/{Region}/instances/{name}"},
+	"Transfer Job":                    {"storagetransfer.googleapis.com/TransferJob", "//storagetransfer.googleapis.com/projects/{project}/transferJobs/{name}"},
+	"Healthcare Dataset":              {"healthcare.googleapis.com/Dataset", "//healthcare.googleapis.com/projects/{project}/locations/{Region}/datasets/{name}"},
+	"FHIR Store":                      {"healthcare.googleapis.com/FhirStore", "//healthcare.googleapis.com/projects/{project}/locations/{Region}/datasets/{Dataset}/fhirStores/{name}"},
+	"DICOM Store":                     {"healthcare.googleapis.com/DicomStore", "//healthcare.googleapis.com/projects/{project}/locations/{Region}/datasets/{Dataset}/dicomStores/{name}"},
+	"HL7v2 Store":                     {"healthcare.googleapis.com/Hl7V2Store", "//healthcare.googleapis.com/projects/{project}/locations/{Region}/datasets/{Dataset}/hl7V2Stores/{name}"},
+	"reCAPTCHA Key":                   {"recaptchaenterprise.googleapis.com/Key", "//recaptchaenterprise.googleapis.com/projects/{project}/keys/{name}"},
+}
+
+// fallbackParents lists, for types without a resourceKind whose names are
+// only unique within a parent resource, the fields naming the parents,
+// outermost first. normalize puts them in the fallback ID so that, say, two
+// Dataplex zones of the same name in different lakes stay apart.
+var fallbackParents = map[string][]string{
+	"Access Level":               {"Policy"},
+	"Service Perimeter":          {"Policy"},
+	"Cloud Deploy Release":       {"Pipeline"},
+	"Dataform Workspace":         {"Repository"},
+	"Dataplex Zone":              {"Lake"},
+	"Dataplex Asset":             {"Lake", "Zone"},
+	"Policy Tag":                 {"Taxonomy"},
+	"Identity Provider":          {"Tenant"},
+	"Workload Identity Provider": {"Pool"},
+	"Service Directory Service":  {"Namespace"},
+	"Service Directory Endpoint": {"Namespace", "Service"},
+	"VPC Peering":                {"Network"},
+	"Peering Route":              {"Network"},
+	"Kafka Topic":                {"Cluster"},
+	"Dialogflow Webhook":         {"Agent"},
+	"Dialogflow Environment":     {"Agent"},
+}
+
+// normalize fills in the row's normalized attributes from its type and
+// fields: the full resource name used as its stable ID, asset type,
+// location, zone (for zonal resources, as the API reported it), labels and
+// create time. Types without a resourceKind, or rows
+// missing a field their name needs, get an ID in the same shape under
+// //gcp_footprint, with their fallbackParents, so every resource still has
+// one.
+func (r *inventoryRow) normalize() {
+	r.Location = r.location()
+	if regionOf(r.Location) != r.Location {
+		r.Zone = r.Location
+	}
+	r.CreateTime = r.field("Created")
+	if r.CreateTime == "" {
+		r.CreateTime = r.field("Create Time")
+	}
+	if labels := r.field("Labels"); labels != "" {
+		r.Labels = map[string]string{}
+		for _, pair := range strings.Split(labels, ", ") {
+			k, v, _ := strings.Cut(pair, "=")
+			r.Labels[k] = v
+		}
+	}
+
+	if kind, ok := resourceKinds[r.ResourceType]; ok {
+		if id, ok := r.expandName(kind.name); ok {
+			r.ID, r.AssetType = id, kind.assetType
+			return
+		}
+	}
+	slug := strings.ToLower(strings.NewReplacer(" ", "-", "/", "-").Replace(r.ResourceType))
+	id := "//gcp_footprint/projects/" + r.ProjectID + "/locations/" + r.Location + "/" + slug
+	for _, key := range fallbackParents[r.ResourceType] {
+		// Some parents, such as a peering's network, are self-links.
+		parent := r.field(key)
+		if parent != "" {
+			parent = path.Base(parent)
+		}
+		id += "/" + parent
+	}
+	r.ID = id + "/" + r.Name
+	if r.ResourceType == "IAM Binding" {
+		// A role can be bound once without a condition and once per
+		// condition, and condition titles needn't be unique.
+		if condition := r.field("Condition"); condition != "" {
+			sum := sha256.Sum256([]byte(condition))
+			r.ID += "/conditions/" + hex.EncodeToString(sum[:6])
+		}
+	}
+}
+
+// expandName fills in a resourceKind name pattern, failing if a field it
+// needs is empty.
+func (r inventoryRow) expandName(pattern string) (string, bool) {
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(pattern, '{')
+		if start < 0 {
+			b.WriteString(pattern)
+			return b.String(), true
+		}
+		end := strings.IndexByte(pattern[start:], '}') + start
+		b.WriteString(pattern[:start])
+
+		var value string
+		switch key := pattern[start+1 : end]; key {
+		case "project":
+			value = r.ProjectID
+		case "name":
+			value = r.Name
+		case "Region", "Zone":
+			// Compute reports these as self-links in older inventories.
+			if value = r.field(key); value != "" {
+				value = path.Base(value)
+			}
+		default:
+			value = r.field(key)
+		}
+		if value == "" {
+			return "", false
+		}
+		b.WriteString(value)
+		pattern = pattern[end+1:]
+	}
+}
+
+// location is the zone, region or multi-region the resource lives in, in
+// lower case as Cloud Asset Inventory reports it, or "global".
+func (r inventoryRow) location() string {
+	for _, key := range []string{"Zone", "Region", "Location"} {
+		if v := r.field(key); v != "" {
+			return strings.ToLower(path.Base(v))
+		}
+	}
+	return rowRegion(r)
+}
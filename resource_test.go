@@ -0,0 +1,173 @@
+package main
+
+import (
+	"testing"
+
+	compute "google.golang.org/api/compute/v1"
+)
+
+func TestNormalizeSelfLinks(t *testing.T) {
+	const regionLink = "https://www.googleapis.com/compute/v1/projects/my-project/regions/us-central1"
+	tests := []struct {
+		name         string
+		resourceType string
+		info         string
+		wantID       string
+		wantLocation string
+	}{
+		{
+			name:         "address from addressInfo",
+			resourceType: "Static IP Address",
+			info:         addressInfo(&compute.Address{Name: "web-ip", Address: "10.0.0.5", Region: regionLink}),
+			wantID:       "//compute.googleapis.com/projects/my-project/regions/us-central1/addresses/web-ip",
+			wantLocation: "us-central1",
+		},
+		{
+			name:         "global address from addressInfo",
+			resourceType: "Global Static IP Address",
+			info:         addressInfo(&compute.Address{Name: "lb-ip", Address: "34.1.2.3"}),
+			wantID:       "//compute.googleapis.com/projects/my-project/global/addresses/lb-ip",
+			wantLocation: "global",
+		},
+		{
+			name:         "forwarding rule from forwardingRuleInfo",
+			resourceType: "Forwarding Rule",
+			info:         forwardingRuleInfo(&compute.ForwardingRule{Name: "ilb", Region: regionLink}),
+			wantID:       "//compute.googleapis.com/projects/my-project/regions/us-central1/forwardingRules/ilb",
+			wantLocation: "us-central1",
+		},
+		{
+			// Inventories saved before the collectors shortened the region
+			// still hold the self-link.
+			name:         "subnet with a self-link region",
+			resourceType: "Subnet",
+			info:         "Name: default\nNetwork: default\nIP Range: 10.128.0.0/20\nRegion: " + regionLink,
+			wantID:       "//compute.googleapis.com/projects/my-project/regions/us-central1/subnetworks/default",
+			wantLocation: "us-central1",
+		},
+		{
+			name:         "instance with a self-link zone",
+			resourceType: "Compute Instance",
+			info:         "Name: vm-1\nZone: https://www.googleapis.com/compute/v1/projects/my-project/zones/us-central1-a",
+			wantID:       "//compute.googleapis.com/projects/my-project/zones/us-central1-a/instances/vm-1",
+			wantLocation: "us-central1-a",
+		},
+		{
+			name:         "project by number",
+			resourceType: "Project",
+			info:         "Name: My Project\nProject ID: my-project\nProject Number: 123456789012",
+			wantID:       "//cloudresourcemanager.googleapis.com/projects/123456789012",
+			wantLocation: "global",
+		},
+		{
+			name:         "Cloud SQL database on its instance",
+			resourceType: "Cloud SQL Database",
+			info:         "Name: postgres\nInstance: orders-db\nRegion: us-central1\nCharset: UTF8",
+			wantID:       "//cloudsql.googleapis.com/projects/my-project/instances/orders-db/databases/postgres",
+			wantLocation: "us-central1",
+		},
+		{
+			name:         "Cloud SQL user with a host",
+			resourceType: "Cloud SQL User",
+			info:         "Name: root@%\nInstance: legacy-mysql\nRegion: europe-west1\nHost: %\nType: BUILT_IN",
+			wantID:       "//cloudsql.googleapis.com/projects/my-project/instances/legacy-mysql/users/root@%",
+			wantLocation: "europe-west1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row := inventoryRow{ProjectID: "my-project", ResourceType: tt.resourceType, Fields: parseFields(tt.info)}
+			row.Name = row.Fields[0].Value
+			row.normalize()
+			if row.ID != tt.wantID {
+				t.Errorf("ID = %q, want %q", row.ID, tt.wantID)
+			}
+			if row.AssetType != resourceKinds[tt.resourceType].assetType {
+				t.Errorf("AssetType = %q, want %q", row.AssetType, resourceKinds[tt.resourceType].assetType)
+			}
+			if row.Location != tt.wantLocation {
+				t.Errorf("Location = %q, want %q", row.Location, tt.wantLocation)
+			}
+		})
+	}
+}
+
+func TestNormalizeFallbackIDs(t *testing.T) {
+	tests := []struct {
+		name         string
+		resourceType string
+		section      string
+		info         string
+		wantID       string
+	}{
+		{
+			name:         "type without parents",
+			resourceType: "Attestor",
+			info:         "Name: built-by-ci\nDescription: CI builds",
+			wantID:       "//gcp_footprint/projects/my-project/locations/global/attestor/built-by-ci",
+		},
+		{
+			name:         "unconditional binding",
+			resourceType: "IAM Binding",
+			info:         "Role: roles/editor\nMembers: group:dev@example.com",
+			wantID:       "//gcp_footprint/projects/my-project/locations/global/iam-binding/roles/editor",
+		},
+		{
+			name:         "conditional binding of the same role",
+			resourceType: "IAM Binding",
+			info:         "Role: roles/editor\nMembers: group:dev@example.com\nCondition Title: weekdays\nCondition: request.time.getDayOfWeek() < 5\nCondition Description: ",
+			wantID:       "//gcp_footprint/projects/my-project/locations/global/iam-binding/roles/editor/conditions/0671cad02097",
+		},
+		{
+			name:         "zone in one lake",
+			resourceType: "Dataplex Zone",
+			section:      "REGION: us-central1",
+			info:         "Name: raw\nLake: sales\nType: RAW",
+			wantID:       "//gcp_footprint/projects/my-project/locations/us-central1/dataplex-zone/sales/raw",
+		},
+		{
+			name:         "zone of the same name in another lake",
+			resourceType: "Dataplex Zone",
+			section:      "REGION: us-central1",
+			info:         "Name: raw\nLake: marketing\nType: RAW",
+			wantID:       "//gcp_footprint/projects/my-project/locations/us-central1/dataplex-zone/marketing/raw",
+		},
+		{
+			name:         "peering route under a self-link network",
+			resourceType: "Peering Route",
+			section:      "REGION: us-central1",
+			info:         "Name: to-shared/10.8.0.0/16\nNetwork: https://www.googleapis.com/compute/v1/projects/my-project/global/networks/prod\nPeering: to-shared",
+			wantID:       "//gcp_footprint/projects/my-project/locations/us-central1/peering-route/prod/to-shared/10.8.0.0/16",
+		},
+		{
+			name:         "Kafka topic in its cluster",
+			resourceType: "Kafka Topic",
+			section:      "REGION: us-central1",
+			info:         "Name: orders\nCluster: events\nPartitions: 6",
+			wantID:       "//gcp_footprint/projects/my-project/locations/us-central1/kafka-topic/events/orders",
+		},
+		{
+			name:         "Dialogflow environment of its agent",
+			resourceType: "Dialogflow Environment",
+			info:         "Name: draft\nAgent: support-bot\nLocation: global",
+			wantID:       "//gcp_footprint/projects/my-project/locations/global/dialogflow-environment/support-bot/draft",
+		},
+		{
+			name:         "missing parent",
+			resourceType: "Service Directory Endpoint",
+			section:      "REGION: us-central1",
+			info:         "Name: primary\nService: api",
+			wantID:       "//gcp_footprint/projects/my-project/locations/us-central1/service-directory-endpoint//api/primary",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			row := inventoryRow{ProjectID: "my-project", ResourceType: tt.resourceType, Section: tt.section, Fields: parseFields(tt.info)}
+			row.Name = row.Fields[0].Value
+			row.normalize()
+			if row.ID != tt.wantID {
+				t.Errorf("ID = %q, want %q", row.ID, tt.wantID)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+	htransport "google.golang.org/api/transport/http"
+)
+
+// listREST calls a Google REST list method directly and hands each page to
+// fn, for APIs that are newer than the generated clients in our pinned
+// google.golang.org/api release. T describes the response body; page tokens
+// are followed here.
+func listREST[T any](ctx context.Context, endpoint string, fn func(page *T) error) error {
+	client, _, err := htransport.NewClient(ctx, append(clientOptions, option.WithScopes(cloudPlatformScope))...)
+	if err != nil {
+		return fmt.Errorf("create HTTP client: %w", err)
+	}
+
+	pageToken := ""
+	for {
+		u, err := url.Parse(endpoint)
+		if err != nil {
+			return err
+		}
+		if pageToken != "" {
+			q := u.Query()
+			q.Set("pageToken", pageToken)
+			u.RawQuery = q.Encode()
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		if err := googleapi.CheckResponse(resp); err != nil {
+			resp.Body.Close()
+			return err
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		var page T
+		var next struct {
+			NextPageToken string `json:"nextPageToken"`
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return fmt.Errorf("parse response: %w", err)
+		}
+		if err := json.Unmarshal(body, &next); err != nil {
+			return fmt.Errorf("parse response: %w", err)
+		}
+		if err := fn(&page); err != nil {
+			return err
+		}
+		if next.NextPageToken == "" {
+			return nil
+		}
+		pageToken = next.NextPageToken
+	}
+}
+
+// callREST sends one request to a Google REST API with body, if any, as
+// JSON, and decodes the response into out unless it is nil. Like listREST
+// it is for APIs the generated clients don't cover well enough.
+func callREST(ctx context.Context, method, endpoint string, body, out any) error {
+	client, _, err := htransport.NewClient(ctx, append(clientOptions, option.WithScopes(cloudPlatformScope))...)
+	if err != nil {
+		return fmt.Errorf("create HTTP client: %w", err)
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reqBody)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := googleapi.CheckResponse(resp); err != nil {
+		return err
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("parse response: %w", err)
+	}
+	return nil
+}
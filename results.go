@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+var (
+	resultsStoreURL  string
+	resultsRetention time.Duration
+
+	// results is the store --results-store names, nil without one.
+	results resultsStore
+)
+
+// resultsStore keeps the history of complete scans outside the local
+// state files, so --daemon and serve can run without a persistent disk and
+// still diff against earlier scans and show trends.
+type resultsStore interface {
+	// save stores a complete scan.
+	save(ctx context.Context, state scanState) error
+	// list returns the project's stored scans, newest first.
+	list(ctx context.Context) ([]storedScan, error)
+	// load returns the project's scan from scanTime.
+	load(ctx context.Context, scanTime time.Time) (scanState, error)
+	// remove deletes the project's scan from scanTime.
+	remove(ctx context.Context, scanTime time.Time) error
+}
+
+// storedScan summarizes one stored scan, enough to chart trends without
+// loading every inventory.
+type storedScan struct {
+	ProjectID string         `json:"project_id"`
+	ScanTime  time.Time      `json:"scan_time"`
+	Resources int            `json:"resources"`
+	ByType    map[string]int `json:"by_type"`
+}
+
+func summarizeScan(state scanState) storedScan {
+	s := storedScan{ProjectID: state.ProjectID, ScanTime: state.ScanTime, Resources: len(state.Inventory), ByType: map[string]int{}}
+	for _, row := range state.Inventory {
+		s.ByType[row.ResourceType]++
+	}
+	return s
+}
+
+// scanStamp names a stored scan by its time, in UTC so names sort in time
+// order.
+func scanStamp(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// openResultsStore returns the store for url: gs://bucket/path/ keeps each
+// scan as JSON objects, firestore://project/collection as Firestore
+// documents in the project's (default) database.
+func openResultsStore(url string) (resultsStore, error) {
+	switch {
+	case strings.HasPrefix(url, "gs://"):
+		bucket, prefix, err := parseGCSURL(url)
+		if err != nil {
+			return nil, err
+		}
+		return &gcsResults{bucket: bucket, prefix: prefix + projectID + "/"}, nil
+	case strings.HasPrefix(url, "firestore://"):
+		project, collection, _ := strings.Cut(strings.TrimPrefix(url, "firestore://"), "/")
+		collection = strings.Trim(collection, "/")
+		if project == "" || collection == "" || strings.Contains(collection, "/") {
+			return nil, fmt.Errorf("invalid Firestore URL %q, expected firestore://project/collection", url)
+		}
+		return &firestoreResults{project: project, collection: collection}, nil
+	}
+	return nil, fmt.Errorf("unsupported results store %q, expected gs://bucket/path/ or firestore://project/collection", url)
+}
+
+// storeResults saves the finished scan to the results store and then
+// removes scans older than --results-retention. The newest scan is always
+// kept.
+func storeResults(ctx context.Context, state scanState) {
+	state.SchemaVersion = inventorySchemaVersion
+	if err := results.save(ctx, state); err != nil {
+		slog.Error("Failed to store scan results", "store", resultsStoreURL, "error", err)
+		return
+	}
+	if resultsRetention <= 0 {
+		return
+	}
+	scans, err := results.list(ctx)
+	if err != nil {
+		slog.Error("Failed to list stored scans", "store", resultsStoreURL, "error", err)
+		return
+	}
+	cutoff := time.Now().Add(-resultsRetention)
+	for i, scan := range scans {
+		if i == 0 || !scan.ScanTime.Before(cutoff) {
+			continue
+		}
+		if err := results.remove(ctx, scan.ScanTime); err != nil {
+			slog.Error("Failed to remove expired scan", "scan_time", scan.ScanTime, "error", err)
+			continue
+		}
+		slog.Info("Removed expired scan", "scan_time", scan.ScanTime.Format(time.RFC3339))
+	}
+}
+
+// latestResults returns up to n of the newest stored scans, newest first.
+func latestResults(ctx context.Context, n int) ([]scanState, error) {
+	scans, err := results.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var states []scanState
+	for _, scan := range scans[:min(n, len(scans))] {
+		state, err := results.load(ctx, scan.ScanTime)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// gcsResults stores each scan as two objects under the project's prefix:
+// <stamp>.json with the whole scan and <stamp>.summary.json for list.
+type gcsResults struct {
+	bucket string
+	prefix string
+}
+
+func (g *gcsResults) withBucket(ctx context.Context, fn func(*storage.BucketHandle) error) error {
+	client, err := storage.NewClient(ctx, clientOptions...)
+	if err != nil {
+		return fmt.Errorf("create storage client: %w", err)
+	}
+	defer client.Close()
+	return fn(client.Bucket(g.bucket))
+}
+
+func (g *gcsResults) save(ctx context.Context, state scanState) error {
+	stamp := scanStamp(state.ScanTime)
+	return g.withBucket(ctx, func(b *storage.BucketHandle) error {
+		// The summary goes last, so list never shows a scan whose
+		// inventory isn't there.
+		if err := writeGCSJSON(ctx, b.Object(g.prefix+stamp+".json"), state); err != nil {
+			return err
+		}
+		return writeGCSJSON(ctx, b.Object(g.prefix+stamp+".summary.json"), summarizeScan(state))
+	})
+}
+
+func writeGCSJSON(ctx context.Context, obj *storage.ObjectHandle, v any) error {
+	w := obj.NewWriter(ctx)
+	w.ContentType = "application/json"
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+func readGCSJSON(ctx context.Context, obj *storage.ObjectHandle, v any) error {
+	r, err := obj.NewReader(ctx)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func (g *gcsResults) list(ctx context.Context) ([]storedScan, error) {
+	var scans []storedScan
+	err := g.withBucket(ctx, func(b *storage.BucketHandle) error {
+		it := b.Objects(ctx, &storage.Query{Prefix: g.prefix})
+		for {
+			attrs, err := it.Next()
+			if err == iterator.Done {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if !strings.HasSuffix(attrs.Name, ".summary.json") {
+				continue
+			}
+			var scan storedScan
+			if err := readGCSJSON(ctx, b.Object(attrs.Name), &scan); err != nil {
+				return fmt.Errorf("read gs://%s/%s: %w", g.bucket, attrs.Name, err)
+			}
+			scans = append(scans, scan)
+		}
+	})
+	sort.Slice(scans, func(i, j int) bool { return scans[i].ScanTime.After(scans[j].ScanTime) })
+	return scans, err
+}
+
+func (g *gcsResults) load(ctx context.Context, scanTime time.Time) (scanState, error) {
+	var state scanState
+	err := g.withBucket(ctx, func(b *storage.BucketHandle) error {
+		return readGCSJSON(ctx, b.Object(g.prefix+scanStamp(scanTime)+".json"), &state)
+	})
+	if err == nil {
+		err = checkSchemaVersion(state.SchemaVersion)
+	}
+	return state, err
+}
+
+func (g *gcsResults) remove(ctx context.Context, scanTime time.Time) error {
+	stamp := scanStamp(scanTime)
+	return g.withBucket(ctx, func(b *storage.BucketHandle) error {
+		for _, name := range []string{stamp + ".summary.json", stamp + ".json"} {
+			if err := b.Object(g.prefix + name).Delete(ctx); err != nil && !errors.Is(err, storage.ErrObjectNotExist) {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// firestoreBatch is the most writes one Firestore commit accepts.
+const firestoreBatch = 500
+
+// firestoreResults stores each scan as a document in
+// <collection>/<project ID>/scans, named by its time and holding its
+// summary, with one document per resource in its resources subcollection.
+// Documents are limited to 1 MiB, so a whole inventory wouldn't fit in
+// one.
+type firestoreResults struct {
+	project    string
+	collection string
+}
+
+// firestoreDocument and firestoreValue are the parts of the Firestore REST
+// representation the store uses.
+type firestoreDocument struct {
+	Name   string                    `json:"name,omitempty"`
+	Fields map[string]firestoreValue `json:"fields"`
+}
+
+type firestoreValue struct {
+	StringValue    *string            `json:"stringValue,omitempty"`
+	IntegerValue   string             `json:"integerValue,omitempty"`
+	TimestampValue string             `json:"timestampValue,omitempty"`
+	MapValue       *firestoreDocument `json:"mapValue,omitempty"`
+}
+
+type firestoreWrite struct {
+	Update *firestoreDocument `json:"update,omitempty"`
+	Delete string             `json:"delete,omitempty"`
+}
+
+func firestoreString(s string) firestoreValue { return firestoreValue{StringValue: &s} }
+
+func firestoreInt(n int) firestoreValue { return firestoreValue{IntegerValue: strconv.Itoa(n)} }
+
+func (v firestoreValue) str() string {
+	if v.StringValue == nil {
+		return ""
+	}
+	return *v.StringValue
+}
+
+func (v firestoreValue) integer() int {
+	n, _ := strconv.Atoi(v.IntegerValue)
+	return n
+}
+
+func (f *firestoreResults) database() string {
+	return fmt.Sprintf("projects/%s/databases/(default)/documents", f.project)
+}
+
+// scans is the collection holding the current project's scans.
+func (f *firestoreResults) scans() string {
+	return fmt.Sprintf("%s/%s/%s/scans", f.database(), f.collection, projectID)
+}
+
+func (f *firestoreResults) scanDoc(scanTime time.Time) string {
+	return f.scans() + "/" + scanStamp(scanTime)
+}
+
+// commit applies writes in batches of firestoreBatch. Each batch is atomic
+// but the whole isn't, which is why save writes the scan document last.
+func (f *firestoreResults) commit(ctx context.Context, writes []firestoreWrite) error {
+	for len(writes) > 0 {
+		n := min(len(writes), firestoreBatch)
+		body := map[string]any{"writes": writes[:n]}
+		if err := callREST(ctx, http.MethodPost, "https://firestore.googleapis.com/v1/"+f.database()+":commit", body, nil); err != nil {
+			return err
+		}
+		writes = writes[n:]
+	}
+	return nil
+}
+
+// documents lists the documents of a collection, in name order.
+func (f *firestoreResults) documents(ctx context.Context, collection string) ([]firestoreDocument, error) {
+	var docs []firestoreDocument
+	err := listREST(ctx, "https://firestore.googleapis.com/v1/"+collection+"?pageSize=300", func(page *struct {
+		Documents []firestoreDocument `json:"documents"`
+	}) error {
+		docs = append(docs, page.Documents...)
+		return nil
+	})
+	sort.Slice(docs, func(i, j int) bool { return docs[i].Name < docs[j].Name })
+	return docs, err
+}
+
+func (f *firestoreResults) save(ctx context.Context, state scanState) error {
+	doc := f.scanDoc(state.ScanTime)
+	var writes []firestoreWrite
+	for i, row := range state.Inventory {
+		data, err := json.Marshal(row)
+		if err != nil {
+			return err
+		}
+		writes = append(writes, firestoreWrite{Update: &firestoreDocument{
+			Name:   fmt.Sprintf("%s/resources/%06d", doc, i),
+			Fields: map[string]firestoreValue{"json": firestoreString(string(data))},
+		}})
+	}
+
+	summary := summarizeScan(state)
+	byType := &firestoreDocument{Fields: map[string]firestoreValue{}}
+	for t, n := range summary.ByType {
+		byType.Fields[t] = firestoreInt(n)
+	}
+	completed, err := json.Marshal(state.Completed)
+	if err != nil {
+		return err
+	}
+	// The scan document goes last, so list never shows a scan whose
+	// resources aren't all there.
+	writes = append(writes, firestoreWrite{Update: &firestoreDocument{
+		Name: doc,
+		Fields: map[string]firestoreValue{
+			"schema_version": firestoreString(state.SchemaVersion),
+			"project_id":     firestoreString(state.ProjectID),
+			"scan_time":      {TimestampValue: state.ScanTime.UTC().Format(time.RFC3339Nano)},
+			"resources":      firestoreInt(summary.Resources),
+			"by_type":        {MapValue: byType},
+			"completed":      firestoreString(string(completed)),
+		},
+	}})
+	return f.commit(ctx, writes)
+}
+
+func (f *firestoreResults) list(ctx context.Context) ([]storedScan, error) {
+	docs, err := f.documents(ctx, f.scans())
+	if err != nil {
+		return nil, err
+	}
+	var scans []storedScan
+	for _, doc := range docs {
+		scanTime, err := time.Parse(time.RFC3339Nano, doc.Fields["scan_time"].TimestampValue)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s: %w", doc.Name, err)
+		}
+		scan := storedScan{
+			ProjectID: doc.Fields["project_id"].str(),
+			ScanTime:  scanTime,
+			Resources: doc.Fields["resources"].integer(),
+			ByType:    map[string]int{},
+		}
+		if byType := doc.Fields["by_type"].MapValue; byType != nil {
+			for t, n := range byType.Fields {
+				scan.ByType[t] = n.integer()
+			}
+		}
+		scans = append(scans, scan)
+	}
+	sort.Slice(scans, func(i, j int) bool { return scans[i].ScanTime.After(scans[j].ScanTime) })
+	return scans, nil
+}
+
+func (f *firestoreResults) load(ctx context.Context, scanTime time.Time) (scanState, error) {
+	var doc firestoreDocument
+	name := f.scanDoc(scanTime)
+	if err := callREST(ctx, http.MethodGet, "https://firestore.googleapis.com/v1/"+name, nil, &doc); err != nil {
+		return scanState{}, err
+	}
+	state := scanState{
+		SchemaVersion: doc.Fields["schema_version"].str(),
+		ProjectID:     doc.Fields["project_id"].str(),
+		ScanTime:      scanTime,
+	}
+	if err := checkSchemaVersion(state.SchemaVersion); err != nil {
+		return state, err
+	}
+	if err := json.Unmarshal([]byte(doc.Fields["completed"].str()), &state.Completed); err != nil {
+		return state, fmt.Errorf("parse %s: %w", name, err)
+	}
+
+	docs, err := f.documents(ctx, name+"/resources")
+	if err != nil {
+		return state, err
+	}
+	for _, d := range docs {
+		var row inventoryRow
+		if err := json.Unmarshal([]byte(d.Fields["json"].str()), &row); err != nil {
+			return state, fmt.Errorf("parse %s: %w", d.Name, err)
+		}
+		state.Inventory = append(state.Inventory, row)
+	}
+	return state, nil
+}
+
+func (f *firestoreResults) remove(ctx context.Context, scanTime time.Time) error {
+	name := f.scanDoc(scanTime)
+	docs, err := f.documents(ctx, name+"/resources")
+	if err != nil {
+		return err
+	}
+	// The scan document goes first, so a partly removed scan is no longer
+	// listed.
+	writes := []firestoreWrite{{Delete: name}}
+	for _, d := range docs {
+		writes = append(writes, firestoreWrite{Delete: d.Name})
+	}
+	return f.commit(ctx, writes)
+}
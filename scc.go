@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+
+	"google.golang.org/api/securitycenter/v1"
+)
+
+// sccCollector pulls active, unmuted Security Command Center findings for
+// the project. It only runs with --scc-findings, since SCC is a paid tier
+// for most of its detectors and the findings can run to thousands.
+var sccCollector = collector{
+	name: "Security Command Center findings", section: "SECURITY COMMAND CENTER FINDINGS", run: global(getSCCFindings),
+	permissions: []string{"securitycenter.findings.list"},
+}
+
+func getSCCFindings(ctx context.Context) {
+	sccService, err := securitycenter.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create Security Command Center service", "error", err)
+		return
+	}
+
+	count := 0
+	severities := map[string]int{}
+	parent := fmt.Sprintf("projects/%s/sources/-", projectID)
+	err = sccService.Projects.Sources.Findings.List(parent).
+		Filter(`state="ACTIVE" AND NOT mute="MUTED"`).
+		Context(ctx).
+		Pages(ctx, func(page *securitycenter.ListFindingsResponse) error {
+			for _, result := range page.ListFindingsResults {
+				finding := result.Finding
+				if finding == nil {
+					continue
+				}
+				resource := finding.ResourceName
+				if result.Resource != nil && result.Resource.DisplayName != "" {
+					resource = result.Resource.DisplayName
+				}
+				info := fmt.Sprintf("Name: %s\nCategory: %s\nSeverity: %s\nClass: %s\nResource: %s\nResource Type: %s\nEvent Time: %s\nLink: %s",
+					path.Base(finding.Name), finding.Category, finding.Severity, finding.FindingClass,
+					resource, sccResourceType(result), finding.EventTime, finding.ExternalUri)
+				writeResourceRaw("SCC Finding", info, result)
+				severities[finding.Severity]++
+				count++
+			}
+			return nil
+		})
+	if err != nil {
+		// Skip projects without Security Command Center
+		slog.Warn("Skipping Security Command Center findings", "error", err)
+	}
+	if count > 0 {
+		slog.Info("Active Security Command Center findings", "total", count,
+			"critical", severities["CRITICAL"], "high", severities["HIGH"],
+			"medium", severities["MEDIUM"], "low", severities["LOW"])
+	}
+	scanProgress.found(count)
+}
+
+func sccResourceType(result *securitycenter.ListFindingsResult) string {
+	if result.Resource == nil {
+		return ""
+	}
+	return strings.TrimPrefix(result.Resource.Type, "google.")
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"strings"
+
+	"google.golang.org/api/compute/v1"
+)
+
+// getResourcePolicies lists the region's snapshot schedules, instance
+// schedules and placement policies with the disks and instances attached
+// to them.
+func getResourcePolicies(ctx context.Context, region string) {
+	computeService, err := compute.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create compute service", "error", err)
+		return
+	}
+
+	policies, err := computeService.ResourcePolicies.List(projectID, region).Context(ctx).Do()
+	if err != nil {
+		// Skip regions that don't exist or aren't enabled for this project
+		slog.Debug("Skipping region", "region", region, "error", err)
+		return
+	}
+	if len(policies.Items) == 0 {
+		return
+	}
+
+	attached, err := resourcePolicyUsers(ctx, computeService)
+	if err != nil {
+		slog.Error("Failed to list resource policy attachments", "region", region, "error", err)
+	}
+
+	for _, policy := range policies.Items {
+		kind, schedule, retention := "", "", ""
+		switch {
+		case policy.SnapshotSchedulePolicy != nil:
+			kind = "snapshot schedule"
+			schedule = snapshotSchedule(policy.SnapshotSchedulePolicy.Schedule)
+			if r := policy.SnapshotSchedulePolicy.RetentionPolicy; r != nil {
+				retention = fmt.Sprintf("%d days", r.MaxRetentionDays)
+			}
+		case policy.InstanceSchedulePolicy != nil:
+			kind = "instance schedule"
+			var parts []string
+			if s := policy.InstanceSchedulePolicy.VmStartSchedule; s != nil {
+				parts = append(parts, "start "+s.Schedule)
+			}
+			if s := policy.InstanceSchedulePolicy.VmStopSchedule; s != nil {
+				parts = append(parts, "stop "+s.Schedule)
+			}
+			schedule = strings.Join(parts, ", ") + " " + policy.InstanceSchedulePolicy.TimeZone
+		case policy.GroupPlacementPolicy != nil:
+			kind = "placement"
+			schedule = policy.GroupPlacementPolicy.Collocation
+		}
+
+		info := fmt.Sprintf("Name: %s\nType: %s\nRegion: %s\nStatus: %s\nSchedule: %s\nRetention: %s\nAttached To: %s",
+			policy.Name, kind, region, policy.Status, strings.TrimSpace(schedule), retention,
+			strings.Join(attached[resourcePath(policy.SelfLink)], ", "))
+		writeResourceRaw("Resource Policy", info, policy)
+	}
+	scanProgress.found(len(policies.Items))
+}
+
+// resourcePolicyUsers maps resource policies to the disks and instances
+// attached to them, across every zone.
+func resourcePolicyUsers(ctx context.Context, computeService *compute.Service) (map[string][]string, error) {
+	users := map[string][]string{}
+	err := computeService.Disks.AggregatedList(projectID).Pages(ctx, func(list *compute.DiskAggregatedList) error {
+		for _, scoped := range list.Items {
+			for _, disk := range scoped.Disks {
+				for _, policy := range disk.ResourcePolicies {
+					users[resourcePath(policy)] = append(users[resourcePath(policy)], "disk "+disk.Name)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return users, err
+	}
+	err = computeService.Instances.AggregatedList(projectID).Pages(ctx, func(list *compute.InstanceAggregatedList) error {
+		for _, scoped := range list.Items {
+			for _, instance := range scoped.Instances {
+				for _, policy := range instance.ResourcePolicies {
+					users[resourcePath(policy)] = append(users[resourcePath(policy)], "instance "+instance.Name)
+				}
+			}
+		}
+		return nil
+	})
+	return users, err
+}
+
+func snapshotSchedule(s *compute.ResourcePolicySnapshotSchedulePolicySchedule) string {
+	switch {
+	case s == nil:
+		return ""
+	case s.HourlySchedule != nil:
+		return fmt.Sprintf("every %d hours from %s", s.HourlySchedule.HoursInCycle, s.HourlySchedule.StartTime)
+	case s.DailySchedule != nil:
+		return fmt.Sprintf("every %d days at %s", s.DailySchedule.DaysInCycle, s.DailySchedule.StartTime)
+	case s.WeeklySchedule != nil:
+		var days []string
+		for _, d := range s.WeeklySchedule.DayOfWeeks {
+			days = append(days, d.Day+" "+d.StartTime)
+		}
+		return "weekly on " + strings.Join(days, ", ")
+	}
+	return ""
+}
+
+// diskBackupInfo returns the snapshot schedule fields of a disk row. Disks
+// only carry snapshot schedules, so any attached policy counts as a backup.
+func diskBackupInfo(disk *compute.Disk) string {
+	var schedules []string
+	for _, policy := range disk.ResourcePolicies {
+		schedules = append(schedules, path.Base(policy))
+	}
+	return fmt.Sprintf("Snapshot Schedules: %s\nNo Backup Policy: %t",
+		strings.Join(schedules, ", "), len(schedules) == 0)
+}
+
+// instanceSchedules returns the names of the resource policies attached to
+// an instance, such as instance start/stop schedules.
+func instanceSchedules(instance *compute.Instance) string {
+	var names []string
+	for _, policy := range instance.ResourcePolicies {
+		names = append(names, path.Base(policy))
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// inventorySchemaVersion is written into every saved scan and NDJSON
+// record. The major version changes only when a field is removed, renamed
+// or changes meaning; new fields bump the minor version, so a reader of
+// 1.x can read any 1.y file.
+const inventorySchemaVersion = "1.0"
+
+// inventoryJSONSchema is the JSON Schema of inventorySchemaVersion,
+// published as inventory.schema.json in the repository.
+//
+//go:embed inventory.schema.json
+var inventoryJSONSchema []byte
+
+// checkSchemaVersion returns an error for a file written with a schema this
+// version can't read. Files from before schema versions were recorded have
+// none and are read as 1.0.
+func checkSchemaVersion(version string) error {
+	if version == "" {
+		return nil
+	}
+	major, _, _ := strings.Cut(inventorySchemaVersion, ".")
+	if got, _, _ := strings.Cut(version, "."); got != major {
+		return fmt.Errorf("schema version %s is not supported (this version reads %s.x)", version, major)
+	}
+	return nil
+}
+
+// versionedRow is an inventory row as written on its own, in an NDJSON
+// report, where there is no enclosing document to carry the version.
+type versionedRow struct {
+	SchemaVersion string `json:"schema_version"`
+	inventoryRow
+}
+
+// runValidate checks a saved scan or NDJSON report against the inventory
+// schema and writes each problem found to w. It returns an error if the
+// file isn't valid.
+func runValidate(w io.Writer, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var root map[string]any
+	if err := json.Unmarshal(inventoryJSONSchema, &root); err != nil {
+		return fmt.Errorf("parse inventory schema: %w", err)
+	}
+	v := schemaValidator{root: root}
+
+	data = bytes.TrimSpace(data)
+	kind, count := "saved scan", 0
+	switch first, _ := firstJSONValue(data); {
+	case bytes.HasPrefix(data, []byte("[")):
+		// A bare array of resources, as browse also reads.
+		kind = "resource list"
+		var rows []any
+		if err := json.Unmarshal(data, &rows); err != nil {
+			return fmt.Errorf("parse %s: %w", path, err)
+		}
+		count = len(rows)
+		v.validate(map[string]any{"type": "array", "items": map[string]any{"$ref": "#/$defs/resource"}}, rows, "")
+	case first["inventory"] != nil:
+		// A checkpoint has a record per collector after the first.
+		dec := json.NewDecoder(bytes.NewReader(data))
+		for n := 1; dec.More(); n++ {
+			var doc any
+			if err := dec.Decode(&doc); err != nil {
+				return fmt.Errorf("parse %s: %w", path, err)
+			}
+			at := ""
+			if n > 1 {
+				at = fmt.Sprintf("record %d", n)
+			}
+			v.validate(root, doc, at)
+			if m, ok := doc.(map[string]any); ok {
+				rows, _ := m["inventory"].([]any)
+				count += len(rows)
+			}
+		}
+	default:
+		// Each line of an NDJSON report is a record on its own.
+		kind = "NDJSON report"
+		record := map[string]any{"$ref": "#/$defs/record"}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		for dec.More() {
+			var line any
+			if err := dec.Decode(&line); err != nil {
+				return fmt.Errorf("parse %s: record %d: %w", path, count+1, err)
+			}
+			count++
+			v.validate(record, line, fmt.Sprintf("record %d", count))
+		}
+	}
+
+	for _, problem := range v.problems {
+		fmt.Fprintln(w, problem)
+	}
+	if len(v.problems) > 0 {
+		return fmt.Errorf("%s is not a valid %s (%d problems found)", path, kind, len(v.problems))
+	}
+	fmt.Fprintf(w, "%s is a valid %s (schema %s, %d resources)\n", path, kind, inventorySchemaVersion, count)
+	return nil
+}
+
+// firstJSONValue decodes the first JSON object in data, if it starts with
+// one.
+func firstJSONValue(data []byte) (map[string]json.RawMessage, error) {
+	var first map[string]json.RawMessage
+	err := json.NewDecoder(bytes.NewReader(data)).Decode(&first)
+	return first, err
+}
+
+// schemaValidator checks JSON values against the subset of JSON Schema
+// that inventory.schema.json uses: $ref to $defs, allOf, type, required,
+// properties, additionalProperties, items, pattern and the date-time
+// format.
+type schemaValidator struct {
+	root     map[string]any
+	problems []string
+}
+
+func (v *schemaValidator) fail(at, format string, args ...any) {
+	if at == "" {
+		at = "document"
+	}
+	v.problems = append(v.problems, at+": "+fmt.Sprintf(format, args...))
+}
+
+func (v *schemaValidator) validate(schema map[string]any, value any, at string) {
+	if ref, ok := schema["$ref"].(string); ok {
+		def, _ := v.root["$defs"].(map[string]any)[strings.TrimPrefix(ref, "#/$defs/")].(map[string]any)
+		if def == nil {
+			v.fail(at, "unknown schema reference %s", ref)
+			return
+		}
+		v.validate(def, value, at)
+	}
+	if all, ok := schema["allOf"].([]any); ok {
+		for _, s := range all {
+			v.validate(s.(map[string]any), value, at)
+		}
+	}
+
+	if types := schemaTypes(schema["type"]); len(types) > 0 && !types[jsonType(value)] {
+		v.fail(at, "is %s, want %s", jsonType(value), strings.Join(sortedKeys(types), " or "))
+		return
+	}
+
+	switch value := value.(type) {
+	case map[string]any:
+		if required, ok := schema["required"].([]any); ok {
+			for _, name := range required {
+				if _, ok := value[name.(string)]; !ok {
+					v.fail(at, "missing %s", name)
+				}
+			}
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		extra, _ := schema["additionalProperties"].(map[string]any)
+		for _, name := range sortedKeys(value) {
+			if p, ok := properties[name].(map[string]any); ok {
+				v.validate(p, value[name], joinPath(at, name))
+			} else if extra != nil {
+				v.validate(extra, value[name], joinPath(at, name))
+			}
+		}
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range value {
+				v.validate(items, item, at+"["+strconv.Itoa(i)+"]")
+			}
+		}
+	case string:
+		if pattern, ok := schema["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err == nil && !re.MatchString(value) {
+				v.fail(at, "%q does not match %s", value, pattern)
+			}
+		}
+		if schema["format"] == "date-time" {
+			if _, err := time.Parse(time.RFC3339, value); err != nil {
+				v.fail(at, "%q is not an RFC 3339 date-time", value)
+			}
+		}
+	}
+}
+
+func joinPath(at, name string) string {
+	if at == "" {
+		return name
+	}
+	return at + "." + name
+}
+
+// schemaTypes returns the types a schema's "type" allows, which may be one
+// name or a list.
+func schemaTypes(t any) map[string]bool {
+	types := map[string]bool{}
+	switch t := t.(type) {
+	case string:
+		types[t] = true
+	case []any:
+		for _, name := range t {
+			types[name.(string)] = true
+		}
+	}
+	return types
+}
+
+// jsonType is the JSON Schema type name of a decoded JSON value.
+func jsonType(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"slices"
+	"sort"
+	"strings"
+
+	"google.golang.org/api/compute/v1"
+)
+
+// getSecurityPolicies lists Cloud Armor policies with the backend services
+// they protect, followed by one entry per rule so rules can be filtered and
+// diffed individually.
+func getSecurityPolicies(ctx context.Context) {
+	computeService, err := compute.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create compute service", "error", err)
+		return
+	}
+
+	policies, err := computeService.SecurityPolicies.List(projectID).Context(ctx).Do()
+	if err != nil {
+		slog.Error("Failed to list Cloud Armor security policies", "error", err)
+		return
+	}
+
+	// Policies only know their rules; attachments are recorded on the
+	// backend services, global and regional.
+	attached := map[string][]string{}
+	services, err := computeService.BackendServices.AggregatedList(projectID).Context(ctx).Do()
+	if err != nil {
+		slog.Error("Failed to list backend services", "error", err)
+	} else {
+		for _, scoped := range services.Items {
+			for _, service := range scoped.BackendServices {
+				if service.SecurityPolicy != "" {
+					policy := path.Base(service.SecurityPolicy)
+					attached[policy] = append(attached[policy], service.Name)
+				}
+				if service.EdgeSecurityPolicy != "" {
+					policy := path.Base(service.EdgeSecurityPolicy)
+					attached[policy] = append(attached[policy], service.Name+" (edge)")
+				}
+			}
+		}
+	}
+
+	count := 0
+	for _, policy := range policies.Items {
+		backends := attached[policy.Name]
+		sort.Strings(backends)
+		info := fmt.Sprintf("Name: %s\nType: %s\nDescription: %s\nRules: %d\nAttached To: %s",
+			policy.Name, policy.Type, oneLine(policy.Description), len(policy.Rules), strings.Join(backends, ", "))
+		writeResourceRaw("Cloud Armor Policy", info, policy)
+		count++
+
+		for _, rule := range policy.Rules {
+			info := fmt.Sprintf("Name: %s/%d\nPolicy: %s\nPriority: %d\nAction: %s\nMatch: %s\nPreview: %v\nDescription: %s",
+				policy.Name, rule.Priority, policy.Name, rule.Priority, rule.Action,
+				securityRuleMatch(rule.Match), rule.Preview, oneLine(rule.Description))
+			writeResourceRaw("Cloud Armor Rule", info, rule)
+			count++
+		}
+	}
+	scanProgress.found(count)
+}
+
+// securityRuleMatch renders a rule's match condition: either a CEL
+// expression or the source IP ranges of a basic match.
+func securityRuleMatch(match *compute.SecurityPolicyRuleMatcher) string {
+	switch {
+	case match == nil:
+		return ""
+	case match.Expr != nil && match.Expr.Expression != "":
+		return oneLine(match.Expr.Expression)
+	case match.Config != nil:
+		return "srcIpRanges: " + strings.Join(match.Config.SrcIpRanges, ", ")
+	}
+	return match.VersionedExpr
+}
+
+// instanceSecurityInfo returns the security fields of a compute instance
+// report entry. OS Login and serial port access come from the instance's
+// metadata, falling back to the project's.
+func instanceSecurityInfo(instance *compute.Instance, projectMetadata *compute.Metadata) string {
+	var secureBoot, vtpm, integrityMonitoring bool
+	if sc := instance.ShieldedInstanceConfig; sc != nil {
+		secureBoot, vtpm, integrityMonitoring = sc.EnableSecureBoot, sc.EnableVtpm, sc.EnableIntegrityMonitoring
+	}
+	confidential := instance.ConfidentialInstanceConfig != nil && instance.ConfidentialInstanceConfig.EnableConfidentialCompute
+
+	osLogin := metadataFlag("enable-oslogin", instance.Metadata, projectMetadata)
+	serialPort := metadataFlag("serial-port-enable", instance.Metadata, projectMetadata)
+
+	var accounts, scopes []string
+	for _, sa := range instance.ServiceAccounts {
+		accounts = append(accounts, sa.Email)
+		for _, scope := range sa.Scopes {
+			scopes = append(scopes, strings.TrimPrefix(scope, "https://www.googleapis.com/auth/"))
+		}
+	}
+
+	defaultFullAccess := false
+	for _, sa := range instance.ServiceAccounts {
+		defaultFullAccess = defaultFullAccess || (isDefaultComputeServiceAccount(sa.Email) && slices.Contains(sa.Scopes, cloudPlatformScope))
+	}
+	if defaultFullAccess {
+		slog.Warn("Instance runs as the default compute service account with full API access", "instance", instance.Name)
+	}
+
+	return fmt.Sprintf("Secure Boot: %v\nvTPM: %v\nIntegrity Monitoring: %v\nConfidential VM: %v\n"+
+		"OS Login: %v\nSerial Port Access: %v\nService Account: %s\nScopes: %s\nDefault SA Full Access: %v\nDeletion Protection: %v",
+		secureBoot, vtpm, integrityMonitoring, confidential, osLogin, serialPort,
+		strings.Join(accounts, ", "), strings.Join(scopes, ", "), defaultFullAccess, instance.DeletionProtection)
+}
+
+// isDefaultComputeServiceAccount reports whether email is the Compute
+// Engine default service account, which has the Editor role on the project
+// unless someone removed it. With the cloud-platform scope, anything
+// running as it can change nearly everything in the project.
+func isDefaultComputeServiceAccount(email string) bool {
+	return email == "default" || strings.HasSuffix(email, "-compute@developer.gserviceaccount.com")
+}
+
+// defaultFirewallRules are the rules created with the default network.
+var defaultFirewallRules = []string{"default-allow-icmp", "default-allow-internal", "default-allow-rdp", "default-allow-ssh"}
+
+func isDefaultFirewallRule(firewall *compute.Firewall) bool {
+	return path.Base(firewall.Network) == "default" && slices.Contains(defaultFirewallRules, firewall.Name)
+}
+
+// metadataFlag reports whether a boolean metadata key is set to true on the
+// instance, or on the project when the instance doesn't set it.
+func metadataFlag(key string, metadata ...*compute.Metadata) bool {
+	for _, md := range metadata {
+		if md == nil {
+			continue
+		}
+		for _, item := range md.Items {
+			if item.Key == key && item.Value != nil {
+				value := strings.ToLower(*item.Value)
+				return value == "true" || value == "1"
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// server exposes scans over HTTP for the serve command. Only one scan runs
+// at a time; results are copied out of the scan globals when it finishes so
+// handlers never read state a running scan is writing.
+type server struct {
+	// ctx is cancelled on shutdown. Scans started over HTTP run under it,
+	// and scans waits for them to write their reports.
+	ctx   context.Context
+	scans sync.WaitGroup
+
+	mu       sync.Mutex
+	running  bool
+	lastErr  error
+	latest   *scanState
+	previous *scanState
+}
+
+// errScanRunning is returned by daemonScan when another scan holds the
+// server.
+var errScanRunning = errors.New("a scan is already running")
+
+type scanStatus struct {
+	Running   bool       `json:"running"`
+	ProjectID string     `json:"project_id"`
+	LastScan  *time.Time `json:"last_scan,omitempty"`
+	Resources int        `json:"resources"`
+	LastError string     `json:"last_error,omitempty"`
+}
+
+func runServer(ctx context.Context, addr string) error {
+	srv := &server{ctx: ctx}
+	if results != nil {
+		// The store has the history, so the last two scans can be diffed
+		// straight away.
+		states, err := latestResults(ctx, 2)
+		if err != nil {
+			slog.Warn("Failed to load stored scans", "store", resultsStoreURL, "error", err)
+		}
+		if len(states) > 0 {
+			srv.latest = &states[0]
+		}
+		if len(states) > 1 {
+			srv.previous = &states[1]
+		}
+	} else if state, err := readState(snapshotFile); err == nil {
+		srv.latest = &state
+	} else if !errors.Is(err, os.ErrNotExist) {
+		slog.Warn("Ignoring previous scan snapshot", "error", err)
+	}
+
+	if daemon {
+		srv.scans.Add(1)
+		go func() {
+			defer srv.scans.Done()
+			runDaemon(ctx, srv.daemonScan)
+		}()
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", srv.handleHealth)
+	mux.HandleFunc("GET /scan", srv.handleScanStatus)
+	mux.HandleFunc("POST /scan", srv.handleScan)
+	mux.HandleFunc("GET /inventory", srv.handleInventory)
+	mux.HandleFunc("GET /diff", srv.handleDiff)
+	if results != nil {
+		mux.HandleFunc("GET /scans", srv.handleScans)
+		mux.HandleFunc("GET /scans/{time}", srv.handleStoredScan)
+	}
+	mux.HandleFunc("GET /metrics", scanMetrics.handle)
+	if serveUI {
+		mux.Handle("GET /", uiHandler())
+	}
+
+	if grpcAddr != "" {
+		if err := serveGRPC(ctx, srv, grpcAddr); err != nil {
+			return err
+		}
+	}
+
+	httpServer := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		if err := httpServer.Shutdown(context.Background()); err != nil {
+			slog.Error("Failed to shut down HTTP server", "error", err)
+		}
+	}()
+	slog.Info("Serving HTTP API", "addr", addr, "project", projectID)
+	err := httpServer.ListenAndServe()
+	if !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	slog.Info("Shutting down, waiting for running scans")
+	srv.scans.Wait()
+	return nil
+}
+
+// daemonScan is the daemon's scan. It skips the tick with errScanRunning
+// when a scan started over HTTP or gRPC is still running.
+func (s *server) daemonScan(ctx context.Context) error {
+	if !s.claimScan() {
+		return errScanRunning
+	}
+	return s.scan(ctx)
+}
+
+// scan runs one scan. The caller must have claimed it with claimScan.
+func (s *server) scan(ctx context.Context) error {
+	err := runScan(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running = false
+	s.lastErr = err
+	if err == nil {
+		s.previous = s.latest
+		s.latest = &scanState{
+			SchemaVersion: inventorySchemaVersion,
+			ProjectID:     projectID,
+			ScanTime:      scanTime,
+			Inventory:     withoutScanMetadata(inventory),
+		}
+	}
+	return err
+}
+
+func (s *server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *server) handleScanStatus(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	writeJSON(w, http.StatusOK, s.status())
+}
+
+// handleScan starts a scan in the background and returns immediately; poll
+// GET /scan for completion.
+func (s *server) handleScan(w http.ResponseWriter, r *http.Request) {
+	if !s.startScan() {
+		writeJSON(w, http.StatusConflict, map[string]string{"error": "a scan is already running"})
+		return
+	}
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "started"})
+}
+
+// claimScan marks a scan running unless one already is, and reports whether
+// it did. The check and the claim are one critical section, so of two
+// callers at once only one gets to scan.
+func (s *server) claimScan() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return false
+	}
+	s.running = true
+	return true
+}
+
+// startScan starts a scan in the background unless one is running, and
+// reports whether it did.
+func (s *server) startScan() bool {
+	if !s.claimScan() {
+		return false
+	}
+	s.scans.Add(1)
+	go func() {
+		defer s.scans.Done()
+		if err := s.scan(s.ctx); err != nil {
+			slog.Error("Scan failed", "error", err)
+		}
+	}()
+	return true
+}
+
+// latestScan returns the last complete scan, or nil before the first one.
+func (s *server) latestScan() *scanState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.latest
+}
+
+func (s *server) handleInventory(w http.ResponseWriter, r *http.Request) {
+	latest := s.latestScan()
+	if latest == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "no completed scan yet"})
+		return
+	}
+	writeJSON(w, http.StatusOK, latest)
+}
+
+func (s *server) handleDiff(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Has("from") || r.URL.Query().Has("to") {
+		s.handleStoredDiff(w, r)
+		return
+	}
+	s.mu.Lock()
+	latest, previous := s.latest, s.previous
+	s.mu.Unlock()
+	if latest == nil || previous == nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "need two completed scans to diff"})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"from": previous.ScanTime,
+		"to":   latest.ScanTime,
+		"diff": diffInventories(previous.Inventory, latest.Inventory),
+	})
+}
+
+func (s *server) status() scanStatus {
+	st := scanStatus{Running: s.running, ProjectID: projectID}
+	if s.latest != nil {
+		st.LastScan = &s.latest.ScanTime
+		st.Resources = len(s.latest.Inventory)
+	}
+	if s.lastErr != nil {
+		st.LastError = s.lastErr.Error()
+	}
+	return st
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("Failed to write response", "error", err)
+	}
+}
+
+// handleScans lists the stored scans, newest first, with their resource
+// counts by type for trends.
+func (s *server) handleScans(w http.ResponseWriter, r *http.Request) {
+	scans, err := results.list(r.Context())
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+	if scans == nil {
+		scans = []storedScan{}
+	}
+	writeJSON(w, http.StatusOK, scans)
+}
+
+// handleStoredScan returns a stored scan by the scan_time /scans lists.
+func (s *server) handleStoredScan(w http.ResponseWriter, r *http.Request) {
+	state, status, err := loadStoredScan(r, r.PathValue("time"))
+	if err != nil {
+		writeJSON(w, status, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, state)
+}
+
+// handleStoredDiff diffs two stored scans, given by their scan_time as
+// ?from= and ?to=.
+func (s *server) handleStoredDiff(w http.ResponseWriter, r *http.Request) {
+	if results == nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "from and to need --results-store"})
+		return
+	}
+	from, status, err := loadStoredScan(r, r.URL.Query().Get("from"))
+	if err != nil {
+		writeJSON(w, status, map[string]string{"error": err.Error()})
+		return
+	}
+	to, status, err := loadStoredScan(r, r.URL.Query().Get("to"))
+	if err != nil {
+		writeJSON(w, status, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"from": from.ScanTime,
+		"to":   to.ScanTime,
+		"diff": diffInventories(from.Inventory, to.Inventory),
+	})
+}
+
+// loadStoredScan loads the stored scan from an RFC 3339 time, returning
+// the HTTP status to report if it can't.
+func loadStoredScan(r *http.Request, value string) (scanState, int, error) {
+	scanTime, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return scanState{}, http.StatusBadRequest, fmt.Errorf("invalid scan time %q, expected RFC 3339 as listed by /scans", value)
+	}
+	state, err := results.load(r.Context(), scanTime)
+	if err != nil {
+		return state, http.StatusNotFound, fmt.Errorf("load scan %s: %w", value, err)
+	}
+	return state, http.StatusOK, nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+
+	"google.golang.org/api/servicedirectory/v1"
+)
+
+// getServiceDirectory lists the region's Service Directory namespaces, the
+// services registered in them and each service's endpoints.
+func getServiceDirectory(ctx context.Context, region string) {
+	sdService, err := servicedirectory.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create Service Directory service", "error", err)
+		return
+	}
+
+	parent := fmt.Sprintf("projects/%s/locations/%s", projectID, region)
+	namespaces, err := sdService.Projects.Locations.Namespaces.List(parent).Context(ctx).Do()
+	if err != nil {
+		// Skip projects that don't have the Service Directory API enabled
+		slog.Debug("Skipping Service Directory", "region", region, "error", err)
+		return
+	}
+
+	count := 0
+	for _, namespace := range namespaces.Namespaces {
+		services, err := sdService.Projects.Locations.Namespaces.Services.List(namespace.Name).Context(ctx).Do()
+		if err != nil {
+			slog.Error("Failed to list Service Directory services", "namespace", namespace.Name, "error", err)
+			continue
+		}
+		info := fmt.Sprintf("Name: %s\nRegion: %s\nServices: %d\nLabels: %s",
+			path.Base(namespace.Name), region, len(services.Services), attributeMapping(namespace.Labels))
+		writeResourceRaw("Service Directory Namespace", info, namespace)
+		count++
+
+		for _, service := range services.Services {
+			endpoints, err := sdService.Projects.Locations.Namespaces.Services.Endpoints.List(service.Name).Context(ctx).Do()
+			if err != nil {
+				slog.Error("Failed to list Service Directory endpoints", "service", service.Name, "error", err)
+				continue
+			}
+			info := fmt.Sprintf("Name: %s\nNamespace: %s\nRegion: %s\nEndpoints: %d\nAnnotations: %s",
+				path.Base(service.Name), path.Base(namespace.Name), region, len(endpoints.Endpoints),
+				attributeMapping(service.Annotations))
+			writeResourceRaw("Service Directory Service", info, service)
+			count++
+
+			for _, endpoint := range endpoints.Endpoints {
+				info := fmt.Sprintf("Name: %s\nService: %s\nNamespace: %s\nAddress: %s\nPort: %d\nNetwork: %s",
+					path.Base(endpoint.Name), path.Base(service.Name), path.Base(namespace.Name),
+					endpoint.Address, endpoint.Port, path.Base(endpoint.Network))
+				writeResourceRaw("Service Directory Endpoint", info, endpoint)
+				count++
+			}
+		}
+	}
+	scanProgress.found(count)
+}
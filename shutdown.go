@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// shutdownSignals are the signals that stop a scan early. A scan stopped
+// by one still writes its report, marked incomplete, with whatever the
+// collectors found before it.
+var shutdownSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+// interruptContext returns a context that is cancelled by the first
+// SIGINT or SIGTERM. Once it is, the signal handler is removed so that a
+// second Ctrl-C exits at once rather than waiting for the report to be
+// written.
+func interruptContext() context.Context {
+	ctx, stop := signal.NotifyContext(context.Background(), shutdownSignals...)
+	go func() {
+		<-ctx.Done()
+		stop()
+		slog.Warn("Interrupted, writing what was collected so far (interrupt again to quit now)")
+	}()
+	return ctx
+}
+
+// warnIncomplete logs why the scan behind scanCtx stopped early. Its
+// checkpoint is kept so that --resume can finish it.
+func warnIncomplete(scanCtx context.Context) {
+	if errors.Is(scanCtx.Err(), context.DeadlineExceeded) {
+		slog.Warn("Scan timed out, report is incomplete", "timeout", scanTimeout)
+	} else {
+		slog.Warn("Scan interrupted, report is incomplete")
+	}
+	slog.Warn("Run again with --resume to finish the remaining collectors", "state_file", checkpointFile)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"google.golang.org/api/cloudkms/v1"
+)
+
+var (
+	signReports bool
+	signKey     string
+)
+
+// signOutputs writes a SHA-256 manifest of the report files next to them,
+// in the format sha256sum -c checks, and with --sign-key a detached
+// signature of the manifest. It returns the files it wrote.
+func signOutputs(ctx context.Context, reportName string, fileNames []string) ([]string, error) {
+	var manifest strings.Builder
+	for _, name := range fileNames {
+		sum, err := fileSHA256(name)
+		if err != nil {
+			return nil, fmt.Errorf("hash %s: %w", name, err)
+		}
+		fmt.Fprintf(&manifest, "%s  %s\n", sum, name)
+	}
+	manifestName := reportName + ".sha256"
+	if err := os.WriteFile(manifestName, []byte(manifest.String()), 0o644); err != nil {
+		return nil, err
+	}
+	written := []string{manifestName}
+	fmt.Fprintf(console, "Manifest saved to: %s\n", manifestName)
+
+	if signKey == "" {
+		return written, nil
+	}
+	var signature []byte
+	var err error
+	if isKMSKeyVersion(signKey) {
+		signature, err = signWithKMS(ctx, signKey, []byte(manifest.String()))
+	} else {
+		signature, err = signWithFile(signKey, []byte(manifest.String()))
+	}
+	if err != nil {
+		return written, fmt.Errorf("sign manifest: %w", err)
+	}
+	signatureName := manifestName + ".sig"
+	if err := os.WriteFile(signatureName, signature, 0o644); err != nil {
+		return written, err
+	}
+	fmt.Fprintf(console, "Signature saved to: %s\n", signatureName)
+	return append(written, signatureName), nil
+}
+
+func fileSHA256(name string) (string, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// isKMSKeyVersion reports whether key names a Cloud KMS key version rather
+// than a local key file.
+func isKMSKeyVersion(key string) bool {
+	return strings.HasPrefix(key, "projects/") && strings.Contains(key, "/cryptoKeyVersions/")
+}
+
+// signWithKMS signs the SHA-256 digest of data with an asymmetric Cloud KMS
+// key version, which must use a SHA-256 algorithm such as
+// EC_SIGN_P256_SHA256 or RSA_SIGN_PKCS1_2048_SHA256.
+func signWithKMS(ctx context.Context, keyVersion string, data []byte) ([]byte, error) {
+	kmsService, err := cloudkms.NewService(ctx, clientOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("create Cloud KMS service: %w", err)
+	}
+	digest := sha256.Sum256(data)
+	resp, err := kmsService.Projects.Locations.KeyRings.CryptoKeys.CryptoKeyVersions.AsymmetricSign(keyVersion,
+		&cloudkms.AsymmetricSignRequest{
+			Digest: &cloudkms.Digest{Sha256: base64.StdEncoding.EncodeToString(digest[:])},
+		}).Context(ctx).Do()
+	if err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(resp.Signature)
+}
+
+// signWithFile signs data with a PEM private key: PKCS#8, or the older EC
+// and PKCS#1 RSA encodings. ECDSA and RSA (PKCS#1 v1.5) sign the SHA-256
+// digest, so the signature checks with openssl dgst -sha256 -verify;
+// Ed25519 signs the data itself.
+func signWithFile(keyFile string, data []byte) ([]byte, error) {
+	pemData, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, fmt.Errorf("%s is not a PEM file", keyFile)
+	}
+
+	var key any
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", keyFile, err)
+	}
+
+	digest := sha256.Sum256(data)
+	switch key := key.(type) {
+	case *ecdsa.PrivateKey:
+		return ecdsa.SignASN1(rand.Reader, key, digest[:])
+	case *rsa.PrivateKey:
+		return rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	case ed25519.PrivateKey:
+		return ed25519.Sign(key, data), nil
+	}
+	return nil, fmt.Errorf("unsupported key type %T in %s", key, keyFile)
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// sink is one destination for a scan's results. Every sink sees each
+// resource as the collectors report it, then the finished inventory, so a
+// single run can write several report formats, sign them, export them to
+// BigQuery and upload them to Cloud Storage.
+type sink interface {
+	// resource is called with each resource as it is found.
+	resource(row inventoryRow) error
+	// finish is called once the scan is done, with the files the sinks
+	// before it wrote, and returns the files it wrote itself.
+	finish(ctx context.Context, rows []inventoryRow, files []string) ([]string, error)
+}
+
+// scanSinks are the current scan's sinks, in the order they finish.
+var scanSinks []sink
+
+// parseFormats splits a comma-separated --format value, dropping repeats.
+func parseFormats(spec string) ([]string, error) {
+	var formats []string
+	seen := map[string]bool{}
+	for _, name := range strings.Split(spec, ",") {
+		name = strings.TrimSpace(name)
+		if _, ok := reportFormats[name]; !ok {
+			return nil, fmt.Errorf("unknown report format %q (valid: %s)", name, strings.Join(formatNames(), ", "))
+		}
+		if !seen[name] {
+			seen[name] = true
+			formats = append(formats, name)
+		}
+	}
+	return formats, nil
+}
+
+// reportName is what the scan's reports are called in the manifest and
+// notifications: the report file when there is only one, otherwise
+// baseName.
+func reportName(baseName string) string {
+	if splitBy != "" || len(outputFormats) > 1 {
+		return baseName
+	}
+	if outputPath != "" {
+		return outputPath
+	}
+	return baseName + reportFormats[outputFormats[0]].suffix
+}
+
+// newSinks opens the current scan's sinks: a report per format in
+// outputFormats, followed by the manifest, BigQuery export and upload when
+// they are enabled. With one format and --output, the report is written to
+// --output as it is; otherwise each is named baseName plus the format's
+// extension. It also points report at the text report, if there is one.
+func newSinks(baseName string) ([]sink, error) {
+	var sinks []sink
+	report = io.Discard
+	for _, name := range outputFormats {
+		format := reportFormats[name]
+		if splitBy != "" {
+			sinks = append(sinks, &splitSink{dir: baseName, format: format})
+			continue
+		}
+		s := &fileSink{name: baseName + format.suffix, format: format, text: name == "text"}
+		if len(outputFormats) == 1 {
+			s.name = reportName(baseName)
+		}
+		if s.name == "-" {
+			s.file = os.Stdout
+		} else {
+			f, err := os.Create(s.name)
+			if err != nil {
+				closeSinks(sinks)
+				return nil, err
+			}
+			s.file = f
+		}
+		if s.text {
+			report = s.file
+		}
+		sinks = append(sinks, s)
+	}
+
+	if signReports {
+		sinks = append(sinks, signSink{reportName: reportName(baseName)})
+	}
+	if bigQueryTable != "" {
+		sinks = append(sinks, bigQuerySink{table: bigQueryTable})
+	}
+	if uploadDest != "" {
+		sinks = append(sinks, uploadSink{dest: uploadDest})
+	}
+	return sinks, nil
+}
+
+// closeSinks closes the files of sinks that won't be finished.
+func closeSinks(sinks []sink) {
+	for _, s := range sinks {
+		if f, ok := s.(*fileSink); ok && f.file != os.Stdout {
+			f.file.Close()
+		}
+	}
+}
+
+// streamResource hands a resource to every sink of the scan.
+func streamResource(row inventoryRow) {
+	for _, s := range scanSinks {
+		if err := s.resource(row); err != nil {
+			slog.Error("Failed to write resource", "error", err)
+		}
+	}
+}
+
+// finishSinks finishes the scan's sinks in order, handing each the files
+// written before it. It stops at the first sink that fails.
+func finishSinks(ctx context.Context, rows []inventoryRow) error {
+	var files []string
+	for _, s := range scanSinks {
+		written, err := s.finish(ctx, rows, files)
+		if err != nil {
+			closeSinks(scanSinks)
+			return err
+		}
+		files = append(files, written...)
+	}
+	return nil
+}
+
+// fileSink writes one report format to a file or stdout. The text format
+// is written to report directly as the collectors run, streaming formats
+// one resource at a time, and the rest from the finished inventory.
+type fileSink struct {
+	name   string
+	format reportFormat
+	text   bool
+	file   *os.File
+}
+
+func (s *fileSink) resource(row inventoryRow) error {
+	if s.format.stream == nil {
+		return nil
+	}
+	return s.format.stream(s.file, row)
+}
+
+func (s *fileSink) finish(ctx context.Context, rows []inventoryRow, files []string) ([]string, error) {
+	if s.format.render != nil {
+		if err := s.format.render(s.file, rows); err != nil {
+			slog.Error("Failed to write report", "file", s.name, "error", err)
+		}
+	}
+	// Stdout can't be rewritten, so the text report's summary goes at the
+	// end there.
+	if s.file == os.Stdout {
+		if s.text {
+			io.WriteString(s.file, currentSummary.text())
+		}
+		return nil, nil
+	}
+	if err := s.file.Close(); err != nil {
+		slog.Error("Failed to close output file", "file", s.name, "error", err)
+	}
+	if s.text {
+		if err := prependSummary(s.name, currentSummary); err != nil {
+			slog.Error("Failed to write executive summary", "error", err)
+		}
+	}
+	fmt.Fprintf(console, "GCP footprint saved to: %s\n", s.name)
+	return []string{s.name}, nil
+}
+
+// splitSink writes one format as a directory of reports, one per region or
+// service, from the finished inventory.
+type splitSink struct {
+	dir    string
+	format reportFormat
+}
+
+func (s *splitSink) resource(row inventoryRow) error { return nil }
+
+func (s *splitSink) finish(ctx context.Context, rows []inventoryRow, files []string) ([]string, error) {
+	written, err := writeSplitReports(s.dir, s.format)
+	if err != nil {
+		return written, fmt.Errorf("write split reports: %w", err)
+	}
+	fmt.Fprintf(console, "GCP footprint saved to: %s (%d files)\n", s.dir, len(written))
+	return written, nil
+}
+
+// signSink writes the manifest of the report files, signed with --sign-key
+// if one is set. A failure is logged so the export and upload still run.
+type signSink struct {
+	reportName string
+}
+
+func (s signSink) resource(row inventoryRow) error { return nil }
+
+func (s signSink) finish(ctx context.Context, rows []inventoryRow, files []string) ([]string, error) {
+	signed, err := signOutputs(ctx, s.reportName, files)
+	if err != nil {
+		slog.Error("Failed to sign reports", "error", err)
+	}
+	return signed, nil
+}
+
+// bigQuerySink loads the inventory into a BigQuery table. A failure is
+// logged so the upload still runs.
+type bigQuerySink struct {
+	table string
+}
+
+func (s bigQuerySink) resource(row inventoryRow) error { return nil }
+
+func (s bigQuerySink) finish(ctx context.Context, rows []inventoryRow, files []string) ([]string, error) {
+	if err := exportBigQuery(ctx, s.table); err != nil {
+		slog.Error("Failed to export inventory to BigQuery", "error", err)
+	}
+	return nil, nil
+}
+
+// uploadSink copies the report files to Cloud Storage.
+type uploadSink struct {
+	dest string
+}
+
+func (s uploadSink) resource(row inventoryRow) error { return nil }
+
+func (s uploadSink) finish(ctx context.Context, rows []inventoryRow, files []string) ([]string, error) {
+	if err := uploadReports(ctx, s.dest, files...); err != nil {
+		return nil, fmt.Errorf("upload report: %w", err)
+	}
+	return nil, nil
+}
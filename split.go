@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// writeSplitReports writes the inventory as one report per region or
+// service (--split-by) into dir, in the scan's format, and returns the
+// files written. Each file is a complete report of its own.
+func writeSplitReports(dir string, format reportFormat) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	services := collectorServices()
+	groups := map[string][]inventoryRow{}
+	for _, row := range inventory {
+		key := rowRegion(row)
+		if splitBy == "service" {
+			_, name, _ := strings.Cut(row.Collector, "/")
+			key = services[name]
+		}
+		groups[key] = append(groups[key], row)
+	}
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	render := format.render
+	if render == nil && format.stream != nil {
+		render = func(w io.Writer, rows []inventoryRow) error {
+			for _, row := range rows {
+				if err := format.stream(w, row); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+	}
+	if render == nil {
+		render = writeText
+	}
+
+	var fileNames []string
+	for _, key := range keys {
+		name := filepath.Join(dir, fmt.Sprintf("gcp_footprint_%s_%s%s", projectID, sanitizeID(key), format.suffix))
+		f, err := os.Create(name)
+		if err != nil {
+			return fileNames, err
+		}
+		err = render(f, groups[key])
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return fileNames, fmt.Errorf("write %s: %w", name, err)
+		}
+		fileNames = append(fileNames, name)
+	}
+	return fileNames, nil
+}
+
+// collectorServices maps collector names to the API service they query,
+// taken from the prefix of their permissions (compute, storage, container,
+// ...).
+func collectorServices() map[string]string {
+	services := map[string]string{}
+	for _, c := range append(append([]collector{}, globalCollectors...), regionalCollectors...) {
+		services[c.name] = collectorService(c)
+	}
+	return services
+}
+
+// collectorService is the API service a collector queries, from its first
+// permission.
+func collectorService(c collector) string {
+	if len(c.permissions) == 0 {
+		return "other"
+	}
+	service, _, _ := strings.Cut(c.permissions[0], ".")
+	return service
+}
+
+// writeText renders rows in the text report format, for reports that aren't
+// streamed while the collectors run.
+func writeText(w io.Writer, rows []inventoryRow) error {
+	if _, err := io.WriteString(w, reportHeader()); err != nil {
+		return err
+	}
+	section := ""
+	for _, row := range rows {
+		if row.Section != section {
+			section = row.Section
+			io.WriteString(w, sectionHeading(section))
+		}
+		if _, err := fmt.Fprintf(w, "\n[%s]\n%s\n", row.ResourceType, row.info()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
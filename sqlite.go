@@ -0,0 +1,203 @@
+//go:build cgo
+
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// writeSQLite writes the inventory as a SQLite database: a resources table
+// with every resource and its fields as JSON, and one table per resource
+// type with a column per field, linked to it by resource_id. SQLite can't
+// write to a stream, so the database is built in a temporary file first.
+func writeSQLite(w io.Writer, rows []inventoryRow) error {
+	rows = withoutScanMetadata(rows)
+	tmp, err := os.CreateTemp("", "gcp_footprint_*.db")
+	if err != nil {
+		return err
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	db, err := sql.Open("sqlite3", tmp.Name())
+	if err != nil {
+		return err
+	}
+	if err := fillSQLite(db, rows); err != nil {
+		db.Close()
+		return err
+	}
+	if err := db.Close(); err != nil {
+		return err
+	}
+
+	f, err := os.Open(tmp.Name())
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = io.Copy(w, f)
+	return err
+}
+
+func fillSQLite(db *sql.DB, rows []inventoryRow) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`CREATE TABLE resources (
+		id INTEGER PRIMARY KEY,
+		scan_time TEXT NOT NULL,
+		project_id TEXT NOT NULL,
+		section TEXT,
+		resource_type TEXT NOT NULL,
+		name TEXT,
+		collector TEXT,
+		fields TEXT,
+		asset_name TEXT,
+		asset_type TEXT,
+		location TEXT,
+		zone TEXT,
+		labels TEXT,
+		create_time TEXT
+	)`)
+	if err != nil {
+		return fmt.Errorf("create resources table: %w", err)
+	}
+	insertResource, err := tx.Prepare(`INSERT INTO resources
+		(scan_time, project_id, section, resource_type, name, collector, fields,
+		asset_name, asset_type, location, zone, labels, create_time)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer insertResource.Close()
+
+	tables := map[string]*sqliteTypeTable{}
+	usedTables := map[string]bool{"resources": true}
+	for _, row := range rows {
+		fields := map[string]string{}
+		for _, f := range row.Fields {
+			fields[f.Key] = f.Value
+		}
+		fieldsJSON, err := json.Marshal(fields)
+		if err != nil {
+			return err
+		}
+		labelsJSON, err := json.Marshal(row.Labels)
+		if err != nil {
+			return err
+		}
+		result, err := insertResource.Exec(row.ScanTime.UTC().Format("2006-01-02T15:04:05Z"), row.ProjectID,
+			row.Section, row.ResourceType, row.Name, row.Collector, string(fieldsJSON),
+			row.key(), row.AssetType, row.Location, row.Zone, string(labelsJSON), row.CreateTime)
+		if err != nil {
+			return fmt.Errorf("insert %s %s: %w", row.ResourceType, row.Name, err)
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		table, ok := tables[row.ResourceType]
+		if !ok {
+			table = &sqliteTypeTable{name: uniqueSQLName(usedTables, row.ResourceType)}
+			tables[row.ResourceType] = table
+			table.collectColumns(rows, row.ResourceType)
+			if err := table.create(tx); err != nil {
+				return err
+			}
+		}
+		if err := table.insert(tx, id, row); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// sqliteTypeTable is the table of one resource type, with a column for
+// every field any resource of that type has.
+type sqliteTypeTable struct {
+	name    string
+	keys    []string
+	columns []string
+}
+
+func (t *sqliteTypeTable) collectColumns(rows []inventoryRow, resourceType string) {
+	used := map[string]bool{"resource_id": true}
+	seen := map[string]bool{}
+	for _, row := range rows {
+		if row.ResourceType != resourceType {
+			continue
+		}
+		for _, f := range row.Fields {
+			if !seen[f.Key] {
+				seen[f.Key] = true
+				t.keys = append(t.keys, f.Key)
+				t.columns = append(t.columns, uniqueSQLName(used, f.Key))
+			}
+		}
+	}
+}
+
+func (t *sqliteTypeTable) create(tx *sql.Tx) error {
+	defs := []string{"resource_id INTEGER NOT NULL REFERENCES resources(id)"}
+	for _, c := range t.columns {
+		defs = append(defs, c+" TEXT")
+	}
+	_, err := tx.Exec(fmt.Sprintf("CREATE TABLE %s (%s)", t.name, strings.Join(defs, ", ")))
+	if err != nil {
+		return fmt.Errorf("create table %s: %w", t.name, err)
+	}
+	return nil
+}
+
+func (t *sqliteTypeTable) insert(tx *sql.Tx, id int64, row inventoryRow) error {
+	args := []any{id}
+	placeholders := []string{"?"}
+	for _, key := range t.keys {
+		args = append(args, row.field(key))
+		placeholders = append(placeholders, "?")
+	}
+	query := fmt.Sprintf("INSERT INTO %s (resource_id, %s) VALUES (%s)",
+		t.name, strings.Join(t.columns, ", "), strings.Join(placeholders, ", "))
+	if _, err := tx.Exec(query, args...); err != nil {
+		return fmt.Errorf("insert into %s: %w", t.name, err)
+	}
+	return nil
+}
+
+// uniqueSQLName turns a resource type or field name into a snake_case SQL
+// identifier that isn't already in used.
+func uniqueSQLName(used map[string]bool, s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(r)
+		case b.Len() > 0 && !strings.HasSuffix(b.String(), "_"):
+			b.WriteRune('_')
+		}
+	}
+	base := strings.TrimSuffix(b.String(), "_")
+	if base == "" || unicode.IsDigit(rune(base[0])) {
+		base = "t_" + base
+	}
+
+	name := base
+	for i := 2; used[name]; i++ {
+		name = fmt.Sprintf("%s_%d", base, i)
+	}
+	used[name] = true
+	return name
+}
@@ -0,0 +1,13 @@
+//go:build !cgo
+
+package main
+
+import (
+	"errors"
+	"io"
+)
+
+// writeSQLite is unavailable without cgo, which the SQLite driver needs.
+func writeSQLite(w io.Writer, rows []inventoryRow) error {
+	return errors.New("the sqlite format needs a build with CGO_ENABLED=1")
+}
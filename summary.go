@@ -0,0 +1,158 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// summaryTopTypes caps how many resource types the executive summary lists;
+// the rest are counted together.
+const summaryTopTypes = 15
+
+// findingFlags are the fields collectors and analysis passes set to "true"
+// on resources that need attention.
+var findingFlags = []string{
+	"Expiring Soon", "Missing", "Stale", "No Backup Policy", "Default SA Full Access",
+	"Default Network", "Default Rule", "Outside Allowed Locations",
+}
+
+// executiveSummary is the overview that opens the text and Markdown
+// reports.
+type executiveSummary struct {
+	Resources int
+	ByType    map[string]int
+	ByRegion  map[string]int
+	Public    int
+	Findings  int
+	Diff      *diffCounts
+}
+
+// currentSummary is the summary of the scan being written, set once the
+// collectors have finished.
+var currentSummary *executiveSummary
+
+// isFinding reports whether a resource is flagged by any collector or
+// analysis pass, or is itself a finding.
+func isFinding(row inventoryRow) bool {
+	if row.ResourceType == "SCC Finding" || row.field("Idle") != "" || row.field("External Members") != "" {
+		return true
+	}
+	for _, flag := range findingFlags {
+		if row.field(flag) == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+// buildExecutiveSummary counts rows by type and region, how many are
+// reachable from the internet and how many are flagged, and diffs them
+// against previous when there is one.
+func buildExecutiveSummary(rows []inventoryRow, previous *scanState) *executiveSummary {
+	s := &executiveSummary{
+		Resources: len(rows),
+		ByType:    map[string]int{},
+		ByRegion:  map[string]int{},
+	}
+	for _, row := range rows {
+		s.ByType[row.ResourceType]++
+		s.ByRegion[rowRegion(row)]++
+		if publicEndpoint(row) != "" {
+			s.Public++
+		}
+		if isFinding(row) {
+			s.Findings++
+		}
+	}
+	if previous != nil {
+		diff := diffInventories(previous.Inventory, rows)
+		s.Diff = &diffCounts{len(diff.Added), len(diff.Removed), len(diff.Changed)}
+	}
+	return s
+}
+
+// topCounts returns the keys of counts by descending count, then name, with
+// everything past limit summed into the returned rest.
+func topCounts(counts map[string]int, limit int) ([]string, int) {
+	keys := sortedKeys(counts)
+	sort.SliceStable(keys, func(i, j int) bool { return counts[keys[i]] > counts[keys[j]] })
+	rest := 0
+	if limit > 0 && len(keys) > limit {
+		for _, k := range keys[limit:] {
+			rest += counts[k]
+		}
+		keys = keys[:limit]
+	}
+	return keys, rest
+}
+
+func (s *executiveSummary) changes() string {
+	if s.Diff == nil {
+		return "no previous scan"
+	}
+	return fmt.Sprintf("%d added, %d removed, %d changed", s.Diff.Added, s.Diff.Removed, s.Diff.Changed)
+}
+
+// text renders the summary as a section of the text report.
+func (s *executiveSummary) text() string {
+	var b strings.Builder
+	b.WriteString(sectionHeading("EXECUTIVE SUMMARY"))
+	fmt.Fprintf(&b, "\nResources: %d\nPublicly Reachable: %d\nFindings: %d\nSince Last Scan: %s\n",
+		s.Resources, s.Public, s.Findings, s.changes())
+
+	b.WriteString("\nBy region:\n")
+	regions, _ := topCounts(s.ByRegion, 0)
+	for _, region := range regions {
+		fmt.Fprintf(&b, "  %-32s %6d\n", region, s.ByRegion[region])
+	}
+	b.WriteString("\nBy type:\n")
+	types, rest := topCounts(s.ByType, summaryTopTypes)
+	for _, t := range types {
+		fmt.Fprintf(&b, "  %-32s %6d\n", t, s.ByType[t])
+	}
+	if rest > 0 {
+		fmt.Fprintf(&b, "  %-32s %6d\n", fmt.Sprintf("%d other types", len(s.ByType)-len(types)), rest)
+	}
+	return b.String()
+}
+
+// writeMarkdown renders the summary as the opening section of the
+// Markdown report.
+func (s *executiveSummary) writeMarkdown(w io.Writer) {
+	fmt.Fprintf(w, "\n## Executive Summary\n\n")
+	fmt.Fprintf(w, "- **Publicly reachable:** %d\n- **Findings:** %d\n- **Since last scan:** %s\n",
+		s.Public, s.Findings, s.changes())
+
+	fmt.Fprintf(w, "\n| Region | Resources |\n|---|---:|\n")
+	regions, _ := topCounts(s.ByRegion, 0)
+	for _, region := range regions {
+		fmt.Fprintf(w, "| %s | %d |\n", markdownCell(region), s.ByRegion[region])
+	}
+	fmt.Fprintf(w, "\n| Resource Type | Resources |\n|---|---:|\n")
+	types, rest := topCounts(s.ByType, summaryTopTypes)
+	for _, t := range types {
+		fmt.Fprintf(w, "| %s | %d |\n", markdownCell(t), s.ByType[t])
+	}
+	if rest > 0 {
+		fmt.Fprintf(w, "| %d other types | %d |\n", len(s.ByType)-len(types), rest)
+	}
+}
+
+// prependSummary inserts the summary into a finished text report right
+// after its header. The text report is written while the collectors run,
+// so the summary can only be added once the file is complete.
+func prependSummary(path string, s *executiveSummary) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	header := reportHeader()
+	if !strings.HasPrefix(string(data), header) {
+		return fmt.Errorf("%s does not start with the report header", path)
+	}
+	out := header + s.text() + string(data[len(header):])
+	return os.WriteFile(path, []byte(out), 0o644)
+}
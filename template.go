@@ -0,0 +1,105 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// templateData is what a --template is executed against.
+type templateData struct {
+	ProjectID string
+	Generated time.Time
+	Resources []inventoryRow
+}
+
+// templateSection is a report section and the resources found in it, in
+// scan order.
+type templateSection struct {
+	Title     string
+	Resources []inventoryRow
+}
+
+var templateFuncs = template.FuncMap{
+	// field returns a field of a resource, "" if it has none.
+	"field": func(row inventoryRow, key string) string { return row.field(key) },
+	// ofType keeps the resources of the given types.
+	"ofType": func(rows []inventoryRow, types ...string) []inventoryRow {
+		var out []inventoryRow
+		for _, row := range rows {
+			for _, t := range types {
+				if row.ResourceType == t {
+					out = append(out, row)
+					break
+				}
+			}
+		}
+		return out
+	},
+	// sections groups resources by report section.
+	"sections": func(rows []inventoryRow) []templateSection {
+		var out []templateSection
+		index := map[string]int{}
+		for _, row := range rows {
+			i, ok := index[row.Section]
+			if !ok {
+				i = len(out)
+				index[row.Section] = i
+				out = append(out, templateSection{Title: row.Section})
+			}
+			out[i].Resources = append(out[i].Resources, row)
+		}
+		return out
+	},
+	// types lists the distinct resource types, sorted.
+	"types": func(rows []inventoryRow) []string {
+		seen := map[string]bool{}
+		var out []string
+		for _, row := range rows {
+			if !seen[row.ResourceType] {
+				seen[row.ResourceType] = true
+				out = append(out, row.ResourceType)
+			}
+		}
+		sort.Strings(out)
+		return out
+	},
+	"region":   rowRegion,
+	"join":     strings.Join,
+	"split":    splitList,
+	"lower":    strings.ToLower,
+	"upper":    strings.ToUpper,
+	"replace":  strings.ReplaceAll,
+	"contains": strings.Contains,
+	"mdCell":   markdownCell,
+}
+
+// loadTemplateFormat parses a --template file into a report format. The
+// report takes the template's name without .tmpl, so report.md.tmpl writes
+// gcp_footprint_<project>.md; templates without an inner extension write
+// .txt.
+func loadTemplateFormat(path string) (reportFormat, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return reportFormat{}, err
+	}
+	tmpl, err := template.New(filepath.Base(path)).Funcs(templateFuncs).Parse(string(src))
+	if err != nil {
+		return reportFormat{}, err
+	}
+
+	suffix := filepath.Ext(strings.TrimSuffix(filepath.Base(path), ".tmpl"))
+	if suffix == "" {
+		suffix = ".txt"
+	}
+	return reportFormat{
+		suffix: suffix,
+		render: func(w io.Writer, rows []inventoryRow) error {
+			return tmpl.Execute(w, templateData{ProjectID: projectID, Generated: scanTime, Resources: rows})
+		},
+	}, nil
+}
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"unicode"
+)
+
+// terraformImport maps a report resource type to its Terraform resource type
+// and a function building the import ID from the inventory row.
+type terraformImport struct {
+	resourceType string
+	importID     func(row inventoryRow) string
+}
+
+var terraformImports = map[string]terraformImport{
+	"Project": {"google_project", func(row inventoryRow) string {
+		return row.ProjectID
+	}},
+	"Essential Contact": {"google_essential_contacts_contact", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/contacts/%s", row.ProjectID, row.Name)
+	}},
+	"Storage Bucket": {"google_storage_bucket", func(row inventoryRow) string {
+		return row.Name
+	}},
+	"IAM Binding": {"google_project_iam_binding", func(row inventoryRow) string {
+		if title := row.field("Condition Title"); title != "" {
+			return row.ProjectID + " " + row.Name + " " + title
+		}
+		return row.ProjectID + " " + row.Name
+	}},
+	"Audit Config": {"google_project_iam_audit_config", func(row inventoryRow) string {
+		return row.ProjectID + " " + row.Name
+	}},
+	"Service Account": {"google_service_account", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/serviceAccounts/%s", row.ProjectID, row.Name)
+	}},
+	"Custom Role": {"google_project_iam_custom_role", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/roles/%s", row.ProjectID, row.Name)
+	}},
+	"Workload Identity Pool": {"google_iam_workload_identity_pool", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/locations/global/workloadIdentityPools/%s", row.ProjectID, row.Name)
+	}},
+	"Workload Identity Provider": {"google_iam_workload_identity_pool_provider", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/locations/global/workloadIdentityPools/%s/providers/%s", row.ProjectID, row.field("Pool"), row.Name)
+	}},
+	"Compute Instance": {"google_compute_instance", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/zones/%s/instances/%s", row.ProjectID, row.field("Zone"), row.Name)
+	}},
+	"GKE Cluster": {"google_container_cluster", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/locations/%s/clusters/%s", row.ProjectID, row.field("Location"), row.Name)
+	}},
+	"Cloud SQL Instance": {"google_sql_database_instance", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/instances/%s", row.ProjectID, row.Name)
+	}},
+	"Cloud SQL Database": {"google_sql_database", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/instances/%s/databases/%s", row.ProjectID, row.field("Instance"), row.Name)
+	}},
+	"VPC Network": {"google_compute_network", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/global/networks/%s", row.ProjectID, row.Name)
+	}},
+	"Subnet": {"google_compute_subnetwork", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/regions/%s/subnetworks/%s", row.ProjectID, path.Base(row.field("Region")), row.Name)
+	}},
+	"VPC Peering": {"google_compute_network_peering", func(row inventoryRow) string {
+		return fmt.Sprintf("%s/%s/%s", row.ProjectID, row.field("Network"), row.Name)
+	}},
+	"Route": {"google_compute_route", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/global/routes/%s", row.ProjectID, row.Name)
+	}},
+	"Service Directory Namespace": {"google_service_directory_namespace", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/locations/%s/namespaces/%s", row.ProjectID, row.field("Region"), row.Name)
+	}},
+	"Transfer Job": {"google_storage_transfer_job", func(row inventoryRow) string {
+		return fmt.Sprintf("%s/%s", row.ProjectID, row.Name)
+	}},
+	"BigQuery Reservation": {"google_bigquery_reservation", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/locations/%s/reservations/%s", row.ProjectID, row.field("Location"), row.Name)
+	}},
+	"Healthcare Dataset": {"google_healthcare_dataset", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/locations/%s/datasets/%s", row.ProjectID, row.field("Region"), row.Name)
+	}},
+	"FHIR Store": {"google_healthcare_fhir_store", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/locations/%s/datasets/%s/fhirStores/%s", row.ProjectID, row.field("Region"), row.field("Dataset"), row.Name)
+	}},
+	"DICOM Store": {"google_healthcare_dicom_store", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/locations/%s/datasets/%s/dicomStores/%s", row.ProjectID, row.field("Region"), row.field("Dataset"), row.Name)
+	}},
+	"HL7v2 Store": {"google_healthcare_hl7_v2_store", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/locations/%s/datasets/%s/hl7V2Stores/%s", row.ProjectID, row.field("Region"), row.field("Dataset"), row.Name)
+	}},
+	"Dataform Repository": {"google_dataform_repository", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/locations/%s/repositories/%s", row.ProjectID, row.field("Region"), row.Name)
+	}},
+	"Looker Instance": {"google_looker_instance", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/locations/%s/instances/%s", row.ProjectID, row.field("Region"), row.Name)
+	}},
+	"reCAPTCHA Key": {"google_recaptcha_enterprise_key", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/keys/%s", row.ProjectID, row.Name)
+	}},
+	"NCC Hub": {"google_network_connectivity_hub", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/locations/global/hubs/%s", row.ProjectID, row.Name)
+	}},
+	"NCC Spoke": {"google_network_connectivity_spoke", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/locations/%s/spokes/%s", row.ProjectID, row.field("Location"), row.Name)
+	}},
+	"Static IP Address": {"google_compute_address", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/regions/%s/addresses/%s", row.ProjectID, path.Base(row.field("Region")), row.Name)
+	}},
+	"Global Static IP Address": {"google_compute_global_address", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/global/addresses/%s", row.ProjectID, row.Name)
+	}},
+	"Sole-Tenant Node Template": {"google_compute_node_template", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/regions/%s/nodeTemplates/%s", row.ProjectID, row.field("Region"), row.Name)
+	}},
+	"Sole-Tenant Node Group": {"google_compute_node_group", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/zones/%s/nodeGroups/%s", row.ProjectID, row.field("Zone"), row.Name)
+	}},
+	"Reservation": {"google_compute_reservation", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/zones/%s/reservations/%s", row.ProjectID, row.field("Zone"), row.Name)
+	}},
+	"Packet Mirroring Policy": {"google_compute_packet_mirroring", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/regions/%s/packetMirrorings/%s", row.ProjectID, row.field("Region"), row.Name)
+	}},
+	"Cloud IDS Endpoint": {"google_cloud_ids_endpoint", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/locations/%s/endpoints/%s", row.ProjectID, row.field("Zone"), row.Name)
+	}},
+	"Resource Policy": {"google_compute_resource_policy", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/regions/%s/resourcePolicies/%s", row.ProjectID, row.field("Region"), row.Name)
+	}},
+	"Image": {"google_compute_image", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/global/images/%s", row.ProjectID, row.Name)
+	}},
+	"Machine Image": {"google_compute_machine_image", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/global/machineImages/%s", row.ProjectID, row.Name)
+	}},
+	"Firewall Rule": {"google_compute_firewall", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/global/firewalls/%s", row.ProjectID, row.Name)
+	}},
+	"Persistent Disk": {"google_compute_disk", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/zones/%s/disks/%s", row.ProjectID, row.field("Zone"), row.Name)
+	}},
+	"Forwarding Rule": {"google_compute_forwarding_rule", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/regions/%s/forwardingRules/%s", row.ProjectID, path.Base(row.field("Region")), row.Name)
+	}},
+	"Global Forwarding Rule": {"google_compute_global_forwarding_rule", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/global/forwardingRules/%s", row.ProjectID, row.Name)
+	}},
+	"Cloud Armor Policy": {"google_compute_security_policy", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/global/securityPolicies/%s", row.ProjectID, row.Name)
+	}},
+	"SSL Certificate": {"google_compute_ssl_certificate", func(row inventoryRow) string {
+		if region := row.field("Region"); region != "" {
+			return fmt.Sprintf("projects/%s/regions/%s/sslCertificates/%s", row.ProjectID, path.Base(region), row.Name)
+		}
+		return fmt.Sprintf("projects/%s/global/sslCertificates/%s", row.ProjectID, row.Name)
+	}},
+	"Dataplex Lake": {"google_dataplex_lake", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/locations/%s/lakes/%s", row.ProjectID, row.field("Region"), row.Name)
+	}},
+	"Dataplex Zone": {"google_dataplex_zone", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/locations/%s/lakes/%s/zones/%s", row.ProjectID, rowRegion(row), row.field("Lake"), row.Name)
+	}},
+	"Data Catalog Taxonomy": {"google_data_catalog_taxonomy", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/locations/%s/taxonomies/%s", row.ProjectID, row.field("Region"), row.Name)
+	}},
+	"Cloud Deploy Pipeline": {"google_clouddeploy_delivery_pipeline", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/locations/%s/deliveryPipelines/%s", row.ProjectID, row.field("Region"), row.Name)
+	}},
+	"Cloud Deploy Target": {"google_clouddeploy_target", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/locations/%s/targets/%s", row.ProjectID, row.field("Region"), row.Name)
+	}},
+	"Patch Deployment": {"google_os_config_patch_deployment", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/patchDeployments/%s", row.ProjectID, row.Name)
+	}},
+	"Deployment Manager Deployment": {"google_deployment_manager_deployment", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/deployments/%s", row.ProjectID, row.Name)
+	}},
+	"TPU Node": {"google_tpu_v2_vm", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/locations/%s/nodes/%s", row.ProjectID, row.field("Zone"), row.Name)
+	}},
+	"Fleet Membership": {"google_gke_hub_membership", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/locations/%s/memberships/%s", row.ProjectID, row.field("Location"), row.Name)
+	}},
+	"Fleet Feature": {"google_gke_hub_feature", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/locations/global/features/%s", row.ProjectID, row.Name)
+	}},
+	"Binary Authorization Policy": {"google_binary_authorization_policy", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s", row.ProjectID)
+	}},
+	"Attestor": {"google_binary_authorization_attestor", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/attestors/%s", row.ProjectID, row.Name)
+	}},
+	"Certificate Manager Certificate": {"google_certificate_manager_certificate", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/locations/global/certificates/%s", row.ProjectID, row.Name)
+	}},
+	"Certificate Map": {"google_certificate_manager_certificate_map", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/locations/global/certificateMaps/%s", row.ProjectID, row.Name)
+	}},
+	"PSC Service Attachment": {"google_compute_service_attachment", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/regions/%s/serviceAttachments/%s", row.ProjectID, path.Base(row.field("Region")), row.Name)
+	}},
+	"Snapshot": {"google_compute_snapshot", func(row inventoryRow) string {
+		return fmt.Sprintf("projects/%s/global/snapshots/%s", row.ProjectID, row.Name)
+	}},
+}
+
+// writeTerraformImports writes a shell script of `terraform import` commands,
+// one per discovered resource that has a Terraform equivalent.
+func writeTerraformImports(w io.Writer, rows []inventoryRow) error {
+	fmt.Fprintf(w, "#!/bin/sh\n# terraform import commands generated by gcp_footprint\n")
+	fmt.Fprintf(w, "# Project: %s\n# Generated: %s\n\nset -e\n\n", projectID, scanTime.Format("2006-01-02 15:04:05"))
+
+	used := make(map[string]bool)
+	skipped := 0
+	for _, row := range rows {
+		imp, ok := terraformImports[row.ResourceType]
+		if !ok {
+			skipped++
+			continue
+		}
+
+		address := imp.resourceType + "." + uniqueTerraformName(used, imp.resourceType, row.Name)
+		fmt.Fprintf(w, "terraform import %s %s\n", shellQuote(address), shellQuote(imp.importID(row)))
+	}
+
+	if skipped > 0 {
+		fmt.Fprintf(w, "\n# %d resources have no Terraform import mapping and were skipped\n", skipped)
+	}
+	return nil
+}
+
+// uniqueTerraformName turns a GCP resource name into a valid Terraform
+// identifier that is unique within its resource type.
+func uniqueTerraformName(used map[string]bool, resourceType, name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	base := b.String()
+	if base == "" || !unicode.IsLetter(rune(base[0])) && base[0] != '_' {
+		base = "r_" + base
+	}
+
+	candidate := base
+	for i := 2; used[resourceType+"."+candidate]; i++ {
+		candidate = fmt.Sprintf("%s_%d", base, i)
+	}
+	used[resourceType+"."+candidate] = true
+	return candidate
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+)
+
+var (
+	terraformStateList string
+
+	// terraformStates are the --terraform-state sources: state files, gs://
+	// objects or prefixes of the GCS backend, or terraform:DIR to pull the
+	// state of the configuration in DIR from whatever backend it uses.
+	terraformStates []string
+)
+
+// terraformState is the part of a Terraform state file (format version 4)
+// the audit reads.
+type terraformState struct {
+	Version   int `json:"version"`
+	Resources []struct {
+		Module    string `json:"module"`
+		Mode      string `json:"mode"`
+		Type      string `json:"type"`
+		Name      string `json:"name"`
+		Instances []struct {
+			IndexKey   any            `json:"index_key"`
+			Attributes map[string]any `json:"attributes"`
+		} `json:"instances"`
+	} `json:"resources"`
+}
+
+// stateResource is one managed resource instance from a state.
+type stateResource struct {
+	address string
+	tfType  string
+	id      string
+	project string
+	name    string
+}
+
+// runTerraformAudit compares the inventory with the resources managed in
+// the --terraform-state states, for each resource type with a Terraform
+// equivalent. Each such row gets a Terraform field with its address, or
+// unmanaged, and the report lists the unmanaged resources and the state
+// resources the scan didn't find.
+func runTerraformAudit(ctx context.Context) error {
+	var managed []stateResource
+	var sources []string
+	for _, source := range terraformStates {
+		states, err := loadTerraformStates(ctx, source)
+		if err != nil {
+			return fmt.Errorf("load %s: %w", source, err)
+		}
+		for _, state := range states {
+			found := state.managedResources()
+			managed = append(managed, found...)
+			sources = append(sources, fmt.Sprintf("%s (%d resources)", source, len(found)))
+		}
+	}
+
+	tfTypes := map[string]string{}
+	for resourceType, imp := range terraformImports {
+		tfTypes[imp.resourceType] = resourceType
+	}
+	// State resources are matched by the import ID, which for most types
+	// is the id Terraform records, and failing that by project and name.
+	byKey := map[string]*stateResource{}
+	inState := map[string][]*stateResource{}
+	for i := range managed {
+		r := &managed[i]
+		resourceType, ok := tfTypes[r.tfType]
+		if !ok || r.project != "" && r.project != projectID {
+			continue
+		}
+		inState[resourceType] = append(inState[resourceType], r)
+		byKey[r.tfType+" "+r.id] = r
+		if r.name != "" {
+			byKey[r.tfType+" name "+r.name] = r
+		}
+	}
+
+	matched := map[*stateResource]bool{}
+	unmanaged := map[string][]*inventoryRow{}
+	discovered := map[string]int{}
+	for i := range inventory {
+		row := &inventory[i]
+		imp, ok := terraformImports[row.ResourceType]
+		if !ok {
+			continue
+		}
+		discovered[row.ResourceType]++
+		r := byKey[imp.resourceType+" "+imp.importID(*row)]
+		if r == nil {
+			r = byKey[imp.resourceType+" name "+row.Name]
+		}
+		if r == nil || matched[r] {
+			row.setField("Terraform", "unmanaged")
+			unmanaged[row.ResourceType] = append(unmanaged[row.ResourceType], row)
+			continue
+		}
+		matched[r] = true
+		row.setField("Terraform", r.address)
+	}
+
+	writeSection("TERRAFORM STATE")
+	fmt.Fprintf(report, "\nStates: %s\n", strings.Join(sources, ", "))
+	types := map[string]bool{}
+	for t := range discovered {
+		types[t] = true
+	}
+	for t := range inState {
+		types[t] = true
+	}
+	totalUnmanaged, totalMissing := 0, 0
+	for _, resourceType := range sortedKeys(types) {
+		var missing []*stateResource
+		for _, r := range inState[resourceType] {
+			if !matched[r] {
+				missing = append(missing, r)
+			}
+		}
+		fmt.Fprintf(report, "\n%s (%s): %d discovered, %d in state\n",
+			resourceType, terraformImports[resourceType].resourceType, discovered[resourceType], len(inState[resourceType]))
+		if rows := unmanaged[resourceType]; len(rows) > 0 {
+			fmt.Fprintf(report, "  Unmanaged (%d):\n", len(rows))
+			for _, row := range rows {
+				fmt.Fprintf(report, "    %s (%s)\n", row.Name, terraformImports[resourceType].importID(*row))
+			}
+		}
+		if len(missing) > 0 {
+			fmt.Fprintf(report, "  In state but not found (%d):\n", len(missing))
+			for _, r := range missing {
+				fmt.Fprintf(report, "    %s (%s)\n", r.address, r.id)
+			}
+			if discovered[resourceType] == 0 {
+				fmt.Fprintln(report, "  None were discovered: check that the collector ran.")
+			}
+		}
+		totalUnmanaged += len(unmanaged[resourceType])
+		totalMissing += len(missing)
+	}
+	fmt.Fprintf(report, "\n%d discovered resources are not managed by Terraform; %d state resources no longer exist\n", totalUnmanaged, totalMissing)
+	return nil
+}
+
+// managedResources returns the state's managed resource instances, with
+// their addresses as terraform state list shows them.
+func (s terraformState) managedResources() []stateResource {
+	var found []stateResource
+	for _, res := range s.Resources {
+		if res.Mode != "managed" {
+			continue
+		}
+		address := res.Type + "." + res.Name
+		if res.Module != "" {
+			address = res.Module + "." + address
+		}
+		for _, inst := range res.Instances {
+			r := stateResource{address: address, tfType: res.Type}
+			switch key := inst.IndexKey.(type) {
+			case string:
+				r.address += fmt.Sprintf("[%q]", key)
+			case float64:
+				r.address += fmt.Sprintf("[%d]", int(key))
+			}
+			r.id, _ = inst.Attributes["id"].(string)
+			r.project, _ = inst.Attributes["project"].(string)
+			r.name, _ = inst.Attributes["name"].(string)
+			// Names of zonal and regional resources are only unique within
+			// their location; those Terraform reports as a full URL.
+			if r.name != "" && strings.Contains(r.name, "/") {
+				r.name = path.Base(r.name)
+			}
+			found = append(found, r)
+		}
+	}
+	return found
+}
+
+// loadTerraformStates reads the states of one --terraform-state source.
+// A gs:// prefix ending in / reads the state of every workspace under it,
+// the <workspace>.tfstate objects the GCS backend writes.
+func loadTerraformStates(ctx context.Context, source string) ([]terraformState, error) {
+	var blobs [][]byte
+	switch {
+	case strings.HasPrefix(source, "terraform:"):
+		cmd := exec.CommandContext(ctx, "terraform", "-chdir="+strings.TrimPrefix(source, "terraform:"), "state", "pull")
+		cmd.Stderr = os.Stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("terraform state pull: %w", err)
+		}
+		blobs = append(blobs, out)
+	case strings.HasPrefix(source, "gs://"):
+		objects, err := readGCSObjects(ctx, source, ".tfstate")
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objects {
+			blobs = append(blobs, obj.data)
+		}
+	default:
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return nil, err
+		}
+		blobs = append(blobs, data)
+	}
+
+	var states []terraformState
+	for _, data := range blobs {
+		var state terraformState
+		if err := json.Unmarshal(data, &state); err != nil {
+			return nil, err
+		}
+		if state.Version != 4 {
+			slog.Warn("Terraform state format may not be supported", "source", source, "version", state.Version)
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
@@ -0,0 +1,269 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// topology is the network graph rendered by the dot and mermaid formats:
+// each VPC is a group holding its subnets and attached workloads, with
+// peerings drawn between networks and internet-facing load balancers hung
+// off a shared Internet node.
+type topology struct {
+	nodes  []topologyNode
+	byID   map[string]int
+	edges  []topologyEdge
+	seen   map[string]bool
+	groups []string
+}
+
+type topologyNode struct {
+	id    string
+	label string
+	kind  string // network, subnet, instance, lb, internet
+	group string // id of the owning network, "" for top level
+}
+
+type topologyEdge struct {
+	from, to string
+	peering  bool
+}
+
+const internetNode = "internet"
+
+func buildTopology(rows []inventoryRow) *topology {
+	t := &topology{byID: make(map[string]int), seen: make(map[string]bool)}
+
+	for _, row := range rows {
+		switch row.ResourceType {
+		case "VPC Network":
+			id := t.network(projectID, row.Name)
+			for _, peering := range splitList(row.field("Peerings")) {
+				_, rest, _ := strings.Cut(peering, "=")
+				peerURL, _, _ := strings.Cut(rest, " ")
+				peerProject, peerName := networkFromURL(peerURL)
+				t.edge(id, t.network(peerProject, peerName), true)
+			}
+
+		case "Subnet":
+			t.subnet(row.field("Network"), path.Base(row.field("Region")), row.Name, row.field("IP Range"))
+
+		case "Compute Instance":
+			id := sanitizeID("vm_" + row.field("Zone") + "_" + row.Name)
+			label := fmt.Sprintf("%s\n%s", row.Name, row.field("Zone"))
+			if row.field("Network") == "" {
+				t.node(id, label, "instance", "")
+				continue
+			}
+			parent := t.attachPoint(row.field("Network"), row.field("Subnet"))
+			t.node(id, label, "instance", t.nodes[t.byID[parent]].group)
+			t.edge(id, parent, false)
+
+		case "Forwarding Rule", "Global Forwarding Rule":
+			region := "global"
+			if row.field("Region") != "" {
+				region = path.Base(row.field("Region"))
+			}
+			id := sanitizeID("lb_" + region + "_" + row.Name)
+			label := fmt.Sprintf("%s\n%s %s:%s\n%s", row.Name, row.field("Protocol"),
+				row.field("IP Address"), row.field("Ports"), row.field("Scheme"))
+
+			if row.field("Network") == "" {
+				t.node(id, label, "lb", "")
+				t.node(internetNode, "Internet", "internet", "")
+				t.edge(internetNode, id, false)
+				continue
+			}
+			parent := t.attachPoint(row.field("Network"), row.field("Subnet"))
+			t.node(id, label, "lb", t.nodes[t.byID[parent]].group)
+			t.edge(id, parent, false)
+		}
+	}
+	return t
+}
+
+// network returns the node ID for a VPC, adding it if needed. Networks from
+// other projects (peers, Shared VPC hosts) are labelled with their project.
+func (t *topology) network(project, name string) string {
+	id := sanitizeID("net_" + project + "_" + name)
+	label := name
+	if project != projectID {
+		label = project + "/" + name
+	}
+	if _, ok := t.byID[id]; !ok {
+		t.groups = append(t.groups, id)
+	}
+	t.node(id, label, "network", id)
+	return id
+}
+
+func (t *topology) subnet(networkURL, region, name, cidr string) string {
+	netID := t.network(networkFromURL(networkURL))
+	id := sanitizeID("subnet_" + netID + "_" + region + "_" + name)
+	label := fmt.Sprintf("%s\n%s", name, region)
+	if cidr != "" {
+		label += "\n" + cidr
+	}
+	t.node(id, label, "subnet", netID)
+	return id
+}
+
+// attachPoint returns the subnet a workload sits in, falling back to its
+// network when the subnet is unknown (legacy networks).
+func (t *topology) attachPoint(networkURL, subnetURL string) string {
+	if subnetURL == "" {
+		return t.network(networkFromURL(networkURL))
+	}
+	region := path.Base(path.Dir(path.Dir(subnetURL)))
+	return t.subnet(networkURL, region, path.Base(subnetURL), "")
+}
+
+// node adds a node, keeping the first (most detailed) label when a node is
+// referenced before the resource that defines it.
+func (t *topology) node(id, label, kind, group string) {
+	if i, ok := t.byID[id]; ok {
+		if strings.Count(label, "\n") > strings.Count(t.nodes[i].label, "\n") {
+			t.nodes[i].label = label
+		}
+		return
+	}
+	t.byID[id] = len(t.nodes)
+	t.nodes = append(t.nodes, topologyNode{id: id, label: label, kind: kind, group: group})
+}
+
+func (t *topology) edge(from, to string, peering bool) {
+	key := from + "->" + to
+	if peering && to < from {
+		key = to + "->" + from
+	}
+	if t.seen[key] {
+		return
+	}
+	t.seen[key] = true
+	t.edges = append(t.edges, topologyEdge{from: from, to: to, peering: peering})
+}
+
+func (t *topology) members(group string) []topologyNode {
+	var nodes []topologyNode
+	for _, n := range t.nodes {
+		if n.group == group {
+			nodes = append(nodes, n)
+		}
+	}
+	return nodes
+}
+
+func writeTopologyDOT(w io.Writer, rows []inventoryRow) error {
+	t := buildTopology(rows)
+	shapes := map[string]string{
+		"network":  "ellipse",
+		"subnet":   "box",
+		"instance": "component",
+		"lb":       "diamond",
+		"internet": "doublecircle",
+	}
+	dotNode := func(n topologyNode, indent string) {
+		fmt.Fprintf(w, "%s%q [label=%q, shape=%s];\n", indent, n.id, n.label, shapes[n.kind])
+	}
+
+	fmt.Fprintf(w, "digraph %q {\n", "gcp_footprint_"+projectID)
+	fmt.Fprintln(w, "  rankdir=LR;")
+	fmt.Fprintln(w, "  node [fontname=\"Helvetica\", fontsize=10];")
+	fmt.Fprintln(w, "  compound=true;")
+
+	for _, group := range t.groups {
+		fmt.Fprintf(w, "\n  subgraph %q {\n", "cluster_"+group)
+		fmt.Fprintf(w, "    label=%q;\n    style=rounded;\n", "VPC: "+t.nodes[t.byID[group]].label)
+		for _, n := range t.members(group) {
+			dotNode(n, "    ")
+		}
+		fmt.Fprintln(w, "  }")
+	}
+
+	fmt.Fprintln(w)
+	for _, n := range t.members("") {
+		dotNode(n, "  ")
+	}
+
+	for _, e := range t.edges {
+		if e.peering {
+			fmt.Fprintf(w, "  %q -> %q [style=dashed, dir=both, label=\"peering\"];\n", e.from, e.to)
+		} else {
+			fmt.Fprintf(w, "  %q -> %q;\n", e.from, e.to)
+		}
+	}
+	fmt.Fprintln(w, "}")
+	return nil
+}
+
+func writeTopologyMermaid(w io.Writer, rows []inventoryRow) error {
+	t := buildTopology(rows)
+	mermaidNode := func(n topologyNode, indent string) {
+		label := strings.ReplaceAll(strings.ReplaceAll(n.label, `"`, "#quot;"), "\n", "<br/>")
+		switch n.kind {
+		case "network":
+			fmt.Fprintf(w, "%s%s([\"%s\"])\n", indent, n.id, label)
+		case "lb":
+			fmt.Fprintf(w, "%s%s{{\"%s\"}}\n", indent, n.id, label)
+		case "internet":
+			fmt.Fprintf(w, "%s%s((\"%s\"))\n", indent, n.id, label)
+		default:
+			fmt.Fprintf(w, "%s%s[\"%s\"]\n", indent, n.id, label)
+		}
+	}
+
+	fmt.Fprintln(w, "flowchart LR")
+	for _, group := range t.groups {
+		label := strings.ReplaceAll(t.nodes[t.byID[group]].label, `"`, "#quot;")
+		fmt.Fprintf(w, "  subgraph cluster_%s[\"VPC: %s\"]\n", group, label)
+		for _, n := range t.members(group) {
+			mermaidNode(n, "    ")
+		}
+		fmt.Fprintln(w, "  end")
+	}
+	for _, n := range t.members("") {
+		mermaidNode(n, "  ")
+	}
+
+	for _, e := range t.edges {
+		if e.peering {
+			fmt.Fprintf(w, "  %s -. peering .- %s\n", e.from, e.to)
+		} else {
+			fmt.Fprintf(w, "  %s --> %s\n", e.from, e.to)
+		}
+	}
+	return nil
+}
+
+// networkFromURL extracts the project and name from a network self link such
+// as https://www.googleapis.com/compute/v1/projects/p/global/networks/n.
+func networkFromURL(url string) (project, name string) {
+	name = path.Base(url)
+	project = projectID
+	if _, rest, ok := strings.Cut(url, "projects/"); ok {
+		project, _, _ = strings.Cut(rest, "/")
+	}
+	return project, name
+}
+
+func sanitizeID(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' {
+			return r
+		}
+		return '_'
+	}, s)
+}
+
+// splitList splits a comma-separated report field, ignoring empty values.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ", ") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
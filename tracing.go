@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	otlpEndpoint string
+	otlpProtocol string
+
+	// tracer makes the scan and collector spans. It is a no-op until
+	// setupTracing installs an exporter.
+	tracer = otel.Tracer("github.com/markyjacksonfishing/gcp_footprint")
+
+	// tracerProvider exports the spans, nil without tracing.
+	tracerProvider *sdktrace.TracerProvider
+
+	// collectorSpan is the span of the running collector, which errors
+	// logged while it runs are recorded on. Collectors run one at a time,
+	// but errors can be logged from any goroutine.
+	collectorSpan   trace.Span
+	collectorSpanMu sync.Mutex
+)
+
+// defaultOTLPProtocol is the --otlp-protocol default, from the standard
+// environment variables.
+func defaultOTLPProtocol() string {
+	for _, name := range []string{"OTEL_EXPORTER_OTLP_TRACES_PROTOCOL", "OTEL_EXPORTER_OTLP_PROTOCOL"} {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return "http/protobuf"
+}
+
+// tracingEnabled reports whether spans should be exported: with
+// --otlp-endpoint, or with the standard OpenTelemetry environment
+// variables naming an endpoint.
+func tracingEnabled() bool {
+	return otlpEndpoint != "" || os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// setupTracing installs a tracer provider that exports spans over OTLP,
+// using HTTP/protobuf or gRPC as --otlp-protocol says. Anything the flags
+// leave unset, such as headers or TLS, comes from the OTEL_EXPORTER_OTLP_*
+// environment variables.
+func setupTracing(ctx context.Context) error {
+	var endpoint *url.URL
+	if otlpEndpoint != "" {
+		u, err := url.Parse(otlpEndpoint)
+		if err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+			return fmt.Errorf("invalid --otlp-endpoint %q, expected a URL such as http://localhost:4318", otlpEndpoint)
+		}
+		endpoint = u
+	}
+
+	var client otlptrace.Client
+	switch otlpProtocol {
+	case "http/protobuf", "http":
+		var opts []otlptracehttp.Option
+		if endpoint != nil {
+			opts = append(opts, otlptracehttp.WithEndpoint(endpoint.Host))
+			if endpoint.Scheme == "http" {
+				opts = append(opts, otlptracehttp.WithInsecure())
+			}
+			if endpoint.Path != "" && endpoint.Path != "/" {
+				opts = append(opts, otlptracehttp.WithURLPath(endpoint.Path))
+			}
+		}
+		client = otlptracehttp.NewClient(opts...)
+	case "grpc":
+		var opts []otlptracegrpc.Option
+		if endpoint != nil {
+			opts = append(opts, otlptracegrpc.WithEndpoint(endpoint.Host))
+			if endpoint.Scheme == "http" {
+				opts = append(opts, otlptracegrpc.WithInsecure())
+			}
+		}
+		client = otlptracegrpc.NewClient(opts...)
+	default:
+		return fmt.Errorf("invalid --otlp-protocol %q, expected http/protobuf or grpc", otlpProtocol)
+	}
+
+	exporter, err := otlptrace.New(ctx, client)
+	if err != nil {
+		return fmt.Errorf("create OTLP exporter: %w", err)
+	}
+	// OTEL_SERVICE_NAME and OTEL_RESOURCE_ATTRIBUTES override the defaults.
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName("gcp_footprint"), semconv.ServiceVersion(toolVersion())),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		return fmt.Errorf("create trace resource: %w", err)
+	}
+	tracerProvider = sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		slog.Warn("Failed to export traces", "error", err)
+	}))
+	return nil
+}
+
+// shutdownTracing exports the spans still buffered, before the process
+// exits.
+func shutdownTracing() {
+	if tracerProvider == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := tracerProvider.Shutdown(ctx); err != nil {
+		slog.Warn("Failed to flush traces", "error", err)
+	}
+}
+
+// startScanSpan starts the root span of a scan.
+func startScanSpan(ctx context.Context) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "scan", trace.WithAttributes(attribute.String("gcp.project_id", projectID)))
+}
+
+// endScanSpan records the scan's result on its span and ends it.
+func endScanSpan(span trace.Span, err error) {
+	span.SetAttributes(
+		attribute.Int("gcp_footprint.resources", len(withoutScanMetadata(inventory))),
+		attribute.Int64("gcp_footprint.errors", scanErrors.Load()),
+	)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// startCollectorSpan starts the span of one collector run, a child of the
+// scan span in ctx.
+func startCollectorSpan(ctx context.Context, c collector, region string) (context.Context, trace.Span) {
+	if region == "" {
+		region = "global"
+	}
+	ctx, span := tracer.Start(ctx, c.name, trace.WithAttributes(
+		attribute.String("gcp_footprint.collector", c.name),
+		attribute.String("gcp_footprint.service", collectorService(c)),
+		attribute.String("cloud.region", region),
+		attribute.String("gcp.project_id", projectID),
+	))
+	collectorSpanMu.Lock()
+	collectorSpan = span
+	collectorSpanMu.Unlock()
+	return ctx, span
+}
+
+// endCollectorSpan records the collector's result on its span and ends it.
+func endCollectorSpan(ctx context.Context, span trace.Span, resources int, errs int64) {
+	span.SetAttributes(
+		attribute.Int("gcp_footprint.resources", resources),
+		attribute.Int64("gcp_footprint.errors", errs),
+	)
+	switch {
+	case ctx.Err() != nil:
+		span.SetStatus(codes.Error, ctx.Err().Error())
+	case errs > 0:
+		span.SetStatus(codes.Error, fmt.Sprintf("%d API errors", errs))
+	}
+	span.End()
+	collectorSpanMu.Lock()
+	collectorSpan = nil
+	collectorSpanMu.Unlock()
+}
+
+// traceError adds an error logged while a collector runs to its span.
+func traceError(r slog.Record) {
+	collectorSpanMu.Lock()
+	span := collectorSpan
+	collectorSpanMu.Unlock()
+	if span == nil || !span.IsRecording() {
+		return
+	}
+	message := r.Message
+	r.Attrs(func(a slog.Attr) bool {
+		if a.Key == "error" {
+			message += ": " + a.Value.String()
+		}
+		return true
+	})
+	span.AddEvent("exception", trace.WithAttributes(attribute.String("exception.message", message)))
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+
+	"google.golang.org/api/storagetransfer/v1"
+)
+
+// transferApplianceOrder is a Transfer Appliance order, as returned by
+// transferappliance.googleapis.com/v1alpha1. Only the fields the report
+// uses are decoded.
+type transferApplianceOrder struct {
+	Name        string   `json:"name"`
+	DisplayName string   `json:"displayName"`
+	State       string   `json:"state"`
+	Appliances  []string `json:"appliances"`
+	SubmitTime  string   `json:"submitTime"`
+}
+
+// getTransferJobs lists Storage Transfer Service jobs with where they copy
+// data from and to and when they run, and Transfer Appliance orders.
+func getTransferJobs(ctx context.Context) {
+	transferService, err := storagetransfer.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create Storage Transfer service", "error", err)
+		return
+	}
+
+	count := 0
+	filter := fmt.Sprintf(`{"projectId":%q}`, projectID)
+	err = transferService.TransferJobs.List(filter).Pages(ctx, func(page *storagetransfer.ListTransferJobsResponse) error {
+		for _, job := range page.TransferJobs {
+			source, sink := transferEndpoints(job.TransferSpec)
+			info := fmt.Sprintf("Name: %s\nDescription: %s\nStatus: %s\nSource: %s\nSink: %s\nSchedule: %s\nLast Operation: %s\nModified: %s",
+				path.Base(job.Name), oneLine(job.Description), job.Status, source, sink, transferSchedule(job.Schedule),
+				path.Base(job.LatestOperationName), job.LastModificationTime)
+			writeResourceRaw("Transfer Job", info, job)
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		// Skip projects that don't have the Storage Transfer API enabled
+		slog.Debug("Skipping Storage Transfer jobs", "error", err)
+	}
+
+	endpoint := fmt.Sprintf("https://transferappliance.googleapis.com/v1alpha1/projects/%s/locations/-/orders", projectID)
+	err = listREST(ctx, endpoint, func(page *struct {
+		Orders []transferApplianceOrder `json:"orders"`
+	}) error {
+		for _, order := range page.Orders {
+			info := fmt.Sprintf("Name: %s\nDisplay Name: %s\nLocation: %s\nState: %s\nAppliances: %d\nSubmitted: %s",
+				path.Base(order.Name), order.DisplayName, membershipLocation(order.Name), order.State,
+				len(order.Appliances), order.SubmitTime)
+			writeResourceRaw("Transfer Appliance Order", info, order)
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		// Skip projects that don't have the Transfer Appliance API enabled
+		slog.Debug("Skipping Transfer Appliance orders", "error", err)
+	}
+	scanProgress.found(count)
+}
+
+// transferEndpoints describes where a transfer job reads from and writes to.
+func transferEndpoints(spec *storagetransfer.TransferSpec) (string, string) {
+	if spec == nil {
+		return "", ""
+	}
+	source := ""
+	switch {
+	case spec.GcsDataSource != nil:
+		source = "gs://" + spec.GcsDataSource.BucketName
+	case spec.AwsS3DataSource != nil:
+		source = "s3://" + spec.AwsS3DataSource.BucketName
+	case spec.AwsS3CompatibleDataSource != nil:
+		source = fmt.Sprintf("s3-compatible %s/%s", spec.AwsS3CompatibleDataSource.Endpoint, spec.AwsS3CompatibleDataSource.BucketName)
+	case spec.AzureBlobStorageDataSource != nil:
+		source = fmt.Sprintf("azure://%s/%s", spec.AzureBlobStorageDataSource.StorageAccount, spec.AzureBlobStorageDataSource.Container)
+	case spec.HttpDataSource != nil:
+		source = spec.HttpDataSource.ListUrl
+	case spec.PosixDataSource != nil:
+		source = "posix " + spec.PosixDataSource.RootDirectory
+	}
+	sink := ""
+	switch {
+	case spec.GcsDataSink != nil:
+		sink = "gs://" + spec.GcsDataSink.BucketName
+	case spec.PosixDataSink != nil:
+		sink = "posix " + spec.PosixDataSink.RootDirectory
+	}
+	return source, sink
+}
+
+// transferSchedule describes when a transfer job runs.
+func transferSchedule(schedule *storagetransfer.Schedule) string {
+	if schedule == nil || schedule.ScheduleStartDate == nil {
+		return "on demand"
+	}
+	start := schedule.ScheduleStartDate
+	s := fmt.Sprintf("from %04d-%02d-%02d", start.Year, start.Month, start.Day)
+	if end := schedule.ScheduleEndDate; end != nil {
+		if end.Year == start.Year && end.Month == start.Month && end.Day == start.Day {
+			return fmt.Sprintf("once on %04d-%02d-%02d", start.Year, start.Month, start.Day)
+		}
+		s += fmt.Sprintf(" to %04d-%02d-%02d", end.Year, end.Month, end.Day)
+	}
+	if schedule.RepeatInterval != "" {
+		s += ", every " + schedule.RepeatInterval
+	} else {
+		s += ", daily"
+	}
+	return s
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+var serveUI bool
+
+// uiFiles is the web dashboard: static files that read the HTTP API from
+// the browser, so the server needs no handlers of its own for it.
+//
+//go:embed ui
+var uiFiles embed.FS
+
+// uiHandler serves the dashboard from the root of the server.
+func uiHandler() http.Handler {
+	files, err := fs.Sub(uiFiles, "ui")
+	if err != nil {
+		panic(err) // the embedded directory always exists
+	}
+	return http.FileServerFS(files)
+}
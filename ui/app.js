@@ -0,0 +1,248 @@
+// The dashboard served by `gcp_footprint serve --ui`. It only reads the
+// HTTP API (/scan, /inventory and /diff), so it shows exactly what other
+// clients see.
+"use strict";
+
+// Approximate coordinates of each region, for the region map.
+const regionCoords = {
+  "us-central1": [41.3, -95.9], "us-east1": [33.2, -80.0], "us-east4": [39.0, -77.5],
+  "us-east5": [40.0, -83.0], "us-south1": [32.8, -96.8], "us-west1": [45.6, -121.2],
+  "us-west2": [34.1, -118.2], "us-west3": [40.8, -111.9], "us-west4": [36.2, -115.1],
+  "northamerica-northeast1": [45.5, -73.6], "northamerica-northeast2": [43.7, -79.4],
+  "southamerica-east1": [-23.6, -46.6], "southamerica-west1": [-33.5, -70.7],
+  "europe-west1": [50.5, 3.8], "europe-west2": [51.5, -0.1], "europe-west3": [50.1, 8.7],
+  "europe-west4": [53.4, 6.8], "europe-west6": [47.4, 8.5], "europe-west8": [45.5, 9.2],
+  "europe-west9": [48.9, 2.4], "europe-west10": [52.5, 13.4], "europe-west12": [45.1, 7.7],
+  "europe-southwest1": [40.4, -3.7], "europe-north1": [60.6, 27.2], "europe-central2": [52.2, 21.0],
+  "me-west1": [32.1, 34.8], "me-central1": [25.3, 51.5], "me-central2": [26.4, 50.1],
+  "africa-south1": [-26.2, 28.0],
+  "asia-east1": [24.1, 120.5], "asia-east2": [22.3, 114.2], "asia-northeast1": [35.7, 139.7],
+  "asia-northeast2": [34.7, 135.5], "asia-northeast3": [37.6, 127.0], "asia-south1": [19.1, 72.9],
+  "asia-south2": [28.7, 77.1], "asia-southeast1": [1.4, 103.8], "asia-southeast2": [-6.2, 106.8],
+  "australia-southeast1": [-33.9, 151.2], "australia-southeast2": [-37.8, 145.0],
+};
+
+let resources = [];
+let selected = null;
+
+const $ = (id) => document.getElementById(id);
+
+function el(tag, text, className) {
+  const e = document.createElement(tag);
+  if (text !== undefined) e.textContent = text;
+  if (className) e.className = className;
+  return e;
+}
+
+async function getJSON(path) {
+  const resp = await fetch(path);
+  const body = await resp.json();
+  if (!resp.ok) throw new Error(body.error || resp.statusText);
+  return body;
+}
+
+// region returns the region of a location, so zonal resources are counted
+// with their region.
+function region(location) {
+  return (location || "global").replace(/^([a-z]+-[a-z]+\d+)-[a-z]$/, "$1");
+}
+
+async function loadStatus() {
+  try {
+    const st = await getJSON("scan");
+    $("project").textContent = st.project_id;
+    let text = st.last_scan ? `Last scan ${new Date(st.last_scan).toLocaleString()}, ${st.resources} resources` : "No completed scan yet";
+    if (st.running) text += " (scan running)";
+    $("status").textContent = text;
+    $("status").className = st.last_error ? "error" : "";
+    if (st.last_error) $("status").title = st.last_error;
+    $("scan").disabled = st.running;
+    return st;
+  } catch (err) {
+    $("status").textContent = err.message;
+    $("status").className = "error";
+  }
+}
+
+async function loadInventory() {
+  try {
+    const inv = await getJSON("inventory");
+    resources = inv.inventory || [];
+  } catch (err) {
+    resources = [];
+    $("details").replaceChildren(el("p", err.message, "muted"));
+  }
+  fillSelect($("type"), resources.map((r) => r.resource_type));
+  fillSelect($("location"), resources.map((r) => r.location));
+  renderTable();
+  renderMap();
+}
+
+function fillSelect(select, values) {
+  const current = select.value;
+  while (select.options.length > 1) select.remove(1);
+  for (const v of [...new Set(values)].sort()) select.add(new Option(v, v));
+  select.value = current;
+}
+
+function matches(r, query) {
+  if (!query) return true;
+  const text = [r.name, r.id, r.resource_type, ...(r.fields || []).map((f) => f.value)].join("\n").toLowerCase();
+  return text.includes(query);
+}
+
+const tableLimit = 1000;
+
+function renderTable() {
+  const query = $("search").value.trim().toLowerCase();
+  const type = $("type").value;
+  const location = $("location").value;
+  const rows = resources.filter((r) =>
+    (!type || r.resource_type === type) && (!location || r.location === location) && matches(r, query));
+
+  const body = $("table").tBodies[0];
+  body.replaceChildren();
+  for (const r of rows.slice(0, tableLimit)) {
+    const tr = el("tr");
+    for (const v of [r.resource_type, r.name, r.location, r.section]) tr.append(el("td", v));
+    if (r === selected) tr.className = "selected";
+    tr.onclick = () => {
+      selected = r;
+      renderTable();
+      renderDetails(r);
+    };
+    body.append(tr);
+  }
+  $("count").textContent = rows.length > tableLimit
+    ? `Showing ${tableLimit} of ${rows.length} resources`
+    : `${rows.length} resources`;
+}
+
+function renderDetails(r) {
+  const dl = el("dl");
+  const add = (k, v) => {
+    if (!v) return;
+    dl.append(el("dt", k), el("dd", v));
+  };
+  add("ID", r.id);
+  add("Asset Type", r.asset_type);
+  add("Location", r.location);
+  add("Zone", r.zone);
+  for (const f of r.fields || []) add(f.key, f.value);
+  for (const [k, v] of Object.entries(r.labels || {})) add(`label ${k}`, v);
+  $("details").replaceChildren(el("h2", r.name), dl);
+}
+
+function renderMap() {
+  const counts = {};
+  for (const r of resources) {
+    const reg = region(r.location);
+    counts[reg] = (counts[reg] || 0) + 1;
+  }
+
+  const svg = $("regions");
+  const ns = "http://www.w3.org/2000/svg";
+  svg.replaceChildren();
+  for (let lon = -180; lon <= 180; lon += 30) {
+    const line = document.createElementNS(ns, "line");
+    Object.entries({ x1: lon + 180, y1: 0, x2: lon + 180, y2: 180, class: "grid" }).forEach(([k, v]) => line.setAttribute(k, v));
+    svg.append(line);
+  }
+  for (let lat = -90; lat <= 90; lat += 30) {
+    const line = document.createElementNS(ns, "line");
+    Object.entries({ x1: 0, y1: 90 - lat, x2: 360, y2: 90 - lat, class: "grid" }).forEach(([k, v]) => line.setAttribute(k, v));
+    svg.append(line);
+  }
+
+  const unmapped = [];
+  for (const [reg, count] of Object.entries(counts).sort((a, b) => b[1] - a[1])) {
+    const coords = regionCoords[reg];
+    if (!coords) {
+      unmapped.push(`${reg} (${count})`);
+      continue;
+    }
+    const [lat, lon] = coords;
+    const circle = document.createElementNS(ns, "circle");
+    circle.setAttribute("cx", lon + 180);
+    circle.setAttribute("cy", 90 - lat);
+    circle.setAttribute("r", Math.min(1 + Math.sqrt(count) * 0.5, 12));
+    const title = document.createElementNS(ns, "title");
+    title.textContent = `${reg}: ${count} resources`;
+    circle.append(title);
+    circle.onclick = () => showRegion(reg);
+    const label = document.createElementNS(ns, "text");
+    label.setAttribute("x", lon + 182);
+    label.setAttribute("y", 90 - lat);
+    label.textContent = `${reg} ${count}`;
+    svg.append(circle, label);
+  }
+  $("global").textContent = unmapped.length ? `Not on the map: ${unmapped.join(", ")}` : "";
+}
+
+function showRegion(reg) {
+  $("location").value = "";
+  $("search").value = "";
+  $("type").value = "";
+  // Zones and the region itself both belong to the region.
+  const locations = [...$("location").options].map((o) => o.value).filter((v) => v && region(v) === reg);
+  if (locations.length === 1) $("location").value = locations[0];
+  else $("search").value = reg;
+  renderTable();
+  show("resources");
+}
+
+async function loadDiff() {
+  let diff;
+  try {
+    diff = await getJSON("diff");
+  } catch (err) {
+    $("diff-range").textContent = err.message;
+    for (const id of ["added", "removed", "changed"]) {
+      $(id).replaceChildren();
+      $(`${id}-count`).textContent = "";
+    }
+    return;
+  }
+  $("diff-range").textContent = `From ${new Date(diff.from).toLocaleString()} to ${new Date(diff.to).toLocaleString()}`;
+  const list = (id, items, render) => {
+    $(`${id}-count`).textContent = `(${items.length})`;
+    $(id).replaceChildren(...items.map(render));
+  };
+  const describe = (r) => el("li", `${r.resource_type}: ${r.name} (${r.location || "global"})`);
+  list("added", diff.diff.added, describe);
+  list("removed", diff.diff.removed, describe);
+  list("changed", diff.diff.changed, (c) => {
+    const li = el("li", `${c.resource_type}: ${c.name} `);
+    for (const f of c.fields) {
+      li.append(el("br"), `${f.key}: `, el("span", f.before, "before"), " → ", el("span", f.after, "after"));
+    }
+    return li;
+  });
+}
+
+function show(view) {
+  for (const section of document.querySelectorAll(".view")) section.hidden = section.id !== view;
+  for (const button of document.querySelectorAll("nav button")) button.classList.toggle("active", button.dataset.view === view);
+  if (view === "diff") loadDiff();
+}
+
+let lastScan = null;
+
+// refresh reloads the inventory whenever a new scan has finished.
+async function refresh() {
+  const st = await loadStatus();
+  if (st && st.last_scan !== lastScan) {
+    lastScan = st.last_scan;
+    await loadInventory();
+  }
+}
+
+for (const button of document.querySelectorAll("nav button")) button.onclick = () => show(button.dataset.view);
+for (const id of ["search", "type", "location"]) $(id).oninput = renderTable;
+$("scan").onclick = async () => {
+  await fetch("scan", { method: "POST" });
+  loadStatus();
+};
+
+refresh();
+setInterval(refresh, 10000);
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+// uploadReports copies the generated report files to Cloud Storage. Object
+// names get a UTC timestamp so scheduled runs never overwrite each other.
+func uploadReports(ctx context.Context, dest string, fileNames ...string) error {
+	bucket, prefix, err := parseGCSURL(dest)
+	if err != nil {
+		return err
+	}
+
+	client, err := storage.NewClient(ctx, clientOptions...)
+	if err != nil {
+		return fmt.Errorf("create storage client: %w", err)
+	}
+	defer client.Close()
+
+	stamp := time.Now().UTC().Format("20060102T150405Z")
+	for _, fileName := range fileNames {
+		ext := filepath.Ext(fileName)
+		base := strings.TrimSuffix(filepath.Base(fileName), ext)
+		object := fmt.Sprintf("%s%s_%s%s", prefix, base, stamp, ext)
+
+		if err := uploadFile(ctx, client.Bucket(bucket).Object(object), fileName); err != nil {
+			return fmt.Errorf("upload %s: %w", fileName, err)
+		}
+		fmt.Fprintf(console, "Report uploaded to: gs://%s/%s\n", bucket, object)
+	}
+	return nil
+}
+
+func uploadFile(ctx context.Context, obj *storage.ObjectHandle, fileName string) error {
+	f, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := obj.NewWriter(ctx)
+	if _, err := io.Copy(w, f); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// parseGCSURL splits gs://bucket/path/ into the bucket and an object prefix.
+// A non-empty prefix always ends in "/".
+func parseGCSURL(url string) (bucket, prefix string, err error) {
+	rest, ok := strings.CutPrefix(url, "gs://")
+	if !ok || rest == "" {
+		return "", "", fmt.Errorf("invalid Cloud Storage URL %q, expected gs://bucket/path/", url)
+	}
+
+	bucket, prefix, _ = strings.Cut(rest, "/")
+	if bucket == "" {
+		return "", "", fmt.Errorf("invalid Cloud Storage URL %q, missing bucket", url)
+	}
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return bucket, prefix, nil
+}
+
+// gcsObject is an object read by readGCSObjects.
+type gcsObject struct {
+	url  string
+	data []byte
+}
+
+// readGCSObjects reads the object at gs://bucket/name or, if the URL ends
+// in "/", every object under it whose name ends in suffix.
+func readGCSObjects(ctx context.Context, url, suffix string) ([]gcsObject, error) {
+	bucket, name, _ := strings.Cut(strings.TrimPrefix(url, "gs://"), "/")
+	if bucket == "" {
+		return nil, fmt.Errorf("invalid Cloud Storage URL %q, missing bucket", url)
+	}
+	client, err := storage.NewClient(ctx, clientOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("create storage client: %w", err)
+	}
+	defer client.Close()
+	b := client.Bucket(bucket)
+
+	names := []string{name}
+	if name == "" || strings.HasSuffix(name, "/") {
+		names = nil
+		it := b.Objects(ctx, &storage.Query{Prefix: name})
+		for {
+			attrs, err := it.Next()
+			if err == iterator.Done {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("list %s: %w", url, err)
+			}
+			if strings.HasSuffix(attrs.Name, suffix) {
+				names = append(names, attrs.Name)
+			}
+		}
+	}
+
+	var objects []gcsObject
+	for _, name := range names {
+		obj := gcsObject{url: "gs://" + bucket + "/" + name}
+		r, err := b.Object(name).NewReader(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", obj.url, err)
+		}
+		obj.data, err = io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", obj.url, err)
+		}
+		objects = append(objects, obj)
+	}
+	return objects, nil
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"slices"
+	"strings"
+
+	"google.golang.org/api/accesscontextmanager/v1"
+	"google.golang.org/api/cloudresourcemanager/v1"
+)
+
+// getServiceControls reports the Access Context Manager policies that apply
+// to the project, their access levels and the VPC Service Controls
+// perimeters the project is in. Policies live on the organization, so this
+// finds nothing for projects outside one.
+func getServiceControls(ctx context.Context) {
+	crmService, err := cloudresourcemanager.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create Cloud Resource Manager service", "error", err)
+		return
+	}
+	project, err := crmService.Projects.Get(projectID).Context(ctx).Do()
+	if err != nil {
+		slog.Error("Failed to get project", "error", err)
+		return
+	}
+	orgID, err := organizationID(ctx, crmService)
+	if err != nil {
+		slog.Error("Failed to get project ancestry", "error", err)
+		return
+	}
+	if orgID == "" {
+		slog.Debug("Skipping VPC Service Controls, project has no organization")
+		return
+	}
+	projectRef := fmt.Sprintf("projects/%d", project.ProjectNumber)
+
+	acmService, err := accesscontextmanager.NewService(ctx, clientOptions...)
+	if err != nil {
+		slog.Error("Failed to create Access Context Manager service", "error", err)
+		return
+	}
+	policies, err := acmService.AccessPolicies.List().Parent("organizations/" + orgID).Context(ctx).Do()
+	if err != nil {
+		// Listing policies needs organization-level access
+		slog.Warn("Skipping VPC Service Controls", "error", err)
+		return
+	}
+
+	count := 0
+	for _, policy := range policies.AccessPolicies {
+		// Scoped policies only apply to the folders or projects they name.
+		if len(policy.Scopes) > 0 && !slices.Contains(policy.Scopes, projectRef) {
+			continue
+		}
+		scope := "organization"
+		if len(policy.Scopes) > 0 {
+			scope = strings.Join(policy.Scopes, ", ")
+		}
+		info := fmt.Sprintf("Name: %s\nTitle: %s\nScope: %s", path.Base(policy.Name), policy.Title, scope)
+		writeResourceRaw("Access Policy", info, policy)
+		count++
+
+		levels, err := acmService.AccessPolicies.AccessLevels.List(policy.Name).Context(ctx).Do()
+		if err != nil {
+			slog.Error("Failed to list access levels", "policy", policy.Name, "error", err)
+		} else {
+			for _, level := range levels.AccessLevels {
+				info := fmt.Sprintf("Name: %s\nPolicy: %s\nTitle: %s\nType: %s\nConditions: %s",
+					path.Base(level.Name), path.Base(policy.Name), level.Title, accessLevelType(level), accessLevelConditions(level))
+				writeResourceRaw("Access Level", info, level)
+				count++
+			}
+		}
+
+		perimeters, err := acmService.AccessPolicies.ServicePerimeters.List(policy.Name).Context(ctx).Do()
+		if err != nil {
+			slog.Error("Failed to list service perimeters", "policy", policy.Name, "error", err)
+			continue
+		}
+		for _, perimeter := range perimeters.ServicePerimeters {
+			// The enforced configuration and the dry-run spec are reported
+			// separately, since a project can be in one and not the other.
+			for _, config := range []struct {
+				mode   string
+				config *accesscontextmanager.ServicePerimeterConfig
+			}{{"enforced", perimeter.Status}, {"dry run", perimeter.Spec}} {
+				if config.config == nil || !slices.Contains(config.config.Resources, projectRef) {
+					continue
+				}
+				name := path.Base(perimeter.Name)
+				if config.mode == "dry run" {
+					name += " (dry run)"
+				}
+				vpcServices := "all"
+				if v := config.config.VpcAccessibleServices; v != nil && v.EnableRestriction {
+					vpcServices = strings.Join(v.AllowedServices, ", ")
+				}
+				var levelNames []string
+				for _, level := range config.config.AccessLevels {
+					levelNames = append(levelNames, path.Base(level))
+				}
+				info := fmt.Sprintf("Name: %s\nPolicy: %s\nTitle: %s\nType: %s\nMode: %s\nProjects: %d\nRestricted Services: %s\nAccess Levels: %s\nVPC Accessible Services: %s\nIngress Policies: %d\nEgress Policies: %d",
+					name, path.Base(policy.Name), perimeter.Title, perimeter.PerimeterType, config.mode,
+					len(config.config.Resources), strings.Join(config.config.RestrictedServices, ", "),
+					strings.Join(levelNames, ", "), vpcServices,
+					len(config.config.IngressPolicies), len(config.config.EgressPolicies))
+				writeResourceRaw("Service Perimeter", info, perimeter)
+				count++
+			}
+		}
+	}
+	scanProgress.found(count)
+}
+
+// organizationID returns the ID of the organization the project belongs
+// to, or "" if it isn't in one.
+func organizationID(ctx context.Context, crmService *cloudresourcemanager.Service) (string, error) {
+	ancestry, err := crmService.Projects.GetAncestry(projectID, &cloudresourcemanager.GetAncestryRequest{}).Context(ctx).Do()
+	if err != nil {
+		return "", err
+	}
+	for _, ancestor := range ancestry.Ancestor {
+		if ancestor.ResourceId != nil && ancestor.ResourceId.Type == "organization" {
+			return ancestor.ResourceId.Id, nil
+		}
+	}
+	return "", nil
+}
+
+func accessLevelType(level *accesscontextmanager.AccessLevel) string {
+	if level.Custom != nil {
+		return "custom"
+	}
+	return "basic"
+}
+
+// accessLevelConditions summarizes what an access level requires: the CEL
+// expression of a custom level, or the IP ranges, regions and members of a
+// basic one.
+func accessLevelConditions(level *accesscontextmanager.AccessLevel) string {
+	if level.Custom != nil && level.Custom.Expr != nil {
+		return oneLine(level.Custom.Expr.Expression)
+	}
+	if level.Basic == nil {
+		return ""
+	}
+	var conditions []string
+	for _, c := range level.Basic.Conditions {
+		var parts []string
+		if len(c.IpSubnetworks) > 0 {
+			parts = append(parts, "ip "+strings.Join(c.IpSubnetworks, " "))
+		}
+		if len(c.Regions) > 0 {
+			parts = append(parts, "region "+strings.Join(c.Regions, " "))
+		}
+		if len(c.Members) > 0 {
+			parts = append(parts, "members "+strings.Join(c.Members, " "))
+		}
+		if len(c.RequiredAccessLevels) > 0 {
+			parts = append(parts, "levels "+strings.Join(c.RequiredAccessLevels, " "))
+		}
+		if c.DevicePolicy != nil {
+			parts = append(parts, "device policy")
+		}
+		if c.Negate {
+			parts = append(parts, "negated")
+		}
+		conditions = append(conditions, strings.Join(parts, ", "))
+	}
+	join := " AND "
+	if level.Basic.CombiningFunction == "OR" {
+		join = " OR "
+	}
+	return strings.Join(conditions, join)
+}
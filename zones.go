@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"path"
+	"sort"
+	"sync"
+)
+
+// regionZoneLists remembers each region's zones for the rest of the scan,
+// since every zonal collector asks for them.
+var regionZoneLists sync.Map
+
+// regionZones returns the names of the region's zones, such as
+// us-central1-a, us-central1-b and us-central1-c, in order. Zonal
+// collectors list each of them rather than assuming the region has an "-a"
+// zone and nothing else.
+func regionZones(ctx context.Context, region string) ([]string, error) {
+	if zones, ok := regionZoneLists.Load(region); ok {
+		return zones.([]string), nil
+	}
+	zones, err := cached("zones/"+region, zoneCacheTTL, func() ([]string, error) {
+		computeService, err := newComputeAPI(ctx)
+		if err != nil {
+			return nil, err
+		}
+		r, err := computeService.Region(ctx, region)
+		if err != nil {
+			return nil, err
+		}
+		zones := make([]string, 0, len(r.Zones))
+		for _, zone := range r.Zones {
+			zones = append(zones, path.Base(zone))
+		}
+		sort.Strings(zones)
+		return zones, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	regionZoneLists.Store(region, zones)
+	return zones, nil
+}